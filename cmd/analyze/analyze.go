@@ -18,11 +18,14 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/ai/interactive"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
@@ -38,6 +41,15 @@ var (
 	withDoc         bool
 	interactiveMode bool
 	customAnalysis  bool
+	structured      bool
+	maxTokensTotal  int
+	maxCost         float64
+	suppressions    string
+	certExpiry      time.Duration
+	targetVersion   string
+	manifests       []string
+	eventsWindow    time.Duration
+	eventsV1        bool
 )
 
 // AnalyzeCmd represents the problems command
@@ -48,6 +60,22 @@ var AnalyzeCmd = &cobra.Command{
 	Long: `This command will find problems within your Kubernetes cluster and
 	provide you with a list of issues that need to be resolved`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if cmd.Flags().Changed("cert-expiry-window") {
+			viper.Set("certificate_expiry_window", certExpiry)
+		}
+		if cmd.Flags().Changed("target-version") {
+			viper.Set("target_version", targetVersion)
+		}
+		if cmd.Flags().Changed("manifests") {
+			viper.Set("manifests", manifests)
+		}
+		if cmd.Flags().Changed("events-window") {
+			viper.Set("events_window", eventsWindow)
+		}
+		if cmd.Flags().Changed("events-v1") {
+			viper.Set("events_v1", eventsV1)
+		}
+
 		// Create analysis configuration first.
 		config, err := analysis.NewAnalysis(
 			backend,
@@ -66,6 +94,16 @@ var AnalyzeCmd = &cobra.Command{
 			os.Exit(1)
 		}
 		defer config.Close()
+		config.Structured = structured
+		config.Suppressions, err = analysis.LoadSuppressions(suppressions)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if config.Usage != nil {
+			config.Usage.MaxTotalTokens = maxTokensTotal
+			config.Usage.MaxCost = maxCost
+		}
 
 		if customAnalysis {
 			config.RunCustomAnalysis()
@@ -138,5 +176,19 @@ func init() {
 	AnalyzeCmd.Flags().BoolVarP(&interactiveMode, "interactive", "i", false, "Enable interactive mode that allows further conversation with LLM about the problem. Works only with --explain flag")
 	// custom analysis flag
 	AnalyzeCmd.Flags().BoolVarP(&customAnalysis, "custom-analysis", "z", false, "Enable custom analyzers")
-
+	// AI budget flags
+	AnalyzeCmd.Flags().IntVar(&maxTokensTotal, "max-tokens-total", 0, "Stop explaining further results once this many prompt and completion tokens have been used (0 means unlimited)")
+	AnalyzeCmd.Flags().Float64Var(&maxCost, "max-cost", 0, "Stop explaining further results once the estimated cost in USD reaches this value (0 means unlimited)")
+	// suppression file flag
+	AnalyzeCmd.Flags().StringVar(&suppressions, "suppressions", analysis.DefaultSuppressionsFile(), "File with the suppressions hiding known problems, managed with k8sgpt ignore")
+	// certificate expiry window flag
+	AnalyzeCmd.Flags().DurationVar(&certExpiry, "cert-expiry-window", analyzer.DefaultCertificateExpiryWindow, "Report certificates expiring within this duration, overrides certificate_expiry_window of the config file")
+	// deprecated API flags
+	AnalyzeCmd.Flags().StringVar(&targetVersion, "target-version", "", "Kubernetes version the DeprecatedAPI analyzer checks against (e.g. 1.29), defaults to the version of the cluster")
+	AnalyzeCmd.Flags().StringSliceVar(&manifests, "manifests", []string{}, "Manifest files or directories the DeprecatedAPI analyzer checks for deprecated API versions")
+	// Warning events flags
+	AnalyzeCmd.Flags().DurationVar(&eventsWindow, "events-window", analyzer.DefaultEventsWindow, "Report Warning events seen within this duration, overrides events_window of the config file")
+	AnalyzeCmd.Flags().BoolVar(&eventsV1, "events-v1", false, "Read events from the events.k8s.io/v1 API instead of core/v1, overrides events_v1 of the config file")
+	// structured explanation flag
+	AnalyzeCmd.Flags().BoolVar(&structured, "structured", false, "Ask the AI backend for a structured JSON explanation (summary, root cause, steps, commands, confidence, references). Works only with --explain flag")
 }
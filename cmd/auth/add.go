@@ -31,6 +31,12 @@ const (
 	defaultModel   = "gpt-3.5-turbo"
 )
 
+// backendDefaultModels overrides defaultModel for backends that do not serve it.
+var backendDefaultModels = map[string]string{
+	"ollama":    "llama3",
+	"anthropic": "claude-3-haiku-20240307",
+}
+
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add new provider",
@@ -87,6 +93,11 @@ var addCmd = &cobra.Command{
 			}
 		}
 
+		// use the backend specific default model unless one was given
+		if m, ok := backendDefaultModels[backend]; ok && !cmd.Flags().Changed("model") {
+			model = m
+		}
+
 		// check if model is not empty
 		if model == "" {
 			model = defaultModel
@@ -131,6 +142,7 @@ var addCmd = &cobra.Command{
 			TopP:           topP,
 			TopK:           topK,
 			MaxTokens:      maxTokens,
+			ExtraConfig:    extraConfig,
 		}
 
 		if providerIndex == -1 {
@@ -176,4 +188,6 @@ func init() {
 	addCmd.Flags().StringVarP(&providerId, "providerId", "i", "", "Provider specific ID for e.g. project (only for googlevertexai backend)")
 	//add flag for OCI Compartment ID
 	addCmd.Flags().StringVarP(&compartmentId, "compartmentId", "k", "", "Compartment ID for generative AI model (only for oci backend)")
+	// add flag for extra backend configuration
+	addCmd.Flags().StringToStringVarP(&extraConfig, "extraconfig", "x", nil, "Extra backend configuration as key=value pairs, e.g. header.X-Tenant=team-a,tls_ca_file=/etc/ssl/gateway.pem,retries=3")
 }
@@ -78,6 +78,15 @@ var updateCmd = &cobra.Command{
 					if engine != "" {
 						configAI.Providers[i].Engine = engine
 					}
+					if len(extraConfig) > 0 {
+						if configAI.Providers[i].ExtraConfig == nil {
+							configAI.Providers[i].ExtraConfig = map[string]string{}
+						}
+						for k, v := range extraConfig {
+							configAI.Providers[i].ExtraConfig[k] = v
+						}
+						color.Blue("Extra configuration updated successfully")
+					}
 					configAI.Providers[i].Temperature = temperature
 					color.Green("%s updated in the AI backend provider list", b)
 				}
@@ -110,4 +119,6 @@ func init() {
 	updateCmd.Flags().Float32VarP(&temperature, "temperature", "t", 0.7, "The sampling temperature, value ranges between 0 ( output be more deterministic) and 1 (more random)")
 	// update flag for azure open ai engine/deployment name
 	updateCmd.Flags().StringVarP(&engine, "engine", "e", "", "Update Azure AI deployment name")
+	// update flag for extra backend configuration
+	updateCmd.Flags().StringToStringVarP(&extraConfig, "extraconfig", "x", nil, "Set extra backend configuration keys as key=value pairs")
 }
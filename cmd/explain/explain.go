@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backend      string
+	output       string
+	language     string
+	nocache      bool
+	namespace    string
+	anonymize    bool
+	structured   bool
+	suppressions string
+)
+
+// ExplainCmd represents the explain command
+var ExplainCmd = &cobra.Command{
+	Use:   "explain <kind>/<name>",
+	Short: "This command will explain the problems of a single object",
+	Long: `This command runs only the analyzers relevant to a single object, its owners
+	and its dependents (e.g. the ReplicaSet and Deployment of a pod, the pods behind a Service
+	or the PersistentVolumeClaims it mounts) and explains the problems found`,
+	Example: `  k8sgpt explain pod/web-7d4b9c6f5-x2x9z -n shop
+  k8sgpt explain deploy/web -n shop`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ref, err := analysis.ParseObjectRef(args[0], namespace)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
+		config, err := analysis.NewAnalysis(
+			backend,
+			language,
+			[]string{},
+			ref.Namespace,
+			nocache,
+			true,
+			1,
+			false,
+			false,
+		)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		defer config.Close()
+		config.Structured = structured
+		config.Suppressions, err = analysis.LoadSuppressions(suppressions)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
+		related, err := config.RunObjectAnalysis(ref)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if output != "json" {
+			names := make([]string, 0, len(related))
+			for _, r := range related {
+				names = append(names, r.String())
+			}
+			fmt.Printf("Analyzed: %s\n", color.CyanString(strings.Join(names, ", ")))
+		}
+
+		if err := config.GetAIResults(output, anonymize); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		output_data, err := config.PrintOutput(output)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output_data))
+	},
+}
+
+func init() {
+	// namespace flag
+	ExplainCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the object")
+	// no cache flag
+	ExplainCmd.Flags().BoolVarP(&nocache, "no-cache", "c", false, "Do not use cached data")
+	// anonymize flag
+	ExplainCmd.Flags().BoolVarP(&anonymize, "anonymize", "a", false, "Anonymize data before sending it to the AI backend")
+	// add flag for backend
+	ExplainCmd.Flags().StringVarP(&backend, "backend", "b", "", "Backend AI provider")
+	// output as json
+	ExplainCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json)")
+	// add language options for output
+	ExplainCmd.Flags().StringVarP(&language, "language", "l", "english", "Languages to use for AI (e.g. 'English', 'Spanish', 'French', 'German', 'Italian', 'Portuguese', 'Dutch', 'Russian', 'Chinese', 'Japanese', 'Korean')")
+	// suppression file flag
+	ExplainCmd.Flags().StringVar(&suppressions, "suppressions", analysis.DefaultSuppressionsFile(), "File with the suppressions hiding known problems, managed with k8sgpt ignore")
+	// structured explanation flag
+	ExplainCmd.Flags().BoolVar(&structured, "structured", false, "Ask the AI backend for a structured JSON explanation")
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/cobra"
+)
+
+var codesCmd = &cobra.Command{
+	Use:   "codes [filter]",
+	Short: "List the failure codes of the filters",
+	Long: `The codes command lists the stable failure codes reported by each filter, with a short description
+	and the built-in remediation hint where there is one. Pass a filter name to only list its codes.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		printed := false
+		current := ""
+		for _, code := range common.FailureCodes() {
+			if len(args) == 1 && !containsFilter(code.Analyzer, args[0]) {
+				continue
+			}
+			if code.Analyzer != current {
+				current = code.Analyzer
+				fmt.Print(color.YellowString("%s: \n", current))
+			}
+			fmt.Printf("> %s %s\n", color.GreenString(code.Code), code.Description)
+			if code.Remediation != "" {
+				fmt.Printf("  %s\n", color.BlueString(code.Remediation))
+			}
+			printed = true
+		}
+		if !printed {
+			color.Red("No failure codes for filter %s. Please run k8sgpt filters list.", args[0])
+			os.Exit(1)
+		}
+	},
+}
+
+// containsFilter reports whether the comma separated analyzers of a code
+// include filter.
+func containsFilter(analyzers string, filter string) bool {
+	for _, analyzer := range strings.Split(analyzers, ", ") {
+		if strings.EqualFold(analyzer, filter) {
+			return true
+		}
+	}
+	return false
+}
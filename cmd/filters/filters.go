@@ -35,4 +35,5 @@ func init() {
 	FiltersCmd.AddCommand(listCmd)
 	FiltersCmd.AddCommand(addCmd)
 	FiltersCmd.AddCommand(removeCmd)
+	FiltersCmd.AddCommand(codesCmd)
 }
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backend   string
+	filters   []string
+	namespace string
+	logFile   string
+	anonymize bool
+)
+
+// FixCmd represents the fix command
+var FixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "This command will suggest and apply patches for problems in your cluster",
+	Long: `This command analyzes your cluster, asks the AI backend for a patch fixing
+	each selected result and validates it with a server-side dry-run. The diff of every
+	patch is shown and only applied after you confirm it. Applied patches are logged`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := analysis.NewAnalysis(
+			backend,
+			"english",
+			filters,
+			namespace,
+			true,
+			true,
+			1,
+			false,
+			false,
+		)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		defer config.Close()
+
+		if logFile == "" {
+			logFile, err = xdg.StateFile(filepath.Join("k8sgpt", "fixes.log"))
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+		}
+
+		config.RunAnalysis()
+		for _, err := range config.Errors {
+			color.Yellow("Warning: %s", err)
+		}
+		if len(config.Results) == 0 {
+			color.Green("No problems detected")
+			return
+		}
+
+		selected, err := selectResults(config.Results)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		for _, result := range selected {
+			fixResult(config, result)
+		}
+	},
+}
+
+// selectResults lets the user pick the results to fix.
+func selectResults(results []common.Result) ([]common.Result, error) {
+	options := make([]string, 0, len(results))
+	byOption := map[string]common.Result{}
+	for n, result := range results {
+		option := fmt.Sprintf("%d: %s %s", n, result.Kind, result.Name)
+		if len(result.Error) > 0 {
+			option = fmt.Sprintf("%s - %s", option, result.Error[0].Text)
+		}
+		options = append(options, option)
+		byOption[option] = result
+	}
+	chosen, err := pterm.DefaultInteractiveMultiselect.
+		WithOptions(options).
+		WithMaxHeight(15).
+		Show("Select the results to fix")
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]common.Result, 0, len(chosen))
+	for _, option := range chosen {
+		selected = append(selected, byOption[option])
+	}
+	return selected, nil
+}
+
+// fixResult suggests a patch for a result and applies it once confirmed.
+func fixResult(config *analysis.Analysis, result common.Result) {
+	fmt.Printf("\n%s %s\n", color.CyanString(result.Kind), color.YellowString(result.Name))
+	spinner, _ := pterm.DefaultSpinner.Start("Asking the AI backend for a patch...")
+	fix, err := config.SuggestFix(result, anonymize)
+	spinner.Stop()
+	if err != nil {
+		color.Red("No patch: %v", err)
+		return
+	}
+
+	diff, err := fix.Diff()
+	if err != nil {
+		color.Red("Error: %v", err)
+		return
+	}
+	if fix.Explanation != "" {
+		fmt.Println(fix.Explanation)
+	}
+	if strings.TrimSpace(diff) == "" {
+		color.Yellow("The suggested patch does not change the object")
+		return
+	}
+	printDiff(diff)
+
+	confirmed, err := pterm.DefaultInteractiveConfirm.
+		WithDefaultValue(false).
+		Show("Apply this patch?")
+	if err != nil || !confirmed {
+		fmt.Println("Skipped")
+		return
+	}
+	if err := config.ApplyFix(fix); err != nil {
+		color.Red("Error applying patch: %v", err)
+		return
+	}
+	if err := config.LogFix(logFile, fix); err != nil {
+		color.Red("Error logging patch to %s: %v", logFile, err)
+		return
+	}
+	color.Green("Patched %s %s, logged to %s", result.Kind, result.Name, logFile)
+}
+
+// printDiff colors a unified diff like git diff.
+func printDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			color.New(color.Bold).Println(line)
+		case strings.HasPrefix(line, "+"):
+			color.Green("%s", line)
+		case strings.HasPrefix(line, "-"):
+			color.Red("%s", line)
+		case strings.HasPrefix(line, "@@"):
+			color.Cyan("%s", line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+func init() {
+	// namespace flag
+	FixCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to analyze")
+	// add flag for backend
+	FixCmd.Flags().StringVarP(&backend, "backend", "b", "", "Backend AI provider")
+	// array of strings flag
+	FixCmd.Flags().StringSliceVarP(&filters, "filter", "f", []string{}, "Filter for these analyzers (e.g. Pod, PersistentVolumeClaim, Service, ReplicaSet)")
+	// anonymize flag
+	FixCmd.Flags().BoolVarP(&anonymize, "anonymize", "a", false, "Anonymize data before sending it to the AI backend")
+	// log file flag
+	FixCmd.Flags().StringVar(&logFile, "log-file", "", fmt.Sprintf("File applied patches are logged to (default %s)", filepath.Join(xdg.StateHome, "k8sgpt", "fixes.log")))
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignore
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/spf13/cobra"
+)
+
+var suppression analysis.Suppression
+
+// addCmd represents the add command
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a suppression",
+	Long: `This command adds a suppression to the suppression file. Every matcher that is
+set must match, e.g. --analyzer Pod --namespace legacy --code POD_CRASHLOOP.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := suppression.Validate(); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		suppressions, err := analysis.LoadSuppressions(file)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		suppressions = append(suppressions, suppression)
+		if err := analysis.SaveSuppressions(file, suppressions); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Suppression %d added: %s", len(suppressions), suppression)
+	},
+}
+
+func init() {
+	IgnoreCmd.AddCommand(addCmd)
+	addCmd.Flags().StringVar(&suppression.Analyzer, "analyzer", "", "Kind of the results to hide (e.g. Pod, Ingress)")
+	addCmd.Flags().StringVarP(&suppression.Namespace, "namespace", "n", "", "Namespace of the objects to hide")
+	addCmd.Flags().StringVar(&suppression.Name, "name", "", "Glob matching the names of the objects to hide (e.g. web-*)")
+	addCmd.Flags().StringVarP(&suppression.Labels, "labels", "l", "", "Label selector matching the objects to hide (e.g. team=legacy)")
+	addCmd.Flags().StringVar(&suppression.Code, "code", "", "Failure code to hide, see k8sgpt filters codes")
+	addCmd.Flags().StringVar(&suppression.Reason, "reason", "", "Why the problems are accepted")
+	addCmd.Flags().StringVar(&suppression.Owner, "owner", "", "Who owns the suppression")
+	addCmd.Flags().StringVar(&suppression.Expires, "expires", "", "Last day the suppression applies (YYYY-MM-DD)")
+	_ = addCmd.MarkFlagRequired("reason")
+	_ = addCmd.MarkFlagRequired("owner")
+}
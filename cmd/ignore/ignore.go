@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignore
+
+import (
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/spf13/cobra"
+)
+
+var file string
+
+// IgnoreCmd represents the ignore command
+var IgnoreCmd = &cobra.Command{
+	Use:   "ignore",
+	Short: "Manage the suppressions hiding known problems",
+	Long: `Ignore commands allow you to add, list and remove suppressions. A suppression
+hides the problems of an analyzer, namespace, name glob, label selector or failure
+code from the analyze output, with a reason, an owner and an optional expiry date.
+Objects annotated with ` + analysis.IgnoreAnnotation + `=true are always hidden.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Help()
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	IgnoreCmd.PersistentFlags().StringVar(&file, "file", analysis.DefaultSuppressionsFile(), "Suppression file")
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignore
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the suppressions",
+	Long:  `This command lists the suppressions and whether they have expired.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		suppressions, err := analysis.LoadSuppressions(file)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if len(suppressions) == 0 {
+			fmt.Println("No suppressions")
+			return
+		}
+
+		now := time.Now()
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"#", "Analyzer", "Namespace", "Name", "Labels", "Code", "Reason", "Owner", "Expires"})
+		for i, s := range suppressions {
+			expires := s.Expires
+			if s.Expired(now) {
+				expires = color.RedString("%s (expired)", s.Expires)
+			}
+			table.Append([]string{fmt.Sprint(i + 1), s.Analyzer, s.Namespace, s.Name, s.Labels, s.Code, s.Reason, s.Owner, expires})
+		}
+		table.Render()
+	},
+}
+
+func init() {
+	IgnoreCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ignore
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/spf13/cobra"
+)
+
+// removeCmd represents the remove command
+var removeCmd = &cobra.Command{
+	Use:   "remove [number]",
+	Short: "Remove a suppression",
+	Long:  `This command removes a suppression by the number shown by k8sgpt ignore list.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		suppressions, err := analysis.LoadSuppressions(file)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(suppressions) {
+			color.Red("Error: %s is not the number of a suppression, see k8sgpt ignore list", args[0])
+			os.Exit(1)
+		}
+		removed := suppressions[n-1]
+		suppressions = append(suppressions[:n-1], suppressions[n:]...)
+		if err := analysis.SaveSuppressions(file, suppressions); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Suppression %d removed: %s", n, removed)
+	},
+}
+
+func init() {
+	IgnoreCmd.AddCommand(removeCmd)
+}
@@ -4,14 +4,21 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package manifest
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 const (
+	accept    = "Accept"
 	dontApply = "Don't Apply"
 	reprompt  = "Reprompt"
 )
@@ -32,29 +39,93 @@ to quickly create a Cobra application.`,
 }
 
 var (
-	apply *bool
+	apply      *bool
+	maxRepairs *int
+	namespace  *string
+	outputDir  *string
+	format     *string
+	yes        *bool
 )
 
 func init() {
 	apply = ManifestCmd.Flags().Bool("apply", false, "Whether to apply the generated manifest. Defaults to false.")
+	maxRepairs = ManifestCmd.Flags().Int("max-repairs", 3, "How many times validation errors are sent back to the AI backend to repair the manifest.")
+	namespace = ManifestCmd.Flags().StringP("namespace", "n", "", "Namespace of objects without one, defaults to the default namespace.")
+	outputDir = ManifestCmd.Flags().String("output-dir", "", "Directory to write the accepted manifest to.")
+	format = ManifestCmd.Flags().String("format", FormatYAML, fmt.Sprintf("Layout of the files written to --output-dir: %s.", strings.Join(Formats, ", ")))
+	yes = ManifestCmd.Flags().BoolP("yes", "y", false, "Accept the generated manifest without reviewing it.")
 }
 func runManifestCmd(args []string) {
-	m, err := NewManifester("", 1, true)
-	if err != nil {
-		color.Red("Error: %v", err)
-		os.Exit(1)
-	}
-	defer m.Close()
 	if len(args) == 0 {
 		fmt.Println("prompt must be provided")
 		os.Exit(1)
 	}
-	str, err := m.GenerateManifest(args[0], false)
+	if !slices.Contains(Formats, *format) {
+		color.Red("Error: unknown format %q, must be one of %s", *format, strings.Join(Formats, ", "))
+		os.Exit(1)
+	}
+	m, err := NewManifester(*namespace, 1, true)
 	if err != nil {
 		color.Red("Error: %v", err)
 		os.Exit(1)
 	}
-	print(str)
+	defer m.Close()
+
+	str, objects, err := m.GenerateValidManifest(args[0], *maxRepairs)
+	for {
+		if err != nil && !errors.Is(err, ErrInvalidManifest) {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		print(str)
+		printDiff(objects)
+		printSummary(objects)
+		if err != nil {
+			color.Red("Validation Error: %v", err)
+			os.Exit(1)
+		}
+		if *yes {
+			break
+		}
+
+		var choice, instructions string
+		choice, err = pterm.DefaultInteractiveSelect.
+			WithOptions([]string{accept, dontApply, reprompt}).
+			Show("What do you want to do with this manifest?")
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if choice == dontApply {
+			fmt.Println("The manifest was rejected.")
+			return
+		}
+		if choice == accept {
+			break
+		}
+
+		instructions, err = pterm.DefaultInteractiveTextInput.Show("What should be changed")
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		str, objects, err = m.ReviseManifest(str, instructions, *maxRepairs)
+	}
+
+	if *outputDir != "" {
+		var generated []*unstructured.Unstructured
+		for _, object := range objects {
+			generated = append(generated, object.Object)
+		}
+		files, err := WriteManifest(*outputDir, *format, *namespace, generated)
+		if err != nil {
+			color.Red("Error writing manifest: %v", err)
+			os.Exit(1)
+		}
+		for _, file := range files {
+			fmt.Printf("wrote %s\n", file)
+		}
+	}
 	if *apply {
 		fmt.Println("will apply manifest above to k8s api server")
 		if err := m.ApplyManifest(str); err != nil {
@@ -63,3 +134,53 @@ func runManifestCmd(args []string) {
 		}
 	}
 }
+
+// printDiff shows the changes to live objects, colored like git diff.
+func printDiff(objects []kubernetes.ManifestObject) {
+	for _, object := range objects {
+		if len(object.Errors) > 0 || object.Action == kubernetes.ManifestUnchanged {
+			continue
+		}
+		diff, err := object.Diff()
+		if err != nil {
+			color.Red("Error diffing %s: %v", object, err)
+			continue
+		}
+		fmt.Println()
+		for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				color.New(color.Bold).Println(line)
+			case strings.HasPrefix(line, "+"):
+				color.Green("%s", line)
+			case strings.HasPrefix(line, "-"):
+				color.Red("%s", line)
+			case strings.HasPrefix(line, "@@"):
+				color.Cyan("%s", line)
+			default:
+				fmt.Println(line)
+			}
+		}
+	}
+}
+
+// printSummary shows what applying the manifest would do to the cluster.
+func printSummary(objects []kubernetes.ManifestObject) {
+	if len(objects) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("Summary:")
+	for _, object := range objects {
+		switch {
+		case len(object.Errors) > 0:
+			fmt.Println(color.RedString("  ! %s is invalid", object))
+		case object.Action == kubernetes.ManifestCreate:
+			fmt.Println(color.GreenString("  + %s would be created", object))
+		case object.Action == kubernetes.ManifestUpdate:
+			fmt.Println(color.YellowString("  ~ %s would be changed", object))
+		default:
+			fmt.Printf("  = %s is unchanged\n", object)
+		}
+	}
+}
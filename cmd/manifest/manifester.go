@@ -37,6 +37,8 @@ type Manifester struct {
 	Namespace      string
 	Language       string
 	KubeClient     *kubernetes.Client
+	// validate checks a manifest against the cluster, see kubernetes.Client.ValidateManifest.
+	validate func(ctx context.Context, manifest string, namespace string) ([]kubernetes.ManifestObject, error)
 }
 
 type (
@@ -49,6 +51,9 @@ const (
 	StatusFailed    ManifestStatus = "Failed"
 )
 
+// ErrInvalidManifest is returned when a generated manifest could not be repaired.
+var ErrInvalidManifest = errors.New("manifest is still invalid")
+
 type YamlOutput struct {
 	Status ManifestStatus `json:"status"`
 	Errors ManifestErrors `json:"errors"`
@@ -117,6 +122,7 @@ func NewManifester(namespace string, maxConcurrency int, noCache bool) (*Manifes
 		MaxConcurrency: maxConcurrency,
 		Namespace:      namespace,
 		KubeClient:     client,
+		validate:       client.ValidateManifest,
 	}, nil
 }
 
@@ -138,7 +144,7 @@ func (m *Manifester) GenerateManifest(requirements string, anonymize bool) (stri
 	}
 
 	promptTemplate := ai.PromptMap["k8s_manifest"]
-	prompt := fmt.Sprintf(strings.TrimSpace(promptTemplate), m.Language, requirements)
+	prompt := fmt.Sprintf(strings.TrimSpace(promptTemplate), requirements)
 	response, err := m.AIClient.GetCompletion(m.Context, prompt)
 	if err != nil {
 		return "", err
@@ -150,8 +156,70 @@ func (m *Manifester) GenerateManifest(requirements string, anonymize bool) (stri
 	return response, nil
 }
 
+// GenerateValidManifest generates a manifest and validates it against the
+// cluster. Validation errors are sent back to the model for up to maxRepairs
+// repairs. The returned objects describe the last validation; an error is
+// returned together with them if the manifest is still invalid.
+func (m *Manifester) GenerateValidManifest(requirements string, maxRepairs int) (string, []kubernetes.ManifestObject, error) {
+	manifest, err := m.GenerateManifest(requirements, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return m.repairManifest(manifest, maxRepairs)
+}
+
+// ReviseManifest asks the model to change a manifest following the
+// instructions of the user and validates the result like GenerateValidManifest.
+func (m *Manifester) ReviseManifest(manifest string, instructions string, maxRepairs int) (string, []kubernetes.ManifestObject, error) {
+	promptTemplate := ai.PromptMap["k8s_manifest_revise"]
+	prompt := fmt.Sprintf(strings.TrimSpace(promptTemplate), instructions, manifest)
+	manifest, err := m.AIClient.GetCompletion(m.Context, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	return m.repairManifest(manifest, maxRepairs)
+}
+
+// repairManifest validates a manifest and sends validation errors back to the
+// model until it is valid or maxRepairs is reached.
+func (m *Manifester) repairManifest(manifest string, maxRepairs int) (string, []kubernetes.ManifestObject, error) {
+	namespace := m.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	for repairs := 0; ; repairs++ {
+		objects, err := m.validate(m.Context, manifest, namespace)
+		problems := ManifestProblems(objects, err)
+		if len(problems) == 0 {
+			return manifest, objects, nil
+		}
+		if repairs >= maxRepairs {
+			return manifest, objects, fmt.Errorf("%w after %d repairs:\n%s", ErrInvalidManifest, repairs, strings.Join(problems, "\n"))
+		}
+
+		promptTemplate := ai.PromptMap["k8s_manifest_repair"]
+		prompt := fmt.Sprintf(strings.TrimSpace(promptTemplate), strings.Join(problems, "\n"), manifest)
+		manifest, err = m.AIClient.GetCompletion(m.Context, prompt)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+}
+
+// ManifestProblems lists the errors of a manifest validation.
+func ManifestProblems(objects []kubernetes.ManifestObject, err error) []string {
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var problems []string
+	for _, object := range objects {
+		problems = append(problems, object.Errors...)
+	}
+	return problems
+}
+
 func (m *Manifester) ApplyManifest(answer string) error {
-	return m.KubeClient.ApplyManifest(answer)
+	return m.KubeClient.ApplyManifest(answer, m.Namespace)
 }
 
 func (m *Manifester) Close() {
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	FormatYAML      = "yaml"
+	FormatHelm      = "helm"
+	FormatKustomize = "kustomize"
+
+	helmNamespace = "{{ .Release.Namespace }}"
+)
+
+var (
+	Formats = []string{FormatYAML, FormatHelm, FormatKustomize}
+
+	invalidChartName = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// WriteManifest writes one file per object to dir, laid out as plain YAML,
+// a Helm chart or a Kustomize base. It returns the files written.
+func WriteManifest(dir string, format string, namespace string, objects []*unstructured.Unstructured) ([]string, error) {
+	w := &manifestWriter{dir: dir}
+	switch format {
+	case FormatYAML:
+		for _, obj := range objects {
+			w.writeObject("", obj.Object)
+		}
+	case FormatHelm:
+		w.writeYAML("Chart.yaml", map[string]interface{}{
+			"apiVersion":  "v2",
+			"name":        chartName(dir),
+			"description": "A Helm chart generated by k8sgpt",
+			"type":        "application",
+			"version":     "0.1.0",
+		})
+		w.writeYAML("values.yaml", map[string]interface{}{})
+		for _, obj := range objects {
+			c := obj.DeepCopy()
+			if c.GetNamespace() != "" {
+				c.SetNamespace(helmNamespace)
+			}
+			w.writeObject("templates", c.Object)
+		}
+	case FormatKustomize:
+		var resources []string
+		for _, obj := range objects {
+			c := obj.DeepCopy()
+			c.SetNamespace("")
+			resources = append(resources, w.writeObject("", c.Object))
+		}
+		kustomization := map[string]interface{}{
+			"apiVersion": "kustomize.config.k8s.io/v1beta1",
+			"kind":       "Kustomization",
+			"resources":  resources,
+		}
+		if namespace != "" {
+			kustomization["namespace"] = namespace
+		}
+		w.writeYAML("kustomization.yaml", kustomization)
+	default:
+		return nil, fmt.Errorf("unknown output format %q, must be one of %s", format, strings.Join(Formats, ", "))
+	}
+	return w.files, w.err
+}
+
+type manifestWriter struct {
+	dir   string
+	files []string
+	err   error
+}
+
+// writeObject writes an object to <kind>-<name>.yaml below subdir and returns
+// the path relative to the output directory.
+func (w *manifestWriter) writeObject(subdir string, obj map[string]interface{}) string {
+	u := unstructured.Unstructured{Object: obj}
+	name := filepath.Join(subdir, fmt.Sprintf("%s-%s.yaml", strings.ToLower(u.GetKind()), u.GetName()))
+	w.writeYAML(name, obj)
+	return name
+}
+
+func (w *manifestWriter) writeYAML(name string, v interface{}) {
+	if w.err != nil {
+		return
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		w.err = err
+		return
+	}
+	path := filepath.Join(w.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		w.err = err
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		w.err = err
+		return
+	}
+	w.files = append(w.files, path)
+}
+
+// chartName derives a valid chart name from the output directory.
+func chartName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	name := strings.Trim(invalidChartName.ReplaceAllString(strings.ToLower(filepath.Base(abs)), "-"), "-")
+	if name == "" {
+		return "k8sgpt-manifest"
+	}
+	return name
+}
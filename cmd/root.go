@@ -22,8 +22,11 @@ import (
 	"github.com/k8sgpt-ai/k8sgpt/cmd/analyze"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/auth"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/cache"
+	"github.com/k8sgpt-ai/k8sgpt/cmd/explain"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/filters"
+	"github.com/k8sgpt-ai/k8sgpt/cmd/fix"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/generate"
+	"github.com/k8sgpt-ai/k8sgpt/cmd/ignore"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/integration"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/manifest"
 	"github.com/k8sgpt-ai/k8sgpt/cmd/serve"
@@ -76,6 +79,9 @@ func init() {
 	rootCmd.AddCommand(serve.ServeCmd)
 	rootCmd.AddCommand(cache.CacheCmd)
 	rootCmd.AddCommand(manifest.ManifestCmd)
+	rootCmd.AddCommand(explain.ExplainCmd)
+	rootCmd.AddCommand(fix.FixCmd)
+	rootCmd.AddCommand(ignore.IgnoreCmd)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("Default config file (%s/k8sgpt/k8sgpt.yaml)", xdg.ConfigHome))
 	rootCmd.PersistentFlags().StringVar(&kubecontext, "kubecontext", "", "Kubernetes context to use. Only required if out-of-cluster.")
 	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
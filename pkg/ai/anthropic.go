@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicClientName     = "anthropic"
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+	anthropicAPIVersion     = "2023-06-01"
+	anthropicDefaultSystem  = "You are a Kubernetes expert helping to troubleshoot problems in a cluster."
+	anthropicDefaultTokens  = 1024
+)
+
+// AnthropicClient uses the Anthropic Messages API. The base URL can point to
+// a gateway, and the `system` extra configuration key overrides the system prompt.
+type AnthropicClient struct {
+	nopCloser
+
+	client      *http.Client
+	baseURL     string
+	apiKey      string
+	model       string
+	system      string
+	temperature float32
+	topP        float32
+	topK        int32
+	maxTokens   int
+	lastUsage   *Usage
+}
+
+type AnthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type AnthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []AnthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature,omitempty"`
+	TopP        float32            `json:"top_p,omitempty"`
+	TopK        int32              `json:"top_k,omitempty"`
+}
+
+type AnthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// AnthropicError is an error returned by the Messages API.
+type AnthropicError struct {
+	StatusCode int
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+}
+
+func (e *AnthropicError) Error() string {
+	return fmt.Sprintf("anthropic error, status code: %d, type: %s, message: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// Retryable reports whether the request may succeed when sent again later,
+// i.e. the API was overloaded or the rate limit was hit.
+func (e *AnthropicError) Retryable() bool {
+	switch e.Type {
+	case "overloaded_error", "rate_limit_error":
+		return true
+	}
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+func (c *AnthropicClient) Configure(config IAIConfig) error {
+	c.apiKey = config.GetPassword()
+	if c.apiKey == "" {
+		return errors.New("anthropic API key is required")
+	}
+	c.baseURL = strings.TrimSuffix(config.GetBaseURL(), "/")
+	if c.baseURL == "" {
+		c.baseURL = anthropicDefaultBaseURL
+	}
+	c.model = config.GetModel()
+	c.temperature = config.GetTemperature()
+	c.topP = config.GetTopP()
+	c.topK = config.GetTopK()
+	c.maxTokens = config.GetMaxTokens()
+	if c.maxTokens <= 0 {
+		c.maxTokens = anthropicDefaultTokens
+	}
+	c.system = config.GetExtraConfig()["system"]
+	if c.system == "" {
+		c.system = anthropicDefaultSystem
+	}
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+	c.client = client
+	return nil
+}
+
+func (c *AnthropicClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	return c.GetChatCompletion(ctx, []ChatMessage{{Role: ChatRoleUser, Content: prompt}})
+}
+
+// GetChatCompletion sends the conversation to the Messages API. System
+// messages are merged into the system prompt as the API expects.
+func (c *AnthropicClient) GetChatCompletion(ctx context.Context, messages []ChatMessage) (string, error) {
+	request := AnthropicRequest{
+		Model:       c.model,
+		System:      c.system,
+		MaxTokens:   c.maxTokens,
+		Temperature: c.temperature,
+		TopP:        c.topP,
+		TopK:        c.topK,
+	}
+	for _, m := range messages {
+		if m.Role == ChatRoleSystem {
+			request.System += "\n\n" + m.Content
+			continue
+		}
+		request.Messages = append(request.Messages, AnthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewBuffer(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &AnthropicError{StatusCode: resp.StatusCode}
+		var errResponse struct {
+			Error *AnthropicError `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errResponse); err == nil && errResponse.Error != nil {
+			apiErr.Type = errResponse.Error.Type
+			apiErr.Message = errResponse.Error.Message
+		} else {
+			apiErr.Message = resp.Status
+		}
+		return "", apiErr
+	}
+
+	var response AnthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", err
+	}
+	c.lastUsage = &Usage{
+		PromptTokens:     response.Usage.InputTokens,
+		CompletionTokens: response.Usage.OutputTokens,
+	}
+
+	var answer strings.Builder
+	for _, content := range response.Content {
+		if content.Type == "text" {
+			answer.WriteString(content.Text)
+		}
+	}
+	if answer.Len() == 0 {
+		return "", fmt.Errorf("no text content returned (stop reason: %s)", response.StopReason)
+	}
+	return answer.String(), nil
+}
+
+func (c *AnthropicClient) GetLastUsage() (Usage, bool) {
+	if c.lastUsage == nil {
+		return Usage{}, false
+	}
+	return *c.lastUsage, true
+}
+
+func (c *AnthropicClient) GetName() string {
+	return anthropicClientName
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicClient(t *testing.T) {
+	var got AnthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages", r.URL.Path)
+		require.Equal(t, "secret", r.Header.Get("x-api-key"))
+		require.Equal(t, anthropicAPIVersion, r.Header.Get("anthropic-version"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Error: bad image"},{"type":"text","text":" Solution: fix it"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":5}}`)
+	}))
+	defer server.Close()
+
+	client := &AnthropicClient{}
+	require.NoError(t, client.Configure(&AIProvider{
+		Name:        "anthropic",
+		Model:       "claude-3-haiku-20240307",
+		Password:    "secret",
+		BaseURL:     server.URL + "/",
+		Temperature: 0.2,
+		TopP:        0.9,
+		TopK:        40,
+		MaxTokens:   512,
+	}))
+
+	answer, err := client.GetCompletion(context.Background(), "why is my pod failing?")
+	require.NoError(t, err)
+	require.Equal(t, "Error: bad image Solution: fix it", answer)
+
+	require.Equal(t, AnthropicRequest{
+		Model:       "claude-3-haiku-20240307",
+		System:      anthropicDefaultSystem,
+		Messages:    []AnthropicMessage{{Role: "user", Content: "why is my pod failing?"}},
+		MaxTokens:   512,
+		Temperature: 0.2,
+		TopP:        0.9,
+		TopK:        40,
+	}, got)
+
+	usage, ok := client.GetLastUsage()
+	require.True(t, ok)
+	require.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 5}, usage)
+}
+
+func TestAnthropicClientErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		expectedErr   string
+		expectedType  string
+		expectedRetry bool
+	}{
+		{
+			name:          "overloaded",
+			status:        529,
+			body:          `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			expectedErr:   "status code: 529",
+			expectedType:  "overloaded_error",
+			expectedRetry: true,
+		},
+		{
+			name:          "rate limited",
+			status:        http.StatusTooManyRequests,
+			body:          `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`,
+			expectedErr:   "status code: 429",
+			expectedType:  "rate_limit_error",
+			expectedRetry: true,
+		},
+		{
+			name:         "invalid request",
+			status:       http.StatusBadRequest,
+			body:         `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: field required"}}`,
+			expectedErr:  "max_tokens: field required",
+			expectedType: "invalid_request_error",
+		},
+		{
+			name:          "gateway error without body",
+			status:        http.StatusBadGateway,
+			expectedErr:   "502 Bad Gateway",
+			expectedRetry: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			client := &AnthropicClient{}
+			require.NoError(t, client.Configure(&AIProvider{Password: "secret", BaseURL: server.URL}))
+			_, err := client.GetCompletion(context.Background(), "prompt")
+			require.ErrorContains(t, err, tt.expectedErr)
+
+			var apiErr *AnthropicError
+			require.True(t, errors.As(err, &apiErr))
+			require.Equal(t, tt.status, apiErr.StatusCode)
+			require.Equal(t, tt.expectedType, apiErr.Type)
+			require.Equal(t, tt.expectedRetry, apiErr.Retryable())
+		})
+	}
+}
+
+func TestAnthropicClientRequiresKey(t *testing.T) {
+	require.Error(t, (&AnthropicClient{}).Configure(&AIProvider{}))
+}
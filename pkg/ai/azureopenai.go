@@ -3,8 +3,6 @@ package ai
 import (
 	"context"
 	"errors"
-	"net/http"
-	"net/url"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -17,13 +15,13 @@ type AzureAIClient struct {
 	client      *openai.Client
 	model       string
 	temperature float32
+	lastUsage   *Usage
 }
 
 func (c *AzureAIClient) Configure(config IAIConfig) error {
 	token := config.GetPassword()
 	baseURL := config.GetBaseURL()
 	engine := config.GetEngine()
-	proxyEndpoint := config.GetProxyEndpoint()
 	defaultConfig := openai.DefaultAzureConfig(token, baseURL)
 
 	defaultConfig.AzureModelMapperFunc = func(model string) string {
@@ -35,19 +33,11 @@ func (c *AzureAIClient) Configure(config IAIConfig) error {
 
 	}
 
-	if proxyEndpoint != "" {
-		proxyUrl, err := url.Parse(proxyEndpoint)
-		if err != nil {
-			return err
-		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-
-		defaultConfig.HTTPClient = &http.Client{
-			Transport: transport,
-		}
+	httpClient, err := NewHTTPClient(config)
+	if err != nil {
+		return err
 	}
+	defaultConfig.HTTPClient = httpClient
 	client := openai.NewClientWithConfig(defaultConfig)
 	if client == nil {
 		return errors.New("error creating Azure OpenAI client")
@@ -59,21 +49,66 @@ func (c *AzureAIClient) Configure(config IAIConfig) error {
 }
 
 func (c *AzureAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	return c.getCompletion(ctx, toOpenAIMessages([]ChatMessage{{Role: ChatRoleUser, Content: prompt}}), nil)
+}
+
+// GetStructuredCompletion uses Azure OpenAI JSON mode so the answer is always a JSON object.
+func (c *AzureAIClient) GetStructuredCompletion(ctx context.Context, prompt string) (string, error) {
+	return c.getCompletion(ctx, toOpenAIMessages([]ChatMessage{{Role: ChatRoleUser, Content: prompt}}), &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+	})
+}
+
+func (c *AzureAIClient) GetChatCompletion(ctx context.Context, messages []ChatMessage) (string, error) {
+	return c.getCompletion(ctx, toOpenAIMessages(messages), nil)
+}
+
+// GetToolCompletion uses function calling to let the model request tools.
+func (c *AzureAIClient) GetToolCompletion(ctx context.Context, messages []ChatMessage, tools []Tool) (ChatMessage, error) {
+	message, err := c.chatCompletion(ctx, toOpenAIMessages(messages), nil, toOpenAITools(tools))
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	return fromOpenAIMessage(message), nil
+}
+
+func (c *AzureAIClient) getCompletion(ctx context.Context, messages []openai.ChatCompletionMessage, format *openai.ChatCompletionResponseFormat) (string, error) {
+	message, err := c.chatCompletion(ctx, messages, format, nil)
+	if err != nil {
+		return "", err
+	}
+	return message.Content, nil
+}
+
+func (c *AzureAIClient) chatCompletion(ctx context.Context, messages []openai.ChatCompletionMessage, format *openai.ChatCompletionResponseFormat, tools []openai.Tool) (openai.ChatCompletionMessage, error) {
 	// Create a completion request
 	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: c.model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		Temperature: c.temperature,
+		Model:          c.model,
+		Messages:       messages,
+		Temperature:    c.temperature,
+		ResponseFormat: format,
+		Tools:          tools,
 	})
 	if err != nil {
-		return "", err
+		return openai.ChatCompletionMessage{}, err
+	}
+	// OpenAI compatible backends may not report the usage, the tokens are
+	// estimated then
+	c.lastUsage = nil
+	if resp.Usage.TotalTokens > 0 {
+		c.lastUsage = &Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		}
+	}
+	return resp.Choices[0].Message, nil
+}
+
+func (c *AzureAIClient) GetLastUsage() (Usage, bool) {
+	if c.lastUsage == nil {
+		return Usage{}, false
 	}
-	return resp.Choices[0].Message.Content, nil
+	return *c.lastUsage, true
 }
 
 func (c *AzureAIClient) GetName() string {
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	ChatRoleSystem    = "system"
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+	ChatRoleTool      = "tool"
+)
+
+// ChatMessage is a single role-tagged message of a conversation. Assistant
+// messages may request tool calls, whose results are sent back as tool messages.
+type ChatMessage struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// IChatAI is implemented by clients whose backend accepts a list of messages,
+// so earlier turns of a conversation are sent with their original roles.
+type IChatAI interface {
+	// GetChatCompletion generates the next assistant message of the conversation.
+	GetChatCompletion(ctx context.Context, messages []ChatMessage) (string, error)
+}
+
+// GetChatCompletion continues the conversation using client. Backends without
+// chat support receive the conversation flattened into a single prompt.
+func GetChatCompletion(ctx context.Context, client IAI, messages []ChatMessage) (string, error) {
+	if cc, ok := client.(IChatAI); ok {
+		return cc.GetChatCompletion(ctx, messages)
+	}
+	return client.GetCompletion(ctx, FlattenChatMessages(messages))
+}
+
+// FlattenChatMessages renders a conversation as a plain text transcript.
+func FlattenChatMessages(messages []ChatMessage) string {
+	var b strings.Builder
+	for _, m := range messages {
+		role := m.Role
+		if role != "" {
+			role = strings.ToUpper(role[:1]) + role[1:]
+		}
+		b.WriteString(fmt.Sprintf("%s: %s\n\n", role, m.Content))
+	}
+	b.WriteString("Assistant: ")
+	return b.String()
+}
+
+// TrimChatMessages drops the oldest turns of the conversation until its
+// estimated token count fits into maxTokens. A turn is a user message and the
+// assistant and tool messages that follow it, so an assistant message calling
+// tools is always kept or dropped together with the tool results. System
+// messages and the latest turn are always kept, and the kept conversation
+// never starts with an assistant or tool message.
+func TrimChatMessages(messages []ChatMessage, maxTokens int) []ChatMessage {
+	total := 0
+	var turns [][]int
+	for i, m := range messages {
+		total += EstimateTokens(m.Content)
+		if m.Role == ChatRoleSystem {
+			continue
+		}
+		if m.Role == ChatRoleUser || len(turns) == 0 {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], i)
+	}
+
+	dropped := map[int]bool{}
+	for t := 0; t < len(turns)-1; t++ {
+		if total <= maxTokens && messages[turns[t][0]].Role == ChatRoleUser {
+			break
+		}
+		for _, i := range turns[t] {
+			total -= EstimateTokens(messages[i].Content)
+			dropped[i] = true
+		}
+	}
+
+	trimmed := make([]ChatMessage, 0, len(messages)-len(dropped))
+	for i, m := range messages {
+		if !dropped[i] {
+			trimmed = append(trimmed, m)
+		}
+	}
+	return trimmed
+}
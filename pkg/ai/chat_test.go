@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimChatMessages(t *testing.T) {
+	long := strings.Repeat("x", 400) // ~100 tokens
+	messages := []ChatMessage{
+		{Role: ChatRoleSystem, Content: "context"},
+		{Role: ChatRoleUser, Content: long},
+		{Role: ChatRoleAssistant, Content: long},
+		{Role: ChatRoleUser, Content: long},
+		{Role: ChatRoleAssistant, Content: long},
+		{Role: ChatRoleUser, Content: "latest"},
+	}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		expected  []string
+	}{
+		{
+			name:      "fits",
+			maxTokens: 1000,
+			expected:  []string{ChatRoleSystem, ChatRoleUser, ChatRoleAssistant, ChatRoleUser, ChatRoleAssistant, ChatRoleUser},
+		},
+		{
+			name:      "drops oldest turn",
+			maxTokens: 250,
+			expected:  []string{ChatRoleSystem, ChatRoleUser, ChatRoleAssistant, ChatRoleUser},
+		},
+		{
+			name:      "drops orphan assistant message",
+			maxTokens: 150,
+			expected:  []string{ChatRoleSystem, ChatRoleUser},
+		},
+		{
+			name:      "keeps system and latest message",
+			maxTokens: 1,
+			expected:  []string{ChatRoleSystem, ChatRoleUser},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed := TrimChatMessages(messages, tt.maxTokens)
+			var roles []string
+			for _, m := range trimmed {
+				roles = append(roles, m.Role)
+			}
+			require.Equal(t, tt.expected, roles)
+			require.Equal(t, "latest", trimmed[len(trimmed)-1].Content)
+		})
+	}
+}
+
+func TestTrimChatMessagesToolRound(t *testing.T) {
+	long := strings.Repeat("x", 400) // ~100 tokens
+	messages := []ChatMessage{
+		{Role: ChatRoleSystem, Content: long},
+		{Role: ChatRoleUser, Content: long},
+		{Role: ChatRoleAssistant, Content: long},
+		{Role: ChatRoleUser, Content: "why is web failing?"},
+		{Role: ChatRoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Name: "get_object"}, {ID: "call_2", Name: "get_logs"}}},
+		{Role: ChatRoleTool, ToolCallID: "call_1", Content: long},
+		{Role: ChatRoleTool, ToolCallID: "call_2", Content: long},
+	}
+
+	// the tool results stay with the assistant message calling the tools and
+	// the question it answers
+	trimmed := TrimChatMessages(messages, 1)
+	var roles []string
+	for _, m := range trimmed {
+		roles = append(roles, m.Role)
+	}
+	require.Equal(t, []string{ChatRoleSystem, ChatRoleUser, ChatRoleAssistant, ChatRoleTool, ChatRoleTool}, roles)
+	require.Equal(t, "why is web failing?", trimmed[1].Content)
+	require.Len(t, trimmed[2].ToolCalls, 2)
+}
+
+func TestGetChatCompletion_Fallback(t *testing.T) {
+	client := &NoOpAIClient{}
+	response, err := GetChatCompletion(context.Background(), client, []ChatMessage{
+		{Role: ChatRoleSystem, Content: "context"},
+		{Role: ChatRoleUser, Content: "why?"},
+		{Role: ChatRoleAssistant, Content: "because"},
+		{Role: ChatRoleUser, Content: "and then?"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, response, "System: context\n\nUser: why?\n\nAssistant: because\n\nUser: and then?\n\nAssistant: ")
+}
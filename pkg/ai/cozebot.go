@@ -40,7 +40,11 @@ func (c *CozeBotClient) Configure(config IAIConfig) error {
 		return errors.New("missing required configuration values")
 	}
 
-	c.client = &http.Client{}
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+	c.client = client
 	c.baseURL = baseURL
 	c.token = token
 	c.botID = botID
@@ -20,6 +20,7 @@ type ArkAIClient struct {
 	endpoint    string
 	model       string
 	temperature float32
+	lastUsage   *Usage
 }
 
 type ArkChatCompletionRequest struct {
@@ -33,6 +34,7 @@ type ArkChatCompletionResponse struct {
 	Choices []struct {
 		Message openai.ChatCompletionMessage `json:"message"`
 	} `json:"choices"`
+	Usage *openai.Usage `json:"usage,omitempty"`
 }
 
 func (c *ArkAIClient) Configure(config IAIConfig) error {
@@ -40,25 +42,33 @@ func (c *ArkAIClient) Configure(config IAIConfig) error {
 	c.endpoint = config.GetBaseURL()
 	c.model = config.GetModel()
 	c.temperature = config.GetTemperature()
-	c.client = &http.Client{}
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+	c.client = client
 	return nil
 }
 
 func (c *ArkAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	return c.GetChatCompletion(ctx, []ChatMessage{
+		{
+			Role:    ChatRoleSystem,
+			Content: "You are a helpful assistant.",
+		},
+		{
+			Role:    ChatRoleUser,
+			Content: prompt,
+		},
+	})
+}
+
+func (c *ArkAIClient) GetChatCompletion(ctx context.Context, messages []ChatMessage) (string, error) {
 	// Create a completion request
 	requestBody := ArkChatCompletionRequest{
-		Model: c.model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "You are a helpful assistant.",
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		Stream: false,
+		Model:    c.model,
+		Messages: toOpenAIMessages(messages),
+		Stream:   false,
 	}
 
 	reqBodyBytes, err := json.Marshal(requestBody)
@@ -92,9 +102,24 @@ func (c *ArkAIClient) GetCompletion(ctx context.Context, prompt string) (string,
 		return "", errors.New("no completion choices returned")
 	}
 
+	c.lastUsage = nil
+	if completionResponse.Usage != nil {
+		c.lastUsage = &Usage{
+			PromptTokens:     completionResponse.Usage.PromptTokens,
+			CompletionTokens: completionResponse.Usage.CompletionTokens,
+		}
+	}
+
 	return completionResponse.Choices[0].Message.Content, nil
 }
 
+func (c *ArkAIClient) GetLastUsage() (Usage, bool) {
+	if c.lastUsage == nil {
+		return Usage{}, false
+	}
+	return *c.lastUsage, true
+}
+
 func (c *ArkAIClient) GetName() string {
 	return arkAIClientName
 }
@@ -32,6 +32,8 @@ var (
 		&OCIGenAIClient{},
 		&CozeBotClient{},
 		&ArkAIClient{},
+		&OllamaClient{},
+		&AnthropicClient{},
 	}
 	Backends = []string{
 		openAIClientName,
@@ -46,6 +48,8 @@ var (
 		googleVertexAIClientName,
 		ociClientName,
 		arkAIClientName,
+		ollamaClientName,
+		anthropicClientName,
 	}
 )
 
@@ -173,7 +177,7 @@ func (p *AIProvider) GetExtraConfig() map[string]string {
 	return p.ExtraConfig
 }
 
-var passwordlessProviders = []string{"localai", "amazonsagemaker", "amazonbedrock", "googlevertexai", "oci"}
+var passwordlessProviders = []string{"localai", "ollama", "amazonsagemaker", "amazonbedrock", "googlevertexai", "oci"}
 
 func NeedPassword(backend string) bool {
 	for _, b := range passwordlessProviders {
@@ -1,10 +1,14 @@
 package interactive
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
 	"github.com/pterm/pterm"
 )
@@ -12,7 +16,18 @@ import (
 type INTERACTIVE_STATE int
 
 const (
-	prompt = "Given the following context: "
+	prompt = "You are a Kubernetes expert helping to troubleshoot a cluster. Answer the questions of the user given the following context: "
+	// DefaultMaxTokens is the estimated number of tokens of the conversation
+	// sent to the backend. Older turns are dropped once it is exceeded.
+	DefaultMaxTokens = 8000
+	commandHelp      = `/results          list the results of the analysis
+/focus <n>        talk about result n only, with its live object and events
+/explain <n>      focus on result n and explain it
+/yaml <n>         show the live object of result n
+/reset            start the conversation over
+/save <file>      save the conversation
+/load <file>      resume a saved conversation
+exit              leave interactive mode`
 )
 
 const (
@@ -24,19 +39,28 @@ type InteractionRunner struct {
 	config        *analysis.Analysis
 	State         chan INTERACTIVE_STATE
 	contextWindow []byte
+	history       []ai.ChatMessage
+	tools         *toolRunner
+	MaxTokens     int
 }
 
 func NewInteractionRunner(config *analysis.Analysis, contextWindow []byte) *InteractionRunner {
-	return &InteractionRunner{
+	a := &InteractionRunner{
 		config:        config,
 		contextWindow: contextWindow,
 		State:         make(chan INTERACTIVE_STATE),
+		MaxTokens:     DefaultMaxTokens,
 	}
+	if config.Client != nil {
+		a.tools = newToolRunner(config.Client)
+	}
+	a.reset()
+	return a
 }
 
 func (a *InteractionRunner) StartInteraction() {
 	a.State <- E_RUNNING
-	pterm.Println("Interactive mode enabled [type exit to close.]")
+	pterm.Println("Interactive mode enabled [type exit to close, /help to list the commands.]")
 	for {
 
 		query := pterm.DefaultInteractiveTextInput.WithMultiLine(false)
@@ -51,12 +75,18 @@ func (a *InteractionRunner) StartInteraction() {
 			a.State <- E_EXITED
 			continue
 		}
+		if strings.HasPrefix(queryString, "/") {
+			message, err := a.command(queryString)
+			if err != nil {
+				color.Red("Error: %v", err)
+				continue
+			}
+			pterm.Println(message)
+			continue
+		}
 		pterm.Println()
-		contextWindow := fmt.Sprintf("%s %s %s", prompt, string(a.contextWindow),
-			queryString)
 
-		response, err := a.config.AIClient.GetCompletion(a.config.Context,
-			contextWindow)
+		response, err := a.ask(queryString)
 		if err != nil {
 			color.Red("Error: %v", err)
 			a.State <- E_EXITED
@@ -65,3 +95,136 @@ func (a *InteractionRunner) StartInteraction() {
 		pterm.Println(response)
 	}
 }
+
+// ask sends the question together with the previous turns of the
+// conversation and records the answer. With a cluster at hand the model may
+// call read-only tools before answering.
+func (a *InteractionRunner) ask(query string) (string, error) {
+	start := len(a.history)
+	a.history = append(a.history, ai.ChatMessage{Role: ai.ChatRoleUser, Content: query})
+	if a.tools == nil {
+		response, err := ai.GetChatCompletion(a.config.Context, a.config.AIClient,
+			ai.TrimChatMessages(a.history, a.MaxTokens))
+		if err != nil {
+			a.history = a.history[:start]
+			return "", err
+		}
+		a.history = append(a.history, ai.ChatMessage{Role: ai.ChatRoleAssistant, Content: response})
+		return response, nil
+	}
+
+	for round := 0; round <= maxToolRounds; round++ {
+		tools := clusterTools
+		if round == maxToolRounds {
+			// Force an answer once the model used up its tool calls.
+			tools = nil
+		}
+		message, err := ai.GetToolCompletion(a.config.Context, a.config.AIClient,
+			ai.TrimChatMessages(a.history, a.MaxTokens), tools)
+		if err != nil {
+			a.history = a.history[:start]
+			return "", err
+		}
+		a.history = append(a.history, message)
+		if len(message.ToolCalls) == 0 {
+			return message.Content, nil
+		}
+		for _, call := range message.ToolCalls {
+			a.history = append(a.history, ai.ChatMessage{
+				Role:       ai.ChatRoleTool,
+				ToolCallID: call.ID,
+				Content:    a.tools.run(a.config.Context, call),
+			})
+		}
+	}
+	return "", fmt.Errorf("no answer after %d rounds of tool calls", maxToolRounds)
+}
+
+// command runs a slash command and returns a message for the user.
+func (a *InteractionRunner) command(input string) (string, error) {
+	fields := strings.Fields(input)
+	switch fields[0] {
+	case "/help":
+		return commandHelp, nil
+	case "/results":
+		return a.listResults(), nil
+	case "/focus":
+		result, err := a.result(fields)
+		if err != nil {
+			return "", err
+		}
+		a.focus(result)
+		return fmt.Sprintf("Conversation focused on %s %s.", result.Kind, result.Name), nil
+	case "/explain":
+		result, err := a.result(fields)
+		if err != nil {
+			return "", err
+		}
+		a.focus(result)
+		return a.ask(explainPrompt)
+	case "/yaml":
+		result, err := a.result(fields)
+		if err != nil {
+			return "", err
+		}
+		return a.liveObject(result)
+	case "/reset":
+		a.reset()
+		return "Conversation reset.", nil
+	case "/save":
+		if len(fields) != 2 {
+			return "", errors.New("usage: /save <file>")
+		}
+		if err := a.save(fields[1]); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Conversation saved to %s.", fields[1]), nil
+	case "/load":
+		if len(fields) != 2 {
+			return "", errors.New("usage: /load <file>")
+		}
+		if err := a.load(fields[1]); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Conversation loaded from %s.", fields[1]), nil
+	}
+	return "", fmt.Errorf("unknown command %s", fields[0])
+}
+
+func (a *InteractionRunner) reset() {
+	a.history = []ai.ChatMessage{{
+		Role:    ai.ChatRoleSystem,
+		Content: prompt + string(a.contextWindow),
+	}}
+}
+
+func (a *InteractionRunner) save(path string) error {
+	data, err := json.MarshalIndent(a.history, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func (a *InteractionRunner) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var history []ai.ChatMessage
+	if err := json.Unmarshal(data, &history); err != nil {
+		return fmt.Errorf("reading conversation from %s: %w", path, err)
+	}
+	for _, m := range history {
+		switch m.Role {
+		case ai.ChatRoleSystem, ai.ChatRoleUser, ai.ChatRoleAssistant, ai.ChatRoleTool:
+		default:
+			return fmt.Errorf("unknown role %q in %s", m.Role, path)
+		}
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("no messages found in %s", path)
+	}
+	a.history = history
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInteractionRunner_History(t *testing.T) {
+	runner := NewInteractionRunner(&analysis.Analysis{
+		Context:  context.Background(),
+		AIClient: &ai.NoOpAIClient{},
+	}, []byte("pod is crashing"))
+
+	_, err := runner.ask("why?")
+	require.NoError(t, err)
+	response, err := runner.ask("and then?")
+	require.NoError(t, err)
+	// The second question is sent together with the first turn.
+	require.Contains(t, response, "User: why?")
+	require.Len(t, runner.history, 5)
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	_, err = runner.command("/save " + path)
+	require.NoError(t, err)
+
+	_, err = runner.command("/reset")
+	require.NoError(t, err)
+	require.Len(t, runner.history, 1)
+	require.Equal(t, ai.ChatRoleSystem, runner.history[0].Role)
+	require.Contains(t, runner.history[0].Content, "pod is crashing")
+
+	_, err = runner.command("/load " + path)
+	require.NoError(t, err)
+	require.Len(t, runner.history, 5)
+
+	_, err = runner.command("/load")
+	require.ErrorContains(t, err, "usage: /load <file>")
+	_, err = runner.command("/unknown")
+	require.ErrorContains(t, err, "unknown command")
+}
+
+type scriptedAIClient struct {
+	ai.NoOpAIClient
+	responses []string
+}
+
+func (c *scriptedAIClient) GetCompletion(_ context.Context, _ string) (string, error) {
+	response := c.responses[0]
+	c.responses = c.responses[1:]
+	return response, nil
+}
+
+func TestInteractionRunner_ToolCalls(t *testing.T) {
+	runner := NewInteractionRunner(&analysis.Analysis{
+		Context: context.Background(),
+		AIClient: &scriptedAIClient{responses: []string{
+			`TOOL_CALL: {"name": "list_events", "arguments": {"namespace": "default"}}`,
+			"The deployment exceeds its quota.",
+		}},
+	}, []byte("deployment web has no pods"))
+	runner.tools = newTestToolRunner(true)
+
+	response, err := runner.ask("why?")
+	require.NoError(t, err)
+	require.Equal(t, "The deployment exceeds its quota.", response)
+	// system, user, tool call, tool result, answer
+	require.Len(t, runner.history, 5)
+	require.Equal(t, ai.ChatRoleTool, runner.history[3].Role)
+	require.Contains(t, runner.history[3].Content, "quota exceeded")
+}
+
+func TestInteractionRunner_Results(t *testing.T) {
+	runner := NewInteractionRunner(&analysis.Analysis{
+		Context:  context.Background(),
+		AIClient: &ai.NoOpAIClient{},
+		Results: []common.Result{
+			{
+				Kind:  "Deployment",
+				Name:  "default/web",
+				Error: []common.Failure{{Text: "Deployment default/web has 1 replicas but 0 are available"}},
+			},
+		},
+	}, []byte("the whole analysis"))
+	runner.tools = newTestToolRunner(true)
+
+	out, err := runner.command("/results")
+	require.NoError(t, err)
+	require.Equal(t, "0: Deployment default/web - Deployment default/web has 1 replicas but 0 are available", out)
+
+	out, err = runner.command("/yaml 0")
+	require.NoError(t, err)
+	require.Contains(t, out, "name: web")
+
+	_, err = runner.command("/focus 0")
+	require.NoError(t, err)
+	require.Len(t, runner.history, 1)
+	require.NotContains(t, runner.history[0].Content, "the whole analysis")
+	require.Contains(t, runner.history[0].Content, "has 1 replicas but 0 are available")
+	require.Contains(t, runner.history[0].Content, "Live object:")
+	require.Contains(t, runner.history[0].Content, "quota exceeded")
+
+	out, err = runner.command("/explain 0")
+	require.NoError(t, err)
+	require.Contains(t, out, explainPrompt)
+	require.Len(t, runner.history, 3)
+
+	_, err = runner.command("/focus 1")
+	require.ErrorContains(t, err, "no result 1")
+	_, err = runner.command("/yaml")
+	require.ErrorContains(t, err, "usage: /yaml <result number>")
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+const (
+	focusPrompt   = "You are a Kubernetes expert helping to troubleshoot a cluster. Answer the questions of the user about the following problem found by K8sGPT: "
+	explainPrompt = "Explain this problem and how to fix it step by step."
+)
+
+// listResults numbers the results of the analysis like the text output does.
+func (a *InteractionRunner) listResults() string {
+	if len(a.config.Results) == 0 {
+		return "No problems detected."
+	}
+	var b strings.Builder
+	for n, result := range a.config.Results {
+		b.WriteString(fmt.Sprintf("%d: %s %s", n, result.Kind, result.Name))
+		if len(result.Error) > 0 {
+			b.WriteString(fmt.Sprintf(" - %s", result.Error[0].Text))
+		}
+		if len(result.Error) > 1 {
+			b.WriteString(fmt.Sprintf(" (+%d more)", len(result.Error)-1))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// result returns the result numbered by the argument of a slash command.
+func (a *InteractionRunner) result(fields []string) (common.Result, error) {
+	if len(fields) != 2 {
+		return common.Result{}, fmt.Errorf("usage: %s <result number>", fields[0])
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil || n < 0 || n >= len(a.config.Results) {
+		return common.Result{}, fmt.Errorf("no result %s, use /results to list them", fields[1])
+	}
+	return a.config.Results[n], nil
+}
+
+// focus narrows the conversation down to a single result, including its
+// live object and events when a cluster is available.
+func (a *InteractionRunner) focus(result common.Result) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s %s", result.Kind, result.Name))
+	if result.ParentObject != "" {
+		b.WriteString(fmt.Sprintf(" (owned by %s)", result.ParentObject))
+	}
+	b.WriteString("\nErrors:\n")
+	for _, failure := range result.Error {
+		b.WriteString(fmt.Sprintf("- %s\n", failure.Text))
+	}
+	if result.Details != "" {
+		b.WriteString(fmt.Sprintf("Explanation so far:\n%s\n", result.Details))
+	}
+	if a.tools != nil {
+		if yaml, err := a.liveObject(result); err == nil {
+			b.WriteString(fmt.Sprintf("Live object:\n%s\n", yaml))
+		}
+		namespace, name := splitName(result.Name)
+		if events, err := a.tools.listEvents(a.config.Context, toolArguments{Namespace: namespace, Name: name}); err == nil {
+			b.WriteString(fmt.Sprintf("Events:\n%s\n", events))
+		}
+	}
+
+	a.history = []ai.ChatMessage{{
+		Role:    ai.ChatRoleSystem,
+		Content: focusPrompt + b.String(),
+	}}
+}
+
+// liveObject fetches the current YAML of the object a result is about.
+func (a *InteractionRunner) liveObject(result common.Result) (string, error) {
+	if a.tools == nil {
+		return "", errors.New("no cluster connection available")
+	}
+	namespace, name := splitName(result.Name)
+	return a.tools.getObject(a.config.Context, toolArguments{
+		Kind:      result.Kind,
+		Name:      name,
+		Namespace: namespace,
+	})
+}
+
+// splitName splits the namespace/name of namespaced results.
+func splitName(name string) (string, string) {
+	if namespace, name, ok := strings.Cut(name, "/"); ok {
+		return namespace, name
+	}
+	return "", name
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// maxToolRounds bounds how many times the model may call tools before answering.
+	maxToolRounds    = 5
+	maxEvents        = 30
+	defaultTailLines = 50
+	maxTailLines     = 500
+	// maxToolOutput bounds the size of a tool result sent to the model, a
+	// large object or many log lines would fill the conversation.
+	maxToolOutput     = 16 * 1024
+	toolGetObject     = "get_object"
+	toolListEvents    = "list_events"
+	toolGetLogs       = "get_logs"
+	toolRunAnalyzer   = "run_analyzer"
+	toolDeclinedReply = "The user did not allow this tool call."
+)
+
+// allowedKinds are the objects the model may read. Secrets and other objects
+// holding credentials are deliberately not part of it.
+var allowedKinds = map[string]schema.GroupVersionKind{
+	"Pod":                     {Version: "v1", Kind: "Pod"},
+	"Service":                 {Version: "v1", Kind: "Service"},
+	"Endpoints":               {Version: "v1", Kind: "Endpoints"},
+	"Node":                    {Version: "v1", Kind: "Node"},
+	"Namespace":               {Version: "v1", Kind: "Namespace"},
+	"PersistentVolume":        {Version: "v1", Kind: "PersistentVolume"},
+	"PersistentVolumeClaim":   {Version: "v1", Kind: "PersistentVolumeClaim"},
+	"Deployment":              {Group: "apps", Version: "v1", Kind: "Deployment"},
+	"ReplicaSet":              {Group: "apps", Version: "v1", Kind: "ReplicaSet"},
+	"StatefulSet":             {Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	"DaemonSet":               {Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	"Job":                     {Group: "batch", Version: "v1", Kind: "Job"},
+	"CronJob":                 {Group: "batch", Version: "v1", Kind: "CronJob"},
+	"Ingress":                 {Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+	"NetworkPolicy":           {Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"},
+	"HorizontalPodAutoscaler": {Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"},
+	"PodDisruptionBudget":     {Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+	"StorageClass":            {Group: "storage.k8s.io", Version: "v1", Kind: "StorageClass"},
+}
+
+var clusterTools = []ai.Tool{
+	{
+		Name:        toolGetObject,
+		Description: "Get or describe a Kubernetes object as YAML.",
+		Parameters: objectSchema(map[string]any{
+			"kind":      map[string]any{"type": "string", "description": "Kind of the object, e.g. Pod or Deployment."},
+			"name":      map[string]any{"type": "string"},
+			"namespace": map[string]any{"type": "string", "description": "Empty for cluster scoped objects."},
+		}, "kind", "name"),
+	},
+	{
+		Name:        toolListEvents,
+		Description: "List the latest events of a namespace, optionally only those of one object.",
+		Parameters: objectSchema(map[string]any{
+			"namespace": map[string]any{"type": "string"},
+			"name":      map[string]any{"type": "string", "description": "Name of the involved object."},
+		}, "namespace"),
+	},
+	{
+		Name:        toolGetLogs,
+		Description: "Tail the logs of a pod container.",
+		Parameters: objectSchema(map[string]any{
+			"namespace":  map[string]any{"type": "string"},
+			"name":       map[string]any{"type": "string", "description": "Name of the pod."},
+			"container":  map[string]any{"type": "string", "description": "Empty for the only container of the pod."},
+			"tail_lines": map[string]any{"type": "integer"},
+		}, "namespace", "name"),
+	},
+	{
+		Name:        toolRunAnalyzer,
+		Description: "Run a K8sGPT analyzer, e.g. Pod or Service, and return the problems it finds.",
+		Parameters: objectSchema(map[string]any{
+			"analyzer":  map[string]any{"type": "string"},
+			"namespace": map[string]any{"type": "string", "description": "Empty for all namespaces."},
+		}, "analyzer"),
+	},
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+type toolArguments struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Container string `json:"container"`
+	TailLines int64  `json:"tail_lines"`
+	Analyzer  string `json:"analyzer"`
+}
+
+// toolRunner executes the read-only tool calls requested by the model once
+// the user allowed them.
+type toolRunner struct {
+	client  *kubernetes.Client
+	confirm func(call ai.ToolCall) bool
+}
+
+func newToolRunner(client *kubernetes.Client) *toolRunner {
+	return &toolRunner{
+		client:  client,
+		confirm: confirmToolCall,
+	}
+}
+
+func confirmToolCall(call ai.ToolCall) bool {
+	allowed, err := pterm.DefaultInteractiveConfirm.
+		WithDefaultText(fmt.Sprintf("Allow %s %s?", call.Name, call.Arguments)).
+		Show()
+	return err == nil && allowed
+}
+
+// run executes call. Failures are returned as text so the model can react to them.
+func (t *toolRunner) run(ctx context.Context, call ai.ToolCall) string {
+	var args toolArguments
+	if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
+		return fmt.Sprintf("Error: invalid arguments: %v", err)
+	}
+
+	var run func(context.Context, toolArguments) (string, error)
+	switch call.Name {
+	case toolGetObject:
+		run = t.getObject
+	case toolListEvents:
+		run = t.listEvents
+	case toolGetLogs:
+		run = t.getLogs
+	case toolRunAnalyzer:
+		run = t.runAnalyzer
+	default:
+		return fmt.Sprintf("Error: unknown tool %s", call.Name)
+	}
+
+	if !t.confirm(call) {
+		return toolDeclinedReply
+	}
+	out, err := run(ctx, args)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if len(out) > maxToolOutput {
+		out = fmt.Sprintf("%s\n... (truncated, %d more bytes)", out[:maxToolOutput], len(out)-maxToolOutput)
+	}
+	return out
+}
+
+func (t *toolRunner) getObject(ctx context.Context, args toolArguments) (string, error) {
+	gvk, ok := allowedKinds[args.Kind]
+	if !ok {
+		kinds := make([]string, 0, len(allowedKinds))
+		for kind := range allowedKinds {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		return "", fmt.Errorf("kind %q is not allowed, use one of %s", args.Kind, strings.Join(kinds, ", "))
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := t.client.GetCtrlClient().Get(ctx, ctrl.ObjectKey{Namespace: args.Namespace, Name: args.Name}, obj); err != nil {
+		return "", err
+	}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	out, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (t *toolRunner) listEvents(ctx context.Context, args toolArguments) (string, error) {
+	options := metav1.ListOptions{}
+	if args.Name != "" {
+		options.FieldSelector = fields.OneTermEqualSelector("involvedObject.name", args.Name).String()
+	}
+	events, err := t.client.GetClient().CoreV1().Events(args.Namespace).List(ctx, options)
+	if err != nil {
+		return "", err
+	}
+	if len(events.Items) == 0 {
+		return "No events found.", nil
+	}
+
+	items := events.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].LastTimestamp.Before(&items[j].LastTimestamp)
+	})
+	if len(items) > maxEvents {
+		items = items[len(items)-maxEvents:]
+	}
+	var b strings.Builder
+	for _, e := range items {
+		b.WriteString(fmt.Sprintf("%s %s %s/%s: %s (x%d)\n", e.Type, e.Reason,
+			e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Message, e.Count))
+	}
+	return b.String(), nil
+}
+
+func (t *toolRunner) getLogs(ctx context.Context, args toolArguments) (string, error) {
+	tailLines := args.TailLines
+	if tailLines <= 0 {
+		tailLines = defaultTailLines
+	}
+	if tailLines > maxTailLines {
+		tailLines = maxTailLines
+	}
+	logs, err := t.client.GetClient().CoreV1().Pods(args.Namespace).GetLogs(args.Name, &v1.PodLogOptions{
+		Container: args.Container,
+		TailLines: &tailLines,
+	}).DoRaw(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(logs) == 0 {
+		return "No logs found.", nil
+	}
+	return string(logs), nil
+}
+
+func (t *toolRunner) runAnalyzer(ctx context.Context, args toolArguments) (string, error) {
+	_, analyzerMap := analyzer.GetAnalyzerMap()
+	a, ok := analyzerMap[args.Analyzer]
+	if !ok {
+		return "", fmt.Errorf("unknown analyzer %q", args.Analyzer)
+	}
+	results, err := a.Analyze(common.Analyzer{
+		Client:    t.client,
+		Context:   ctx,
+		Namespace: args.Namespace,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		return "No problems found.", nil
+	}
+	common.AddRemediations(results)
+	var b strings.Builder
+	for _, result := range results {
+		for _, failure := range result.Error {
+			b.WriteString(fmt.Sprintf("%s %s: %s\n", result.Kind, result.Name, failure.Text))
+			if failure.Remediation != "" {
+				b.WriteString(fmt.Sprintf("  Remediation: %s\n", failure.Remediation))
+			}
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestToolRunner(allow bool) *toolRunner {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	large := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "large",
+		Namespace:   "default",
+		Annotations: map[string]string{"example.com/blob": strings.Repeat("x", 2*maxToolOutput)},
+	}}
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"}}
+	event := &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Deployment", Name: "web"},
+		Type:           "Warning",
+		Reason:         "FailedCreate",
+		Message:        "quota exceeded",
+		Count:          2,
+	}
+	return &toolRunner{
+		client: &kubernetes.Client{
+			Client:     fake.NewSimpleClientset(event),
+			CtrlClient: fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment, large, secret).Build(),
+		},
+		confirm: func(ai.ToolCall) bool { return allow },
+	}
+}
+
+func TestToolRunner(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     ai.ToolCall
+		allow    bool
+		expected string
+	}{
+		{
+			name:     "get object",
+			call:     ai.ToolCall{Name: toolGetObject, Arguments: `{"kind":"Deployment","name":"web","namespace":"default"}`},
+			allow:    true,
+			expected: "name: web",
+		},
+		{
+			name:     "large object",
+			call:     ai.ToolCall{Name: toolGetObject, Arguments: `{"kind":"Deployment","name":"large","namespace":"default"}`},
+			allow:    true,
+			expected: "... (truncated, ",
+		},
+		{
+			name:     "kind not allowed",
+			call:     ai.ToolCall{Name: toolGetObject, Arguments: `{"kind":"Secret","name":"token","namespace":"default"}`},
+			allow:    true,
+			expected: `Error: kind "Secret" is not allowed`,
+		},
+		{
+			name:     "list events",
+			call:     ai.ToolCall{Name: toolListEvents, Arguments: `{"namespace":"default"}`},
+			allow:    true,
+			expected: "Warning FailedCreate Deployment/web: quota exceeded (x2)",
+		},
+		{
+			name:     "declined",
+			call:     ai.ToolCall{Name: toolGetLogs, Arguments: `{"namespace":"default","name":"web"}`},
+			allow:    false,
+			expected: toolDeclinedReply,
+		},
+		{
+			name:     "unknown tool",
+			call:     ai.ToolCall{Name: "delete_object", Arguments: `{}`},
+			allow:    true,
+			expected: "Error: unknown tool delete_object",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newTestToolRunner(tt.allow)
+			out := runner.run(context.Background(), tt.call)
+			require.Contains(t, out, tt.expected)
+			require.Less(t, len(out), maxToolOutput+100)
+		})
+	}
+}
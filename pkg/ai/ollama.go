@@ -0,0 +1,315 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	ollamaClientName     = "ollama"
+	ollamaDefaultBaseURL = "http://localhost:11434"
+	ollamaDefaultModel   = "llama3"
+)
+
+// OllamaClient talks to the native Ollama API instead of its OpenAI compatible shim.
+//
+// The following extra configuration keys are supported:
+//   - api: "chat" (default) or "generate", the endpoint to use
+//   - stream: "true" to stream the answer
+//   - keep_alive: how long the model stays loaded after a request (e.g. "5m")
+//   - num_ctx: size of the context window
+//   - pull: "true" to pull the model if it is not present locally
+type OllamaClient struct {
+	nopCloser
+
+	client      *http.Client
+	baseURL     string
+	model       string
+	temperature float32
+	topP        float32
+	topK        int32
+	maxTokens   int
+	api         string
+	stream      bool
+	keepAlive   string
+	numCtx      int
+	pull        bool
+	checked     bool
+	lastUsage   *Usage
+}
+
+type OllamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type OllamaOptions struct {
+	Temperature float32 `json:"temperature,omitempty"`
+	TopP        float32 `json:"top_p,omitempty"`
+	TopK        int32   `json:"top_k,omitempty"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+	NumCtx      int     `json:"num_ctx,omitempty"`
+}
+
+type OllamaRequest struct {
+	Model     string          `json:"model"`
+	Messages  []OllamaMessage `json:"messages,omitempty"`
+	Prompt    string          `json:"prompt,omitempty"`
+	Stream    bool            `json:"stream"`
+	Format    string          `json:"format,omitempty"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+	Options   OllamaOptions   `json:"options"`
+}
+
+type OllamaResponse struct {
+	Model           string         `json:"model"`
+	Message         *OllamaMessage `json:"message,omitempty"`
+	Response        string         `json:"response,omitempty"`
+	Done            bool           `json:"done"`
+	PromptEvalCount int            `json:"prompt_eval_count,omitempty"`
+	EvalCount       int            `json:"eval_count,omitempty"`
+	Error           string         `json:"error,omitempty"`
+}
+
+type ollamaPullStatus struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (c *OllamaClient) Configure(config IAIConfig) error {
+	c.baseURL = strings.TrimSuffix(config.GetBaseURL(), "/")
+	if c.baseURL == "" {
+		c.baseURL = ollamaDefaultBaseURL
+	}
+	c.model = config.GetModel()
+	if c.model == "" {
+		c.model = ollamaDefaultModel
+	}
+	c.temperature = config.GetTemperature()
+	c.topP = config.GetTopP()
+	c.topK = config.GetTopK()
+	c.maxTokens = config.GetMaxTokens()
+
+	extra := config.GetExtraConfig()
+	c.api = extra["api"]
+	if c.api == "" {
+		c.api = "chat"
+	}
+	if c.api != "chat" && c.api != "generate" {
+		return fmt.Errorf("unsupported ollama api %q, use chat or generate", c.api)
+	}
+	c.stream = extra["stream"] == "true"
+	c.pull = extra["pull"] == "true"
+	c.keepAlive = extra["keep_alive"]
+	if v := extra["num_ctx"]; v != "" {
+		numCtx, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid ollama num_ctx %q: %w", v, err)
+		}
+		c.numCtx = numCtx
+	}
+
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+	c.client = client
+	return nil
+}
+
+func (c *OllamaClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	return c.complete(ctx, []ChatMessage{{Role: ChatRoleUser, Content: prompt}}, "")
+}
+
+// GetStructuredCompletion uses Ollama JSON mode so the answer is always a JSON object.
+func (c *OllamaClient) GetStructuredCompletion(ctx context.Context, prompt string) (string, error) {
+	return c.complete(ctx, []ChatMessage{{Role: ChatRoleUser, Content: prompt}}, "json")
+}
+
+func (c *OllamaClient) GetChatCompletion(ctx context.Context, messages []ChatMessage) (string, error) {
+	return c.complete(ctx, messages, "")
+}
+
+func (c *OllamaClient) GetLastUsage() (Usage, bool) {
+	if c.lastUsage == nil {
+		return Usage{}, false
+	}
+	return *c.lastUsage, true
+}
+
+func (c *OllamaClient) GetName() string {
+	return ollamaClientName
+}
+
+func (c *OllamaClient) complete(ctx context.Context, messages []ChatMessage, format string) (string, error) {
+	if err := c.ensureModel(ctx); err != nil {
+		return "", err
+	}
+
+	request := OllamaRequest{
+		Model:     c.model,
+		Stream:    c.stream,
+		Format:    format,
+		KeepAlive: c.keepAlive,
+		Options: OllamaOptions{
+			Temperature: c.temperature,
+			TopP:        c.topP,
+			TopK:        c.topK,
+			NumPredict:  c.maxTokens,
+			NumCtx:      c.numCtx,
+		},
+	}
+	if c.api == "chat" {
+		for _, m := range messages {
+			request.Messages = append(request.Messages, OllamaMessage{Role: m.Role, Content: m.Content})
+		}
+	} else if len(messages) == 1 {
+		request.Prompt = messages[0].Content
+	} else {
+		request.Prompt = FlattenChatMessages(messages)
+	}
+
+	resp, err := c.post(ctx, "/api/"+c.api, request)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	c.lastUsage = nil
+	var answer strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk OllamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", fmt.Errorf("decoding ollama response: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+		if chunk.Message != nil {
+			answer.WriteString(chunk.Message.Content)
+		}
+		answer.WriteString(chunk.Response)
+		if chunk.Done {
+			c.lastUsage = &Usage{
+				PromptTokens:     chunk.PromptEvalCount,
+				CompletionTokens: chunk.EvalCount,
+			}
+			break
+		}
+	}
+	return answer.String(), nil
+}
+
+// ensureModel checks once per client that the model is available locally and
+// pulls it if allowed to.
+func (c *OllamaClient) ensureModel(ctx context.Context) error {
+	if c.checked {
+		return nil
+	}
+	resp, err := c.request(ctx, "/api/show", map[string]string{"name": c.model})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.checked = true
+		return nil
+	case http.StatusNotFound:
+	default:
+		return fmt.Errorf("ollama /api/show returned %s", resp.Status)
+	}
+	if !c.pull {
+		return fmt.Errorf("ollama model %s is not available, run `ollama pull %s` or set the `pull` extra config to true", c.model, c.model)
+	}
+
+	resp, err = c.post(ctx, "/api/pull", map[string]interface{}{"name": c.model, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var status ollamaPullStatus
+		if err := json.Unmarshal(scanner.Bytes(), &status); err != nil {
+			return fmt.Errorf("decoding ollama pull status: %w", err)
+		}
+		if status.Error != "" {
+			return fmt.Errorf("pulling ollama model %s: %s", c.model, status.Error)
+		}
+		if status.Total > 0 {
+			fmt.Fprintf(os.Stderr, "\rpulling %s: %s %d%%", c.model, status.Status, status.Completed*100/status.Total)
+		} else {
+			fmt.Fprintf(os.Stderr, "\rpulling %s: %s", c.model, status.Status)
+		}
+		if status.Status == "success" {
+			fmt.Fprintln(os.Stderr)
+			c.checked = true
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("pulling ollama model %s did not complete", c.model)
+}
+
+func (c *OllamaClient) request(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.client.Do(req)
+}
+
+// post sends body to path and turns non-OK responses into errors.
+func (c *OllamaClient) post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	resp, err := c.request(ctx, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var response OllamaResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err == nil && response.Error != "" {
+			return nil, fmt.Errorf("ollama %s returned %s: %s", path, resp.Status, response.Error)
+		}
+		return nil, fmt.Errorf("ollama %s returned %s", path, resp.Status)
+	}
+	return resp, nil
+}
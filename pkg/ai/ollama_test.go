@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newOllamaStandIn returns a server mimicking the parts of the Ollama API used
+// by the client. Only the models in available are known until pulled.
+func newOllamaStandIn(t *testing.T, available map[string]bool, requests *[]OllamaRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/show":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if !available[body["name"]] {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"error":"model '%s' not found"}`, body["name"])
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		case "/api/pull":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			available[body["name"].(string)] = true
+			fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+			fmt.Fprintln(w, `{"status":"downloading","total":100,"completed":50}`)
+			fmt.Fprintln(w, `{"status":"success"}`)
+		case "/api/chat", "/api/generate":
+			var req OllamaRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			*requests = append(*requests, req)
+			if r.URL.Path == "/api/generate" {
+				fmt.Fprint(w, `{"response":"generated","done":true,"prompt_eval_count":3,"eval_count":1}`)
+				return
+			}
+			if req.Stream {
+				fmt.Fprintln(w, `{"message":{"role":"assistant","content":"Hello"},"done":false}`)
+				fmt.Fprintln(w, `{"message":{"role":"assistant","content":" world"},"done":false}`)
+				fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":7,"eval_count":2}`)
+				return
+			}
+			fmt.Fprint(w, `{"message":{"role":"assistant","content":"Hello world"},"done":true,"prompt_eval_count":7,"eval_count":2}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestOllamaClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		extra         map[string]string
+		available     bool
+		expected      string
+		expectedUsage Usage
+		expectedErr   string
+	}{
+		{
+			name:          "chat",
+			available:     true,
+			extra:         map[string]string{"num_ctx": "8192", "keep_alive": "10m"},
+			expected:      "Hello world",
+			expectedUsage: Usage{PromptTokens: 7, CompletionTokens: 2},
+		},
+		{
+			name:          "chat streaming",
+			available:     true,
+			extra:         map[string]string{"stream": "true"},
+			expected:      "Hello world",
+			expectedUsage: Usage{PromptTokens: 7, CompletionTokens: 2},
+		},
+		{
+			name:          "generate",
+			available:     true,
+			extra:         map[string]string{"api": "generate"},
+			expected:      "generated",
+			expectedUsage: Usage{PromptTokens: 3, CompletionTokens: 1},
+		},
+		{
+			name:        "missing model",
+			expectedErr: "ollama model llama3 is not available",
+		},
+		{
+			name:          "missing model pulled",
+			extra:         map[string]string{"pull": "true"},
+			expected:      "Hello world",
+			expectedUsage: Usage{PromptTokens: 7, CompletionTokens: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []OllamaRequest
+			server := newOllamaStandIn(t, map[string]bool{"llama3": tt.available}, &requests)
+			defer server.Close()
+
+			client := &OllamaClient{}
+			require.NoError(t, client.Configure(&AIProvider{
+				Name:        "ollama",
+				BaseURL:     server.URL,
+				Temperature: 0.7,
+				TopK:        50,
+				MaxTokens:   2048,
+				ExtraConfig: tt.extra,
+			}))
+
+			answer, err := client.GetCompletion(context.Background(), "why is my pod crashing?")
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, answer)
+
+			usage, ok := client.GetLastUsage()
+			require.True(t, ok)
+			require.Equal(t, tt.expectedUsage, usage)
+
+			require.Len(t, requests, 1)
+			require.Equal(t, "llama3", requests[0].Model)
+			require.Equal(t, 50, int(requests[0].Options.TopK))
+			require.Equal(t, 2048, requests[0].Options.NumPredict)
+			if tt.extra["num_ctx"] != "" {
+				require.Equal(t, 8192, requests[0].Options.NumCtx)
+				require.Equal(t, "10m", requests[0].KeepAlive)
+			}
+		})
+	}
+}
+
+func TestOllamaClientStructured(t *testing.T) {
+	var requests []OllamaRequest
+	server := newOllamaStandIn(t, map[string]bool{"mistral": true}, &requests)
+	defer server.Close()
+
+	client := &OllamaClient{}
+	require.NoError(t, client.Configure(&AIProvider{Name: "ollama", Model: "mistral", BaseURL: server.URL}))
+	_, err := GetStructuredCompletion(context.Background(), client, "explain")
+	require.NoError(t, err)
+	require.Equal(t, "json", requests[0].Format)
+	require.Equal(t, "user", requests[0].Messages[0].Role)
+}
+
+func TestOllamaClientInvalidConfig(t *testing.T) {
+	client := &OllamaClient{}
+	require.ErrorContains(t, client.Configure(&AIProvider{ExtraConfig: map[string]string{"api": "embeddings"}}), "unsupported ollama api")
+	require.ErrorContains(t, client.Configure(&AIProvider{ExtraConfig: map[string]string{"num_ctx": "big"}}), "invalid ollama num_ctx")
+}
@@ -16,8 +16,6 @@ package ai
 import (
 	"context"
 	"errors"
-	"net/http"
-	"net/url"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -31,6 +29,7 @@ type OpenAIClient struct {
 	model       string
 	temperature float32
 	topP        float32
+	lastUsage   *Usage
 }
 
 const (
@@ -43,26 +42,17 @@ const (
 func (c *OpenAIClient) Configure(config IAIConfig) error {
 	token := config.GetPassword()
 	defaultConfig := openai.DefaultConfig(token)
-	proxyEndpoint := config.GetProxyEndpoint()
 
 	baseURL := config.GetBaseURL()
 	if baseURL != "" {
 		defaultConfig.BaseURL = baseURL
 	}
 
-	if proxyEndpoint != "" {
-		proxyUrl, err := url.Parse(proxyEndpoint)
-		if err != nil {
-			return err
-		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-
-		defaultConfig.HTTPClient = &http.Client{
-			Transport: transport,
-		}
+	httpClient, err := NewHTTPClient(config)
+	if err != nil {
+		return err
 	}
+	defaultConfig.HTTPClient = httpClient
 
 	client := openai.NewClientWithConfig(defaultConfig)
 	if client == nil {
@@ -76,25 +66,123 @@ func (c *OpenAIClient) Configure(config IAIConfig) error {
 }
 
 func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	return c.getCompletion(ctx, toOpenAIMessages([]ChatMessage{{Role: ChatRoleUser, Content: prompt}}), nil)
+}
+
+// GetStructuredCompletion uses OpenAI JSON mode so the answer is always a JSON object.
+func (c *OpenAIClient) GetStructuredCompletion(ctx context.Context, prompt string) (string, error) {
+	return c.getCompletion(ctx, toOpenAIMessages([]ChatMessage{{Role: ChatRoleUser, Content: prompt}}), &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+	})
+}
+
+func (c *OpenAIClient) GetChatCompletion(ctx context.Context, messages []ChatMessage) (string, error) {
+	return c.getCompletion(ctx, toOpenAIMessages(messages), nil)
+}
+
+// GetToolCompletion uses function calling to let the model request tools.
+func (c *OpenAIClient) GetToolCompletion(ctx context.Context, messages []ChatMessage, tools []Tool) (ChatMessage, error) {
+	message, err := c.chatCompletion(ctx, toOpenAIMessages(messages), nil, toOpenAITools(tools))
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	return fromOpenAIMessage(message), nil
+}
+
+func (c *OpenAIClient) getCompletion(ctx context.Context, messages []openai.ChatCompletionMessage, format *openai.ChatCompletionResponseFormat) (string, error) {
+	message, err := c.chatCompletion(ctx, messages, format, nil)
+	if err != nil {
+		return "", err
+	}
+	return message.Content, nil
+}
+
+func (c *OpenAIClient) chatCompletion(ctx context.Context, messages []openai.ChatCompletionMessage, format *openai.ChatCompletionResponseFormat, tools []openai.Tool) (openai.ChatCompletionMessage, error) {
 	// Create a completion request
 	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: c.model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+		Model:            c.model,
+		Messages:         messages,
 		Temperature:      c.temperature,
 		MaxTokens:        maxToken,
 		PresencePenalty:  presencePenalty,
 		FrequencyPenalty: frequencyPenalty,
 		TopP:             c.topP,
+		ResponseFormat:   format,
+		Tools:            tools,
 	})
 	if err != nil {
-		return "", err
+		return openai.ChatCompletionMessage{}, err
+	}
+	// OpenAI compatible backends may not report the usage, the tokens are
+	// estimated then
+	c.lastUsage = nil
+	if resp.Usage.TotalTokens > 0 {
+		c.lastUsage = &Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		}
+	}
+	return resp.Choices[0].Message, nil
+}
+
+func (c *OpenAIClient) GetLastUsage() (Usage, bool) {
+	if c.lastUsage == nil {
+		return Usage{}, false
+	}
+	return *c.lastUsage, true
+}
+
+func toOpenAIMessages(messages []ChatMessage) []openai.ChatCompletionMessage {
+	out := make([]openai.ChatCompletionMessage, 0, len(messages))
+	for _, m := range messages {
+		message := openai.ChatCompletionMessage{
+			Role:       m.Role,
+			Content:    m.Content,
+			ToolCallID: m.ToolCallID,
+		}
+		for _, call := range m.ToolCalls {
+			message.ToolCalls = append(message.ToolCalls, openai.ToolCall{
+				ID:   call.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      call.Name,
+					Arguments: call.Arguments,
+				},
+			})
+		}
+		out = append(out, message)
+	}
+	return out
+}
+
+func fromOpenAIMessage(m openai.ChatCompletionMessage) ChatMessage {
+	message := ChatMessage{
+		Role:    m.Role,
+		Content: m.Content,
+	}
+	for _, call := range m.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return message
+}
+
+func toOpenAITools(tools []Tool) []openai.Tool {
+	out := make([]openai.Tool, 0, len(tools))
+	for _, tool := range tools {
+		out = append(out, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
 	}
-	return resp.Choices[0].Message.Content, nil
+	return out
 }
 
 func (c *OpenAIClient) GetName() string {
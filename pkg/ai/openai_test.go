@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIClientUsage(t *testing.T) {
+	tests := []struct {
+		name          string
+		usage         string
+		expectedUsage *Usage
+	}{
+		{
+			name:          "reported",
+			usage:         `{"prompt_tokens":12,"completion_tokens":5,"total_tokens":17}`,
+			expectedUsage: &Usage{PromptTokens: 12, CompletionTokens: 5},
+		},
+		{
+			name:  "not reported",
+			usage: `{"prompt_tokens":0,"completion_tokens":0,"total_tokens":0}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":"Error: bad image"}}],"usage":%s}`, tt.usage)
+			}))
+			defer server.Close()
+
+			client := &OpenAIClient{}
+			require.NoError(t, client.Configure(&AIProvider{Name: "localai", Model: "llama", BaseURL: server.URL}))
+			// a usage left over from an earlier completion is not reported
+			client.lastUsage = &Usage{PromptTokens: 1, CompletionTokens: 1}
+
+			answer, err := client.GetCompletion(context.Background(), "why is my pod failing?")
+			require.NoError(t, err)
+			require.Equal(t, "Error: bad image", answer)
+
+			usage, ok := client.GetLastUsage()
+			if tt.expectedUsage == nil {
+				require.False(t, ok)
+			} else {
+				require.True(t, ok)
+				require.Equal(t, *tt.expectedUsage, usage)
+			}
+		})
+	}
+}
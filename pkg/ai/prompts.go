@@ -50,7 +50,24 @@ const (
 	`
 	coze_prompt = "%s"
 
-	k8s_manifest_prompt = "You are an expert Kubernetes YAML generator, that only generates valid Kubernetes YAML manifests. You should never provide any explanations. You should always output raw YAML only, and always wrap the raw YAML with ```yaml. My requriment is %s"
+	structured_prompt = `Simplify the following error message delimited by triple dashes written in --- %s --- language; --- %s ---.
+	Provide the most possible solution in a step by step style accord to supplied doc.
+	Respond with a single JSON object and nothing else, using exactly these fields:
+	{"summary": string, "root_cause": string, "steps": [string], "commands": [string], "confidence": number between 0 and 1, "references": [string]}
+	`
+
+	k8s_manifest_prompt        = "You are an expert Kubernetes YAML generator, that only generates valid Kubernetes YAML manifests. You should never provide any explanations. You should always output raw YAML only, and always wrap the raw YAML with ```yaml. My requriment is %s"
+	k8s_manifest_repair_prompt = "You are an expert Kubernetes YAML generator, that only generates valid Kubernetes YAML manifests. The following manifest was rejected by the cluster with these errors:\n%s\nFix all of the errors and keep everything else unchanged. You should never provide any explanations. You should always output raw YAML only, and always wrap the raw YAML with ```yaml. The manifest is:\n%s"
+	k8s_manifest_revise_prompt = "You are an expert Kubernetes YAML generator, that only generates valid Kubernetes YAML manifests. Change the following manifest as requested:\n%s\nKeep everything else unchanged. You should never provide any explanations. You should always output raw YAML only, and always wrap the raw YAML with ```yaml. The manifest is:\n%s"
+
+	fix_patch_prompt = `You are a Kubernetes expert. K8sGPT found the following problems with the %s %s:
+	%s
+	Write a patch against the object below that fixes the problems without changing anything else.
+	Respond with a single JSON object and nothing else, using exactly these fields:
+	{"patch_type": "strategic" or "merge" or "json", "patch": the patch (an object, or a JSON patch list of operations), "explanation": string}
+	The object is:
+	%s
+	`
 )
 
 var PromptMap = map[string]string{
@@ -60,5 +77,9 @@ var PromptMap = map[string]string{
 	"PrometheusConfigValidate":      prom_conf_prompt,
 	"PrometheusConfigRelabelReport": prom_relabel_prompt,
 	"coze":                          coze_prompt,
+	"structured":                    structured_prompt,
 	"k8s_manifest":                  k8s_manifest_prompt,
+	"k8s_manifest_repair":           k8s_manifest_repair_prompt,
+	"k8s_manifest_revise":           k8s_manifest_revise_prompt,
+	"fix_patch":                     fix_patch_prompt,
 }
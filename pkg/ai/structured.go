@@ -0,0 +1,35 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+)
+
+// IStructuredAI is implemented by clients whose backend can be constrained to
+// emit a single JSON object (e.g. OpenAI JSON mode). Clients that do not
+// implement it are still asked for JSON through the prompt only.
+type IStructuredAI interface {
+	// GetStructuredCompletion generates a JSON document based on prompt.
+	GetStructuredCompletion(ctx context.Context, prompt string) (string, error)
+}
+
+// GetStructuredCompletion asks client for a JSON answer, using the backend's
+// native JSON mode when it has one.
+func GetStructuredCompletion(ctx context.Context, client IAI, prompt string) (string, error) {
+	if sc, ok := client.(IStructuredAI); ok {
+		return sc.GetStructuredCompletion(ctx, prompt)
+	}
+	return client.GetCompletion(ctx, prompt)
+}
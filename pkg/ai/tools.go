@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	toolCallMarker   = "TOOL_CALL:"
+	toolResultMarker = "TOOL_RESULT"
+)
+
+// Tool describes a function the model may ask to call. Parameters is the
+// JSON schema of the arguments object.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
+// ToolCall is a request of the model to call a tool. Arguments is a JSON object.
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// IToolAI is implemented by clients whose backend supports native function
+// calling.
+type IToolAI interface {
+	// GetToolCompletion generates the next assistant message, which either
+	// answers or requests one or more tool calls.
+	GetToolCompletion(ctx context.Context, messages []ChatMessage, tools []Tool) (ChatMessage, error)
+}
+
+// GetToolCompletion continues the conversation and lets the model request
+// tool calls. Backends without function calling are taught a plain text
+// protocol instead, and their answer is parsed for tool calls.
+func GetToolCompletion(ctx context.Context, client IAI, messages []ChatMessage, tools []Tool) (ChatMessage, error) {
+	if tc, ok := client.(IToolAI); ok {
+		return tc.GetToolCompletion(ctx, messages, tools)
+	}
+
+	var converted []ChatMessage
+	if len(tools) > 0 {
+		converted = append(converted, ChatMessage{Role: ChatRoleSystem, Content: toolProtocolPrompt(tools)})
+	}
+	for _, m := range messages {
+		switch {
+		case m.Role == ChatRoleTool:
+			converted = append(converted, ChatMessage{
+				Role:    ChatRoleUser,
+				Content: fmt.Sprintf("%s %s:\n%s", toolResultMarker, m.ToolCallID, m.Content),
+			})
+		case len(m.ToolCalls) > 0:
+			lines := []string{}
+			if m.Content != "" {
+				lines = append(lines, m.Content)
+			}
+			for _, call := range m.ToolCalls {
+				lines = append(lines, formatToolCall(call))
+			}
+			converted = append(converted, ChatMessage{Role: m.Role, Content: strings.Join(lines, "\n")})
+		default:
+			converted = append(converted, m)
+		}
+	}
+
+	response, err := GetChatCompletion(ctx, client, converted)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	if len(tools) == 0 {
+		return ChatMessage{Role: ChatRoleAssistant, Content: response}, nil
+	}
+	return ParseToolCalls(response, len(messages)), nil
+}
+
+// ParseToolCalls extracts the tool calls of the text protocol from response.
+// The IDs of the calls are derived from turn so they are unique within a
+// conversation.
+func ParseToolCalls(response string, turn int) ChatMessage {
+	message := ChatMessage{Role: ChatRoleAssistant}
+	var content []string
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, toolCallMarker) {
+			content = append(content, line)
+			continue
+		}
+		var call struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(trimmed, toolCallMarker)), &call); err != nil || call.Name == "" {
+			content = append(content, line)
+			continue
+		}
+		arguments := string(call.Arguments)
+		if arguments == "" {
+			arguments = "{}"
+		}
+		message.ToolCalls = append(message.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d_%d", turn, len(message.ToolCalls)),
+			Name:      call.Name,
+			Arguments: arguments,
+		})
+	}
+	message.Content = strings.TrimSpace(strings.Join(content, "\n"))
+	return message
+}
+
+func formatToolCall(call ToolCall) string {
+	return fmt.Sprintf("%s {\"name\": %q, \"arguments\": %s}", toolCallMarker, call.Name, call.Arguments)
+}
+
+func toolProtocolPrompt(tools []Tool) string {
+	var b strings.Builder
+	b.WriteString("You can request read-only information from the Kubernetes cluster before answering. ")
+	b.WriteString(fmt.Sprintf("To do so, reply with nothing but one line per request in the form %s {\"name\": \"<tool>\", \"arguments\": {<arguments>}}. ", toolCallMarker))
+	b.WriteString(fmt.Sprintf("The results are sent back to you in messages starting with %s. ", toolResultMarker))
+	b.WriteString("Answer normally once you have enough information. The available tools are:\n")
+	for _, tool := range tools {
+		parameters, _ := json.Marshal(tool.Parameters)
+		b.WriteString(fmt.Sprintf("- %s: %s Arguments schema: %s\n", tool.Name, tool.Description, parameters))
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedAIClient answers with the given responses in order and records the prompts.
+type scriptedAIClient struct {
+	NoOpAIClient
+	responses []string
+	prompts   []string
+}
+
+func (c *scriptedAIClient) GetCompletion(_ context.Context, prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	response := c.responses[0]
+	c.responses = c.responses[1:]
+	return response, nil
+}
+
+func TestParseToolCalls(t *testing.T) {
+	message := ParseToolCalls("Let me check.\nTOOL_CALL: {\"name\": \"get_logs\", \"arguments\": {\"name\": \"web\"}}\n  TOOL_CALL: {\"name\": \"list_events\"}\nTOOL_CALL: not json", 3)
+	require.Equal(t, ChatRoleAssistant, message.Role)
+	require.Equal(t, "Let me check.\nTOOL_CALL: not json", message.Content)
+	require.Equal(t, []ToolCall{
+		{ID: "call_3_0", Name: "get_logs", Arguments: `{"name": "web"}`},
+		{ID: "call_3_1", Name: "list_events", Arguments: "{}"},
+	}, message.ToolCalls)
+}
+
+func TestGetToolCompletion_TextProtocol(t *testing.T) {
+	client := &scriptedAIClient{responses: []string{"the pod has no memory left"}}
+	tools := []Tool{{Name: "get_logs", Description: "Tail the logs of a pod.", Parameters: map[string]any{"type": "object"}}}
+
+	message, err := GetToolCompletion(context.Background(), client, []ChatMessage{
+		{Role: ChatRoleUser, Content: "why does web crash?"},
+		{Role: ChatRoleAssistant, ToolCalls: []ToolCall{{ID: "call_1_0", Name: "get_logs", Arguments: `{"name":"web"}`}}},
+		{Role: ChatRoleTool, ToolCallID: "call_1_0", Content: "out of memory"},
+	}, tools)
+	require.NoError(t, err)
+	require.Equal(t, "the pod has no memory left", message.Content)
+	require.Empty(t, message.ToolCalls)
+
+	prompt := client.prompts[0]
+	require.Contains(t, prompt, "- get_logs: Tail the logs of a pod. Arguments schema: {\"type\":\"object\"}")
+	require.Contains(t, prompt, "Assistant: TOOL_CALL: {\"name\": \"get_logs\", \"arguments\": {\"name\":\"web\"}}")
+	require.Contains(t, prompt, "User: TOOL_RESULT call_1_0:\nout of memory")
+}
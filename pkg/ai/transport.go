@@ -0,0 +1,211 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Extra configuration keys understood by NewHTTPClient. They let HTTP based
+// backends talk to gateways that need more than a base URL.
+const (
+	// headerPrefix marks a header sent with every request, e.g. "header.X-Tenant": "team-a".
+	headerPrefix = "header."
+	// pathPrefixKey is prepended to the path of every request.
+	pathPrefixKey = "path_prefix"
+	// timeoutKey is the overall timeout of a request, e.g. "90s".
+	timeoutKey = "timeout"
+	// retriesKey is how many times a request is retried on 429 and 5xx
+	// responses and on network errors. Completion requests, which are POSTs,
+	// are only retried on network errors when the connection could not be
+	// established. retryBackoffKey is the initial wait between retries.
+	retriesKey      = "retries"
+	retryBackoffKey = "retry_backoff"
+	// TLS settings. The CA file is added to the system pool.
+	tlsCAFileKey             = "tls_ca_file"
+	tlsCertFileKey           = "tls_cert_file"
+	tlsKeyFileKey            = "tls_key_file"
+	tlsServerNameKey         = "tls_server_name"
+	tlsInsecureSkipVerifyKey = "tls_insecure_skip_verify"
+)
+
+const defaultRetryBackoff = time.Second
+
+// NewHTTPClient builds the HTTP client shared by all HTTP based backends from
+// the proxy endpoint and the transport related extra configuration keys.
+func NewHTTPClient(config IAIConfig) (*http.Client, error) {
+	extra := config.GetExtraConfig()
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyEndpoint := config.GetProxyEndpoint(); proxyEndpoint != "" {
+		proxyUrl, err := url.Parse(proxyEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		base.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	tlsConfig, err := newTLSConfig(extra)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		base.TLSClientConfig = tlsConfig
+	}
+
+	rt := &gatewayTransport{
+		base:       base,
+		headers:    map[string]string{},
+		pathPrefix: strings.TrimSuffix(extra[pathPrefixKey], "/"),
+		backoff:    defaultRetryBackoff,
+	}
+	for k, v := range extra {
+		if strings.HasPrefix(k, headerPrefix) {
+			rt.headers[strings.TrimPrefix(k, headerPrefix)] = v
+		}
+	}
+	if v := extra[retriesKey]; v != "" {
+		if rt.retries, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", retriesKey, v, err)
+		}
+	}
+	if v := extra[retryBackoffKey]; v != "" {
+		if rt.backoff, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", retryBackoffKey, v, err)
+		}
+	}
+
+	client := &http.Client{Transport: rt}
+	if v := extra[timeoutKey]; v != "" {
+		if client.Timeout, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", timeoutKey, v, err)
+		}
+	}
+	return client, nil
+}
+
+func newTLSConfig(extra map[string]string) (*tls.Config, error) {
+	caFile, certFile, keyFile := extra[tlsCAFileKey], extra[tlsCertFileKey], extra[tlsKeyFileKey]
+	serverName, insecure := extra[tlsServerNameKey], extra[tlsInsecureSkipVerifyKey] == "true"
+	if caFile == "" && certFile == "" && keyFile == "" && serverName == "" && !insecure {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: insecure, // #nosec G402 -- explicitly requested by the user
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("both tls_cert_file and tls_key_file are required for client certificates")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// gatewayTransport adds headers and a path prefix to every request and
+// retries transient failures with exponential backoff.
+type gatewayTransport struct {
+	base       http.RoundTripper
+	headers    map[string]string
+	pathPrefix string
+	retries    int
+	backoff    time.Duration
+}
+
+func (t *gatewayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if t.pathPrefix != "" && !strings.HasPrefix(req.URL.Path, t.pathPrefix+"/") {
+		req.URL.Path = t.pathPrefix + req.URL.Path
+		req.URL.RawPath = ""
+	}
+
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.retries || !isRetryable(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+func isRetryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// a request that may have reached the backend is not sent again
+		// unless it is idempotent, a completion could be billed twice
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return true
+		}
+		var opErr *net.OpError
+		return (errors.As(err, &opErr) && opErr.Op == "dial") || errors.Is(err, syscall.ECONNREFUSED)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClientHeadersPrefixAndRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		require.Equal(t, "payload", string(body))
+		require.Equal(t, "team-a", r.Header.Get("X-Tenant"))
+		require.Equal(t, "/gateway/v1/chat/completions", r.URL.Path)
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(&AIProvider{ExtraConfig: map[string]string{
+		"header.X-Tenant": "team-a",
+		"path_prefix":     "/gateway/",
+		"retries":         "2",
+		"retry_backoff":   "1ms",
+		"timeout":         "5s",
+	}})
+	require.NoError(t, err)
+
+	resp, err := client.Post(server.URL+"/v1/chat/completions", "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 3, attempts)
+}
+
+func TestNewHTTPClientGivesUpAfterRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(&AIProvider{ExtraConfig: map[string]string{"retries": "1", "retry_backoff": "1ms"}})
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, 2, attempts)
+}
+
+func TestNewHTTPClientDoesNotResendPosts(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		_, _ = io.ReadAll(r.Body)
+		// the connection drops after the backend received the completion request
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(&AIProvider{ExtraConfig: map[string]string{"retries": "2", "retry_backoff": "1ms"}})
+	require.NoError(t, err)
+	_, err = client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestIsRetryable(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		err      error
+		expected bool
+	}{
+		{name: "post rate limited", method: http.MethodPost, status: http.StatusTooManyRequests, expected: true},
+		{name: "post unavailable", method: http.MethodPost, status: http.StatusServiceUnavailable, expected: true},
+		{name: "post bad request", method: http.MethodPost, status: http.StatusBadRequest},
+		{name: "post dial error", method: http.MethodPost, err: dialErr, expected: true},
+		{name: "post connection refused", method: http.MethodPost, err: fmt.Errorf("proxy: %w", syscall.ECONNREFUSED), expected: true},
+		{name: "post connection reset", method: http.MethodPost, err: readErr},
+		{name: "get connection reset", method: http.MethodGet, err: readErr, expected: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+			require.Equal(t, tt.expected, isRetryable(&http.Request{Method: tt.method}, resp, tt.err))
+		})
+	}
+}
+
+func TestNewHTTPClientCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	// Without the CA the self-signed certificate is rejected.
+	client, err := NewHTTPClient(&AIProvider{})
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	require.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+
+	client, err = NewHTTPClient(&AIProvider{ExtraConfig: map[string]string{"tls_ca_file": caFile}})
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestNewHTTPClientInvalidConfig(t *testing.T) {
+	tests := map[string]map[string]string{
+		"invalid retries":   {"retries": "many"},
+		"invalid timeout":   {"timeout": "soon"},
+		"missing key file":  {"tls_cert_file": "cert.pem"},
+		"missing CA bundle": {"tls_ca_file": "/does/not/exist.pem"},
+		"invalid backoff":   {"retry_backoff": "x"},
+	}
+	for name, extra := range tests {
+		extra := extra
+		t.Run(name, func(t *testing.T) {
+			_, err := NewHTTPClient(&AIProvider{ExtraConfig: extra})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestOpenAIClientUsesGatewayHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret-gateway-key", r.Header.Get("X-Gateway-Key"))
+		require.Equal(t, "/llm/v1/chat/completions", r.URL.Path)
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	client := &OpenAIClient{}
+	require.NoError(t, client.Configure(&AIProvider{
+		Password: "token",
+		BaseURL:  server.URL + "/v1",
+		ExtraConfig: map[string]string{
+			"header.X-Gateway-Key": "secret-gateway-key",
+			"path_prefix":          "/llm",
+		},
+	}))
+	answer, err := client.GetCompletion(context.Background(), "hello")
+	require.NoError(t, err)
+	require.Equal(t, "hi", answer)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	PromptTokensMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_prompt_tokens_total",
+		Help: "Number of prompt tokens sent to the AI backend",
+	}, []string{"backend", "model"})
+	CompletionTokensMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_completion_tokens_total",
+		Help: "Number of completion tokens returned by the AI backend",
+	}, []string{"backend", "model"})
+	CostMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_estimated_cost_dollars_total",
+		Help: "Estimated cost in USD of the AI backend requests",
+	}, []string{"backend", "model"})
+)
+
+// Usage is the number of tokens consumed by a single completion.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// IUsageAI is implemented by clients whose backend reports token usage.
+type IUsageAI interface {
+	// GetLastUsage returns the usage reported for the most recent completion,
+	// and false if the backend did not report any.
+	GetLastUsage() (Usage, bool)
+}
+
+// ModelPrice is the price in USD per one million tokens.
+type ModelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// modelPrices holds list prices for commonly used hosted models. Prices for
+// other models can be set with the `prompt_price` and `completion_price`
+// extra configuration keys of a provider.
+var modelPrices = map[string]ModelPrice{
+	"gpt-3.5-turbo":   {Prompt: 0.5, Completion: 1.5},
+	"gpt-4":           {Prompt: 30, Completion: 60},
+	"gpt-4-turbo":     {Prompt: 10, Completion: 30},
+	"gpt-4o":          {Prompt: 5, Completion: 15},
+	"claude-3-haiku":  {Prompt: 0.25, Completion: 1.25},
+	"claude-3-sonnet": {Prompt: 3, Completion: 15},
+	"claude-3-opus":   {Prompt: 15, Completion: 75},
+}
+
+// EstimateTokens approximates the token count of text for backends that do
+// not report usage, using the common heuristic of four characters per token.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// UsageSummary is the accumulated token usage of an analysis run.
+type UsageSummary struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	TotalTokens      int     `json:"totalTokens"`
+	EstimatedCost    float64 `json:"estimatedCost"`
+	Estimated        bool    `json:"estimated"`
+	BudgetExceeded   bool    `json:"budgetExceeded,omitempty"`
+	Skipped          int     `json:"skipped,omitempty"`
+}
+
+// TokenCounter accumulates token usage and cost for a backend and enforces
+// the optional budgets. A zero budget means unlimited.
+type TokenCounter struct {
+	MaxTotalTokens int
+	MaxCost        float64
+
+	mu      sync.Mutex
+	backend string
+	model   string
+	price   ModelPrice
+	summary UsageSummary
+}
+
+// NewTokenCounter creates a counter for the given provider configuration.
+func NewTokenCounter(config IAIConfig) *TokenCounter {
+	t := &TokenCounter{
+		model: config.GetModel(),
+	}
+	if p, ok := config.(*AIProvider); ok {
+		t.backend = p.Name
+	}
+	t.price = lookupModelPrice(t.model)
+	extra := config.GetExtraConfig()
+	if v, err := strconv.ParseFloat(extra["prompt_price"], 64); err == nil {
+		t.price.Prompt = v
+	}
+	if v, err := strconv.ParseFloat(extra["completion_price"], 64); err == nil {
+		t.price.Completion = v
+	}
+	return t
+}
+
+func lookupModelPrice(model string) ModelPrice {
+	// Prefer the longest matching prefix so that e.g. "gpt-4o-2024-05-13"
+	// does not match "gpt-4".
+	var best string
+	for name := range modelPrices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	return modelPrices[best]
+}
+
+// Record adds the usage of one completion. The usage reported by client is
+// used when available, otherwise it is estimated from prompt and response.
+func (t *TokenCounter) Record(client IAI, prompt string, response string) Usage {
+	usage, ok := Usage{}, false
+	if u, isUsageAI := client.(IUsageAI); isUsageAI {
+		usage, ok = u.GetLastUsage()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !ok {
+		usage = Usage{
+			PromptTokens:     EstimateTokens(prompt),
+			CompletionTokens: EstimateTokens(response),
+		}
+		t.summary.Estimated = true
+	}
+	cost := (float64(usage.PromptTokens)*t.price.Prompt + float64(usage.CompletionTokens)*t.price.Completion) / 1e6
+
+	t.summary.Requests++
+	t.summary.PromptTokens += usage.PromptTokens
+	t.summary.CompletionTokens += usage.CompletionTokens
+	t.summary.TotalTokens += usage.PromptTokens + usage.CompletionTokens
+	t.summary.EstimatedCost += cost
+
+	backend := t.backend
+	if backend == "" {
+		backend = client.GetName()
+	}
+	PromptTokensMetric.WithLabelValues(backend, t.model).Add(float64(usage.PromptTokens))
+	CompletionTokensMetric.WithLabelValues(backend, t.model).Add(float64(usage.CompletionTokens))
+	CostMetric.WithLabelValues(backend, t.model).Add(cost)
+	return usage
+}
+
+// Exceeded reports whether one of the budgets has been used up.
+func (t *TokenCounter) Exceeded() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.MaxTotalTokens > 0 && t.summary.TotalTokens >= t.MaxTotalTokens {
+		return true
+	}
+	if t.MaxCost > 0 && t.summary.EstimatedCost >= t.MaxCost {
+		return true
+	}
+	return false
+}
+
+// Skip records that a request was not sent because the budget was exceeded.
+func (t *TokenCounter) Skip() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.summary.BudgetExceeded = true
+	t.summary.Skipped++
+}
+
+// Summary returns a copy of the accumulated usage.
+func (t *TokenCounter) Summary() UsageSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.summary
+}
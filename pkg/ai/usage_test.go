@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type usageClient struct {
+	NoOpAIClient
+	usage Usage
+}
+
+func (c *usageClient) GetLastUsage() (Usage, bool) {
+	return c.usage, true
+}
+
+func TestTokenCounter(t *testing.T) {
+	counter := NewTokenCounter(&AIProvider{Name: "openai", Model: "gpt-4o-2024-05-13"})
+	counter.MaxTotalTokens = 2000
+
+	client := &usageClient{usage: Usage{PromptTokens: 1000, CompletionTokens: 500}}
+	counter.Record(client, "prompt", "response")
+	require.False(t, counter.Exceeded())
+
+	summary := counter.Summary()
+	require.Equal(t, 1, summary.Requests)
+	require.Equal(t, 1500, summary.TotalTokens)
+	require.False(t, summary.Estimated)
+	// gpt-4o pricing, not gpt-4.
+	require.InDelta(t, 0.0125, summary.EstimatedCost, 1e-9)
+
+	counter.Record(client, "prompt", "response")
+	require.True(t, counter.Exceeded())
+	counter.Skip()
+	require.True(t, counter.Summary().BudgetExceeded)
+	require.Equal(t, 1, counter.Summary().Skipped)
+}
+
+func TestTokenCounterEstimatesAndCustomPrice(t *testing.T) {
+	counter := NewTokenCounter(&AIProvider{
+		Name:  "localai",
+		Model: "llama3",
+		ExtraConfig: map[string]string{
+			"prompt_price":     "1",
+			"completion_price": "2",
+		},
+	})
+	counter.MaxCost = 0.00001
+
+	usage := counter.Record(&NoOpAIClient{}, "12345678", "1234")
+	require.Equal(t, Usage{PromptTokens: 2, CompletionTokens: 1}, usage)
+	summary := counter.Summary()
+	require.True(t, summary.Estimated)
+	require.InDelta(t, 0.000004, summary.EstimatedCost, 1e-12)
+	require.False(t, counter.Exceeded())
+}
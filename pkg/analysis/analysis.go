@@ -49,6 +49,12 @@ type Analysis struct {
 	MaxConcurrency     int
 	AnalysisAIProvider string // The name of the AI Provider used for this analysis
 	WithDoc            bool
+	Structured         bool // Ask the AI Provider for a structured (JSON) explanation
+	Usage              *ai.TokenCounter
+	Suppressions       []Suppression // Rules hiding known problems
+	Suppressed         int           // Failures hidden by suppressions or the ignore annotation
+	// Suppressions past their expiry date, which no longer hide anything
+	ExpiredSuppressions []Suppression
 }
 
 type (
@@ -62,11 +68,15 @@ const (
 )
 
 type JsonOutput struct {
-	Provider string          `json:"provider"`
-	Errors   AnalysisErrors  `json:"errors"`
-	Status   AnalysisStatus  `json:"status"`
-	Problems int             `json:"problems"`
-	Results  []common.Result `json:"results"`
+	Provider string           `json:"provider"`
+	Errors   AnalysisErrors   `json:"errors"`
+	Status   AnalysisStatus   `json:"status"`
+	Problems int              `json:"problems"`
+	Results  []common.Result  `json:"results"`
+	Usage    *ai.UsageSummary `json:"usage,omitempty"`
+	// Suppressed counts the problems hidden by suppressions
+	Suppressed          int           `json:"suppressed,omitempty"`
+	ExpiredSuppressions []Suppression `json:"expiredSuppressions,omitempty"`
 }
 
 func NewAnalysis(
@@ -151,6 +161,7 @@ func NewAnalysis(
 	}
 	a.AIClient = aiClient
 	a.AnalysisAIProvider = aiProvider.Name
+	a.Usage = ai.NewTokenCounter(&aiProvider)
 	return a, nil
 }
 
@@ -179,6 +190,12 @@ func (a *Analysis) RunAnalysis() {
 	activeFilters := viper.GetStringSlice("active_filters")
 
 	coreAnalyzerMap, analyzerMap := analyzer.GetAnalyzerMap()
+	// attach the remediation hints and hide suppressed problems once all
+	// analyzers are done
+	defer func() {
+		common.AddRemediations(a.Results)
+		a.applySuppressions()
+	}()
 
 	// we get the openapi schema from the server only if required by the flag "with-doc"
 	openapiSchema := &openapi_v2.Document{}
@@ -291,7 +308,20 @@ func (a *Analysis) GetAIResults(output string, anonymize bool) error {
 		bar = progressbar.Default(int64(len(a.Results)))
 	}
 
+	if a.Usage == nil {
+		a.Usage = ai.NewTokenCounter(&ai.AIProvider{Name: a.AIClient.GetName()})
+	}
+
 	for index, analysis := range a.Results {
+		// Stop explaining once the token or cost budget is used up.
+		if a.Usage.Exceeded() {
+			a.Usage.Skip()
+			if output != "json" {
+				_ = bar.Add(1)
+			}
+			continue
+		}
+
 		var texts []string
 
 		for _, failure := range analysis.Error {
@@ -309,6 +339,9 @@ func (a *Analysis) GetAIResults(output string, anonymize bool) error {
 		if prompt, ok := ai.PromptMap[analysis.Kind]; ok {
 			promptTemplate = prompt
 		}
+		if a.Structured {
+			promptTemplate = ai.PromptMap["structured"]
+		}
 		result, err := a.getAIResultForSanitizedFailures(texts, promptTemplate)
 		if err != nil {
 			// FIXME: can we avoid checking if output is json multiple times?
@@ -332,12 +365,27 @@ func (a *Analysis) GetAIResults(output string, anonymize bool) error {
 			}
 		}
 
+		if a.Structured {
+			structured, err := common.ParseStructuredResponse(result)
+			if err != nil {
+				// The backend could not comply, keep its answer as plain text.
+				a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s: %s; falling back to text", a.AIClient.GetName(), analysis.Name, err))
+			} else {
+				analysis.Structured = structured
+				result = structured.String()
+			}
+		}
+
 		analysis.Details = result
 		if output != "json" {
 			_ = bar.Add(1)
 		}
 		a.Results[index] = analysis
 	}
+
+	if summary := a.Usage.Summary(); summary.BudgetExceeded {
+		a.Errors = append(a.Errors, fmt.Sprintf("AI budget exceeded after %d tokens ($%.4f); %d results were not explained", summary.TotalTokens, summary.EstimatedCost, summary.Skipped))
+	}
 	return nil
 }
 
@@ -346,6 +394,9 @@ func (a *Analysis) getAIResultForSanitizedFailures(texts []string, promptTmpl st
 	// Check for cached data.
 	// TODO(bwplotka): This might depend on model too (or even other client configuration pieces), fix it in later PRs.
 	cacheKey := util.GetCacheKey(a.AIClient.GetName(), a.Language, inputKey)
+	if a.Structured {
+		cacheKey = util.GetCacheKey(a.AIClient.GetName()+"-structured", a.Language, inputKey)
+	}
 
 	if !a.Cache.IsCacheDisabled() && a.Cache.Exists(cacheKey) {
 		response, err := a.Cache.Load(cacheKey)
@@ -364,10 +415,19 @@ func (a *Analysis) getAIResultForSanitizedFailures(texts []string, promptTmpl st
 
 	// Process template.
 	prompt := fmt.Sprintf(strings.TrimSpace(promptTmpl), a.Language, inputKey)
-	response, err := a.AIClient.GetCompletion(a.Context, prompt)
+	var response string
+	var err error
+	if a.Structured {
+		response, err = ai.GetStructuredCompletion(a.Context, a.AIClient, prompt)
+	} else {
+		response, err = a.AIClient.GetCompletion(a.Context, prompt)
+	}
 	if err != nil {
 		return "", err
 	}
+	if a.Usage != nil {
+		a.Usage.Record(a.AIClient, prompt, response)
+	}
 
 	if err = a.Cache.Store(cacheKey, base64.StdEncoding.EncodeToString([]byte(response))); err != nil {
 		color.Red("error storing value to cache; value won't be cached: %v", err)
@@ -354,6 +354,62 @@ func TestNewAnalysis(t *testing.T) {
 	}
 }
 
+func TestGetAIResults_StructuredFallback(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		AIClient:   &ai.NoOpAIClient{},
+		Cache:      disabledCache,
+		Language:   "english",
+		Structured: true,
+		Results: []common.Result{
+			{
+				Kind:  "Pod",
+				Name:  "default/example",
+				Error: []common.Failure{{Text: "back-off restarting failed container"}},
+			},
+		},
+	}
+
+	require.NoError(t, a.GetAIResults("json", false))
+	// The noop backend cannot produce valid JSON, so the text answer is kept.
+	require.Nil(t, a.Results[0].Structured)
+	require.Contains(t, a.Results[0].Details, "I am a noop response")
+	require.Len(t, a.Errors, 1)
+	require.Contains(t, a.Errors[0], "falling back to text")
+}
+
+func TestGetAIResults_Budget(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	usage := ai.NewTokenCounter(&ai.AIProvider{Name: "noopai"})
+	usage.MaxTotalTokens = 1
+	a := Analysis{
+		AIClient: &ai.NoOpAIClient{},
+		Cache:    disabledCache,
+		Explain:  true,
+		Usage:    usage,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/one", Error: []common.Failure{{Text: "first"}}},
+			{Kind: "Pod", Name: "default/two", Error: []common.Failure{{Text: "second"}}},
+		},
+	}
+
+	require.NoError(t, a.GetAIResults("json", false))
+	require.NotEmpty(t, a.Results[0].Details)
+	require.Empty(t, a.Results[1].Details)
+	require.Len(t, a.Errors, 1)
+	require.Contains(t, a.Errors[0], "1 results were not explained")
+
+	out, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	var got JsonOutput
+	require.NoError(t, json.Unmarshal(out, &got))
+	require.NotNil(t, got.Usage)
+	require.Equal(t, 1, got.Usage.Requests)
+	require.True(t, got.Usage.BudgetExceeded)
+}
+
 func TestGetAIResultForSanitizedFailures(t *testing.T) {
 	enabledCache := cache.New("enabled-cache")
 	disabledCache := cache.New("disabled-cache")
@@ -0,0 +1,261 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// patchTypes maps the patch types a model may choose to their content types.
+var patchTypes = map[string]types.PatchType{
+	"strategic": types.StrategicMergePatchType,
+	"merge":     types.MergePatchType,
+	"json":      types.JSONPatchType,
+}
+
+// PatchSuggestion is the patch a model proposes for the object of a result.
+type PatchSuggestion struct {
+	PatchType   string          `json:"patch_type"`
+	Patch       json.RawMessage `json:"patch"`
+	Explanation string          `json:"explanation"`
+}
+
+// ParsePatchSuggestion decodes and validates a model answer. Markdown code
+// fences and prose around the JSON object are tolerated.
+func ParsePatchSuggestion(raw string) (*PatchSuggestion, error) {
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start == -1 || end < start {
+		return nil, errors.New("no JSON object found in response")
+	}
+
+	var suggestion PatchSuggestion
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &suggestion); err != nil {
+		return nil, fmt.Errorf("decoding patch suggestion: %w", err)
+	}
+	if _, ok := patchTypes[suggestion.PatchType]; !ok {
+		return nil, fmt.Errorf("unknown patch_type %q", suggestion.PatchType)
+	}
+	var patch interface{}
+	if err := json.Unmarshal(suggestion.Patch, &patch); err != nil {
+		return nil, errors.New("patch is missing")
+	}
+	switch patch.(type) {
+	case []interface{}:
+		if suggestion.PatchType != "json" {
+			return nil, fmt.Errorf("a %s patch must be an object", suggestion.PatchType)
+		}
+	case map[string]interface{}:
+		if suggestion.PatchType == "json" {
+			return nil, errors.New("a json patch must be a list of operations")
+		}
+	default:
+		return nil, errors.New("patch must be an object or a list of operations")
+	}
+	return &suggestion, nil
+}
+
+// Fix is a patch for the object of a result that passed a server-side dry-run.
+type Fix struct {
+	Result      common.Result
+	Object      *unstructured.Unstructured
+	Patched     *unstructured.Unstructured
+	PatchType   types.PatchType
+	Patch       []byte
+	Explanation string
+}
+
+// Diff shows the change the fix makes to the live object.
+func (f *Fix) Diff() (string, error) {
+	return kubernetes.DiffObjects(f.Object, f.Patched, ResultRef(f.Result).String())
+}
+
+// SuggestFix asks the AI backend for a patch that fixes the problems of a
+// result and validates it with a server-side dry-run. Nothing is changed.
+// Secrets are never sent to the AI backend and the values of ConfigMaps are
+// left out. With anonymize set, the names the failures mark as sensitive are
+// masked.
+func (a *Analysis) SuggestFix(result common.Result, anonymize bool) (*Fix, error) {
+	ref := ResultRef(result)
+	if ref.Kind == "Secret" {
+		return nil, errors.New("secrets are not sent to the AI backend")
+	}
+	obj, err := a.Client.GetObject(a.Context, ref.Kind, ref.Namespace, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(fixPromptObject(obj).Object)
+	if err != nil {
+		return nil, err
+	}
+	var failures strings.Builder
+	for _, failure := range result.Error {
+		failures.WriteString(fmt.Sprintf("- %s\n", failure.Text))
+	}
+
+	promptTemplate := ai.PromptMap["fix_patch"]
+	prompt := fmt.Sprintf(strings.TrimSpace(promptTemplate), ref.Kind, ref.resultName(), strings.TrimSpace(failures.String()), string(data))
+	if anonymize {
+		prompt = maskSensitive(prompt, result.Error)
+	}
+	response, err := ai.GetStructuredCompletion(a.Context, a.AIClient, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if anonymize {
+		response = unmaskSensitive(response, result.Error)
+	}
+	suggestion, err := ParsePatchSuggestion(response)
+	if err != nil {
+		return nil, err
+	}
+
+	fix := &Fix{
+		Result:      result,
+		Object:      obj,
+		PatchType:   patchTypes[suggestion.PatchType],
+		Patch:       suggestion.Patch,
+		Explanation: suggestion.Explanation,
+	}
+	fix.Patched, err = a.Client.PatchObject(a.Context, obj, fix.PatchType, fix.Patch, true)
+	if err != nil {
+		return nil, fmt.Errorf("dry-run of the suggested patch failed: %w", err)
+	}
+	return fix, nil
+}
+
+// ApplyFix patches the live object. The patch only applies to the version of
+// the object it was dry-run against, it fails if the object changed since.
+func (a *Analysis) ApplyFix(fix *Fix) error {
+	patch, err := pinResourceVersion(fix.PatchType, fix.Patch, fix.Object.GetResourceVersion())
+	if err != nil {
+		return err
+	}
+	_, err = a.Client.PatchObject(a.Context, fix.Object, fix.PatchType, patch, false)
+	return err
+}
+
+// fixPromptObject returns the object as it is shown to the AI backend, without
+// its managed fields and the values of ConfigMaps.
+func fixPromptObject(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	object := obj.DeepCopy()
+	unstructured.RemoveNestedField(object.Object, "metadata", "managedFields")
+	if object.GetKind() == "ConfigMap" {
+		for _, field := range []string{"data", "binaryData"} {
+			values, _, _ := unstructured.NestedMap(object.Object, field)
+			for key := range values {
+				values[key] = "<redacted>"
+			}
+			if len(values) > 0 {
+				_ = unstructured.SetNestedMap(object.Object, values, field)
+			}
+		}
+	}
+	return object
+}
+
+// maskSensitive replaces the names the failures mark as sensitive with their
+// masked form, as GetAIResults does.
+func maskSensitive(text string, failures []common.Failure) string {
+	for _, failure := range failures {
+		for _, s := range failure.Sensitive {
+			text = util.ReplaceIfMatch(text, s.Unmasked, s.Masked)
+		}
+	}
+	return text
+}
+
+// unmaskSensitive restores the names masked by maskSensitive.
+func unmaskSensitive(text string, failures []common.Failure) string {
+	for _, failure := range failures {
+		for _, s := range failure.Sensitive {
+			text = strings.ReplaceAll(text, s.Masked, s.Unmasked)
+		}
+	}
+	return text
+}
+
+// pinResourceVersion makes a patch fail with a conflict unless the object
+// still has the given resourceVersion.
+func pinResourceVersion(patchType types.PatchType, patch []byte, resourceVersion string) ([]byte, error) {
+	if patchType == types.JSONPatchType {
+		var operations []interface{}
+		if err := json.Unmarshal(patch, &operations); err != nil {
+			return nil, err
+		}
+		test := map[string]interface{}{"op": "test", "path": "/metadata/resourceVersion", "value": resourceVersion}
+		return json.Marshal(append([]interface{}{test}, operations...))
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(patch, &object); err != nil {
+		return nil, err
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		object["metadata"] = metadata
+	}
+	metadata["resourceVersion"] = resourceVersion
+	return json.Marshal(object)
+}
+
+// FixLogEntry records a patch applied by k8sgpt fix.
+type FixLogEntry struct {
+	Time        time.Time       `json:"time"`
+	Kind        string          `json:"kind"`
+	Name        string          `json:"name"`
+	Backend     string          `json:"backend"`
+	PatchType   types.PatchType `json:"patch_type"`
+	Patch       json.RawMessage `json:"patch"`
+	Explanation string          `json:"explanation"`
+}
+
+// LogFix appends an entry for an applied fix to the JSON lines file at path.
+func (a *Analysis) LogFix(path string, fix *Fix) error {
+	line, err := json.Marshal(FixLogEntry{
+		Time:        time.Now().UTC(),
+		Kind:        fix.Result.Kind,
+		Name:        fix.Result.Name,
+		Backend:     a.AnalysisAIProvider,
+		PatchType:   fix.PatchType,
+		Patch:       fix.Patch,
+		Explanation: fix.Explanation,
+	})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
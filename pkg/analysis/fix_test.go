@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParsePatchSuggestion(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		patchType   string
+		expectedErr string
+	}{
+		{
+			name:      "strategic merge patch in a code fence",
+			raw:       "```json\n{\"patch_type\": \"strategic\", \"patch\": {\"spec\": {\"ingressClassName\": \"nginx\"}}, \"explanation\": \"Set the ingress class\"}\n```",
+			patchType: "strategic",
+		},
+		{
+			name:      "json patch",
+			raw:       `{"patch_type": "json", "patch": [{"op": "replace", "path": "/spec/schedule", "value": "*/5 * * * *"}]}`,
+			patchType: "json",
+		},
+		{
+			name:        "no json",
+			raw:         "I cannot fix this",
+			expectedErr: "no JSON object found",
+		},
+		{
+			name:        "unknown patch type",
+			raw:         `{"patch_type": "apply", "patch": {}}`,
+			expectedErr: `unknown patch_type "apply"`,
+		},
+		{
+			name:        "missing patch",
+			raw:         `{"patch_type": "merge"}`,
+			expectedErr: "patch is missing",
+		},
+		{
+			name:        "json patch as object",
+			raw:         `{"patch_type": "json", "patch": {"spec": {}}}`,
+			expectedErr: "must be a list of operations",
+		},
+		{
+			name:        "merge patch as list",
+			raw:         `{"patch_type": "merge", "patch": [{"op": "remove", "path": "/spec"}]}`,
+			expectedErr: "a merge patch must be an object",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			suggestion, err := ParsePatchSuggestion(tt.raw)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.patchType, suggestion.PatchType)
+		})
+	}
+}
+
+func TestResultRef(t *testing.T) {
+	require.Equal(t, ObjectRef{Kind: "Pod", Namespace: "shop", Name: "web"}, ResultRef(common.Result{Kind: "Pod", Name: "shop/web"}))
+	require.Equal(t, ObjectRef{Kind: "Node", Name: "node-1"}, ResultRef(common.Result{Kind: "Node", Name: "node-1"}))
+}
+
+func TestLogFix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixes.log")
+	a := &Analysis{AnalysisAIProvider: "openai"}
+	fix := &Fix{
+		Result:      common.Result{Kind: "Ingress", Name: "shop/web"},
+		PatchType:   types.StrategicMergePatchType,
+		Patch:       []byte(`{"spec":{"ingressClassName":"nginx"}}`),
+		Explanation: "Set the ingress class",
+	}
+	require.NoError(t, a.LogFix(path, fix))
+	require.NoError(t, a.LogFix(path, fix))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry FixLogEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	require.Equal(t, "Ingress", entry.Kind)
+	require.Equal(t, "shop/web", entry.Name)
+	require.Equal(t, "openai", entry.Backend)
+	require.Equal(t, types.StrategicMergePatchType, entry.PatchType)
+	require.JSONEq(t, `{"spec":{"ingressClassName":"nginx"}}`, string(entry.Patch))
+	require.False(t, entry.Time.IsZero())
+}
+
+func TestSuggestFixRefusesSecrets(t *testing.T) {
+	a := &Analysis{}
+	_, err := a.SuggestFix(common.Result{Kind: "Secret", Name: "shop/web-tls"}, false)
+	require.ErrorContains(t, err, "secrets are not sent to the AI backend")
+}
+
+func TestFixPromptObject(t *testing.T) {
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":          "settings",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"data": map[string]interface{}{"password": "hunter2"},
+	}}
+	object := fixPromptObject(configMap)
+	require.Equal(t, map[string]interface{}{"password": "<redacted>"}, object.Object["data"])
+	_, found, _ := unstructured.NestedFieldNoCopy(object.Object, "metadata", "managedFields")
+	require.False(t, found)
+	// the live object is left untouched
+	require.Equal(t, "hunter2", configMap.Object["data"].(map[string]interface{})["password"])
+}
+
+func TestMaskSensitive(t *testing.T) {
+	failures := []common.Failure{{
+		Text:      "Ingress shop/web references the missing secret web-tls",
+		Sensitive: []common.Sensitive{{Unmasked: "web-tls", Masked: "d2ViLXRscw=="}},
+	}}
+	masked := maskSensitive("secretName: web-tls", failures)
+	require.Equal(t, "secretName: d2ViLXRscw==", masked)
+	require.Equal(t, "secretName: web-tls", unmaskSensitive(masked, failures))
+}
+
+func TestPinResourceVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		patchType types.PatchType
+		patch     string
+		expected  string
+	}{
+		{
+			name:      "strategic merge patch",
+			patchType: types.StrategicMergePatchType,
+			patch:     `{"spec":{"ingressClassName":"nginx"}}`,
+			expected:  `{"metadata":{"resourceVersion":"42"},"spec":{"ingressClassName":"nginx"}}`,
+		},
+		{
+			name:      "merge patch with metadata",
+			patchType: types.MergePatchType,
+			patch:     `{"metadata":{"labels":{"app":"web"}}}`,
+			expected:  `{"metadata":{"labels":{"app":"web"},"resourceVersion":"42"}}`,
+		},
+		{
+			name:      "json patch",
+			patchType: types.JSONPatchType,
+			patch:     `[{"op":"replace","path":"/spec/schedule","value":"*/5 * * * *"}]`,
+			expected:  `[{"op":"test","path":"/metadata/resourceVersion","value":"42"},{"op":"replace","path":"/spec/schedule","value":"*/5 * * * *"}]`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := pinResourceVersion(tt.patchType, []byte(tt.patch), "42")
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(patch))
+		})
+	}
+}
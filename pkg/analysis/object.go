@@ -0,0 +1,428 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// kindAliases maps the names accepted on the command line to the keys the
+// analyzers are registered with, following the kubectl short names.
+var kindAliases = map[string]string{
+	"pod":                     "Pod",
+	"po":                      "Pod",
+	"deployment":              "Deployment",
+	"deploy":                  "Deployment",
+	"replicaset":              "ReplicaSet",
+	"rs":                      "ReplicaSet",
+	"statefulset":             "StatefulSet",
+	"sts":                     "StatefulSet",
+	"daemonset":               "DaemonSet",
+	"ds":                      "DaemonSet",
+	"job":                     "Job",
+	"cronjob":                 "CronJob",
+	"cj":                      "CronJob",
+	"service":                 "Service",
+	"svc":                     "Service",
+	"persistentvolumeclaim":   "PersistentVolumeClaim",
+	"pvc":                     "PersistentVolumeClaim",
+	"ingress":                 "Ingress",
+	"ing":                     "Ingress",
+	"node":                    "Node",
+	"no":                      "Node",
+	"horizontalpodautoscaler": "HorizontalPodAutoScaler",
+	"hpa":                     "HorizontalPodAutoScaler",
+	"poddisruptionbudget":     "PodDisruptionBudget",
+	"pdb":                     "PodDisruptionBudget",
+	"networkpolicy":           "NetworkPolicy",
+	"netpol":                  "NetworkPolicy",
+}
+
+// clusterScopedKinds are the kinds above that do not live in a namespace.
+var clusterScopedKinds = map[string]bool{
+	"Node": true,
+}
+
+// ObjectRef identifies a single Kubernetes object.
+type ObjectRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// ParseObjectRef parses a kind/name argument such as deploy/web or pods/web.
+func ParseObjectRef(arg string, namespace string) (ObjectRef, error) {
+	kind, name, ok := strings.Cut(arg, "/")
+	if !ok || kind == "" || name == "" {
+		return ObjectRef{}, fmt.Errorf("%q is not of the form <kind>/<name>", arg)
+	}
+	lower := strings.ToLower(kind)
+	var resolved string
+	// Accept plurals like kubectl does: pods, ingresses, networkpolicies.
+	for _, candidate := range []string{lower, strings.TrimSuffix(lower, "s"), strings.TrimSuffix(lower, "es"), strings.TrimSuffix(lower, "ies") + "y"} {
+		if resolved = kindAliases[candidate]; resolved != "" {
+			break
+		}
+	}
+	if resolved == "" {
+		return ObjectRef{}, fmt.Errorf("unsupported kind %q", kind)
+	}
+	ref := ObjectRef{Kind: resolved, Namespace: namespace, Name: name}
+	if clusterScopedKinds[resolved] {
+		ref.Namespace = ""
+	}
+	return ref, nil
+}
+
+func (r ObjectRef) String() string {
+	return fmt.Sprintf("%s/%s", r.Kind, r.resultName())
+}
+
+// ResultRef returns the object a result is about.
+func ResultRef(result common.Result) ObjectRef {
+	if namespace, name, ok := strings.Cut(result.Name, "/"); ok {
+		return ObjectRef{Kind: result.Kind, Namespace: namespace, Name: name}
+	}
+	return ObjectRef{Kind: result.Kind, Name: result.Name}
+}
+
+// resultName is the name analyzers report for the object.
+func (r ObjectRef) resultName() string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s/%s", r.Namespace, r.Name)
+}
+
+// objectSet keeps the related objects in the order they were found.
+type objectSet struct {
+	refs []ObjectRef
+	seen map[ObjectRef]bool
+}
+
+func (s *objectSet) add(refs ...ObjectRef) {
+	if s.seen == nil {
+		s.seen = map[ObjectRef]bool{}
+	}
+	for _, ref := range refs {
+		if !s.seen[ref] {
+			s.seen[ref] = true
+			s.refs = append(s.refs, ref)
+		}
+	}
+}
+
+// RunObjectAnalysis runs only the analyzers relevant to ref, its owners and
+// its dependents, and keeps the results about these objects. It returns the
+// objects that were looked at.
+func (a *Analysis) RunObjectAnalysis(ref ObjectRef) ([]ObjectRef, error) {
+	related, err := a.RelatedObjects(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	seenKinds := map[string]bool{}
+	var kinds []string
+	for _, r := range related {
+		if !seenKinds[r.Kind] {
+			seenKinds[r.Kind] = true
+			kinds = append(kinds, r.Kind)
+		}
+		// analyzer keys do not always match the kind of their results, e.g.
+		// HorizontalPodAutoScaler
+		wanted[strings.ToLower(r.Kind)+"/"+r.resultName()] = true
+	}
+
+	_, analyzerMap := analyzer.GetAnalyzerMap()
+	analyzerConfig := common.Analyzer{
+		Client:    a.Client,
+		Context:   a.Context,
+		Namespace: ref.Namespace,
+		AIClient:  a.AIClient,
+	}
+	for _, kind := range kinds {
+		kindAnalyzer, ok := analyzerMap[kind]
+		if !ok {
+			continue
+		}
+		results, err := kindAnalyzer.Analyze(analyzerConfig)
+		if err != nil {
+			a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s", kind, err))
+		}
+		common.AddRemediations(results)
+		for _, result := range results {
+			if wanted[strings.ToLower(result.Kind)+"/"+result.Name] {
+				a.Results = append(a.Results, result)
+			}
+		}
+	}
+	a.applySuppressions()
+	return related, nil
+}
+
+// RelatedObjects returns ref followed by its owners and its dependents, such as
+// the ReplicaSet and Deployment of a pod, the pods behind a Service or the
+// PersistentVolumeClaims mounted by a pod.
+func (a *Analysis) RelatedObjects(ref ObjectRef) ([]ObjectRef, error) {
+	client := a.Client.GetClient()
+	ns := ref.Namespace
+	related := &objectSet{}
+	related.add(ref)
+
+	switch ref.Kind {
+	case "Pod":
+		pod, err := client.CoreV1().Pods(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		a.addOwners(related, ns, pod.OwnerReferences)
+		related.add(podClaims(ns, pod.Spec.Volumes)...)
+		if err := a.addServicesSelecting(related, ns, pod.Labels); err != nil {
+			return nil, err
+		}
+	case "Deployment":
+		deployment, err := client.AppsV1().Deployments(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		rsList, err := client.AppsV1().ReplicaSets(ns).List(a.Context, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var replicaSets []metav1.ObjectMeta
+		for _, rs := range rsList.Items {
+			if isOwnedBy(rs.ObjectMeta, deployment.UID) {
+				related.add(ObjectRef{Kind: "ReplicaSet", Namespace: ns, Name: rs.Name})
+				replicaSets = append(replicaSets, rs.ObjectMeta)
+			}
+		}
+		if err := a.addOwnedPods(related, ns, replicaSets...); err != nil {
+			return nil, err
+		}
+		related.add(podClaims(ns, deployment.Spec.Template.Spec.Volumes)...)
+		if err := a.addServicesSelecting(related, ns, deployment.Spec.Template.Labels); err != nil {
+			return nil, err
+		}
+	case "ReplicaSet":
+		rs, err := client.AppsV1().ReplicaSets(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		a.addOwners(related, ns, rs.OwnerReferences)
+		if err := a.addOwnedPods(related, ns, rs.ObjectMeta); err != nil {
+			return nil, err
+		}
+	case "StatefulSet":
+		sts, err := client.AppsV1().StatefulSets(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if sts.Spec.ServiceName != "" {
+			related.add(ObjectRef{Kind: "Service", Namespace: ns, Name: sts.Spec.ServiceName})
+		}
+		if err := a.addOwnedPods(related, ns, sts.ObjectMeta); err != nil {
+			return nil, err
+		}
+	case "DaemonSet":
+		ds, err := client.AppsV1().DaemonSets(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if err := a.addOwnedPods(related, ns, ds.ObjectMeta); err != nil {
+			return nil, err
+		}
+	case "CronJob":
+		cronJob, err := client.BatchV1().CronJobs(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		jobList, err := client.BatchV1().Jobs(ns).List(a.Context, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var jobs []metav1.ObjectMeta
+		for _, job := range jobList.Items {
+			if isOwnedBy(job.ObjectMeta, cronJob.UID) {
+				related.add(ObjectRef{Kind: "Job", Namespace: ns, Name: job.Name})
+				jobs = append(jobs, job.ObjectMeta)
+			}
+		}
+		if err := a.addOwnedPods(related, ns, jobs...); err != nil {
+			return nil, err
+		}
+	case "Job":
+		job, err := client.BatchV1().Jobs(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		a.addOwners(related, ns, job.OwnerReferences)
+		if err := a.addOwnedPods(related, ns, job.ObjectMeta); err != nil {
+			return nil, err
+		}
+	case "Service":
+		svc, err := client.CoreV1().Services(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if len(svc.Spec.Selector) > 0 {
+			pods, err := client.CoreV1().Pods(ns).List(a.Context, metav1.ListOptions{
+				LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, pod := range pods.Items {
+				related.add(ObjectRef{Kind: "Pod", Namespace: ns, Name: pod.Name})
+			}
+		}
+		ingresses, err := client.NetworkingV1().Ingresses(ns).List(a.Context, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, ing := range ingresses.Items {
+			for _, backend := range ingressBackends(ing) {
+				if backend == svc.Name {
+					related.add(ObjectRef{Kind: "Ingress", Namespace: ns, Name: ing.Name})
+				}
+			}
+		}
+	case "PersistentVolumeClaim":
+		if _, err := client.CoreV1().PersistentVolumeClaims(ns).Get(a.Context, ref.Name, metav1.GetOptions{}); err != nil {
+			return nil, err
+		}
+		pods, err := client.CoreV1().Pods(ns).List(a.Context, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods.Items {
+			for _, claim := range podClaims(ns, pod.Spec.Volumes) {
+				if claim.Name == ref.Name {
+					related.add(ObjectRef{Kind: "Pod", Namespace: ns, Name: pod.Name})
+				}
+			}
+		}
+	case "Ingress":
+		ing, err := client.NetworkingV1().Ingresses(ns).Get(a.Context, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, backend := range ingressBackends(*ing) {
+			related.add(ObjectRef{Kind: "Service", Namespace: ns, Name: backend})
+		}
+	}
+	return related.refs, nil
+}
+
+// addOwners adds the owners of an object and, for workloads, their owners in turn.
+func (a *Analysis) addOwners(related *objectSet, ns string, owners []metav1.OwnerReference) {
+	client := a.Client.GetClient()
+	for _, owner := range owners {
+		related.add(ObjectRef{Kind: owner.Kind, Namespace: ns, Name: owner.Name})
+		switch owner.Kind {
+		case "ReplicaSet":
+			if rs, err := client.AppsV1().ReplicaSets(ns).Get(a.Context, owner.Name, metav1.GetOptions{}); err == nil {
+				a.addOwners(related, ns, rs.OwnerReferences)
+			}
+		case "Job":
+			if job, err := client.BatchV1().Jobs(ns).Get(a.Context, owner.Name, metav1.GetOptions{}); err == nil {
+				a.addOwners(related, ns, job.OwnerReferences)
+			}
+		}
+	}
+}
+
+// addOwnedPods adds the pods owned by any of the owners and the claims they mount.
+func (a *Analysis) addOwnedPods(related *objectSet, ns string, owners ...metav1.ObjectMeta) error {
+	if len(owners) == 0 {
+		return nil
+	}
+	pods, err := a.Client.GetClient().CoreV1().Pods(ns).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		for _, owner := range owners {
+			if isOwnedBy(pod.ObjectMeta, owner.UID) {
+				related.add(ObjectRef{Kind: "Pod", Namespace: ns, Name: pod.Name})
+				related.add(podClaims(ns, pod.Spec.Volumes)...)
+			}
+		}
+	}
+	return nil
+}
+
+// addServicesSelecting adds the services whose selector matches podLabels.
+func (a *Analysis) addServicesSelecting(related *objectSet, ns string, podLabels map[string]string) error {
+	if len(podLabels) == 0 {
+		return nil
+	}
+	services, err := a.Client.GetClient().CoreV1().Services(ns).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, svc := range services.Items {
+		if len(svc.Spec.Selector) > 0 && labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(podLabels)) {
+			related.add(ObjectRef{Kind: "Service", Namespace: ns, Name: svc.Name})
+		}
+	}
+	return nil
+}
+
+// ingressBackends returns the names of the services an ingress routes to.
+func ingressBackends(ing networkingv1.Ingress) []string {
+	var backends []string
+	if ing.Spec.DefaultBackend != nil && ing.Spec.DefaultBackend.Service != nil {
+		backends = append(backends, ing.Spec.DefaultBackend.Service.Name)
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				backends = append(backends, path.Backend.Service.Name)
+			}
+		}
+	}
+	return backends
+}
+
+func podClaims(ns string, volumes []v1.Volume) []ObjectRef {
+	var claims []ObjectRef
+	for _, volume := range volumes {
+		if volume.PersistentVolumeClaim != nil {
+			claims = append(claims, ObjectRef{Kind: "PersistentVolumeClaim", Namespace: ns, Name: volume.PersistentVolumeClaim.ClaimName})
+		}
+	}
+	return claims
+}
+
+func isOwnedBy(meta metav1.ObjectMeta, uid types.UID) bool {
+	for _, owner := range meta.OwnerReferences {
+		if owner.UID == uid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newObjectTestAnalysis() *Analysis {
+	replicas := int32(1)
+	pendingStatus := v1.PodStatus{
+		Phase: v1.PodPending,
+		Conditions: []v1.PodCondition{
+			{
+				Type:    v1.PodScheduled,
+				Reason:  "Unschedulable",
+				Message: "0/1 nodes are available",
+			},
+		},
+	}
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "deploy-uid"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				},
+			},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "web-1", Namespace: "shop", UID: "rs-uid",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "deploy-uid"}},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "web-1-abc", Namespace: "shop", Labels: map[string]string{"app": "web"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-1", UID: "rs-uid"}},
+			},
+			Spec: v1.PodSpec{
+				Volumes: []v1.Volume{{
+					Name:         "data",
+					VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "web-data"}},
+				}},
+			},
+			Status: pendingStatus,
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "shop"},
+			Status:     pendingStatus,
+		},
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-data", Namespace: "shop"},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+		},
+		&autoscalingv1.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "web-old"},
+			},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{
+				DefaultBackend: &networkingv1.IngressBackend{
+					Service: &networkingv1.IngressServiceBackend{Name: "web"},
+				},
+			},
+		},
+	)
+	return &Analysis{
+		Context: context.Background(),
+		Client:  &kubernetes.Client{Client: clientset},
+	}
+}
+
+func TestParseObjectRef(t *testing.T) {
+	tests := []struct {
+		arg         string
+		expected    ObjectRef
+		expectedErr string
+	}{
+		{arg: "pod/web", expected: ObjectRef{Kind: "Pod", Namespace: "shop", Name: "web"}},
+		{arg: "Deployment/web", expected: ObjectRef{Kind: "Deployment", Namespace: "shop", Name: "web"}},
+		{arg: "ingresses/web", expected: ObjectRef{Kind: "Ingress", Namespace: "shop", Name: "web"}},
+		{arg: "networkpolicies/web", expected: ObjectRef{Kind: "NetworkPolicy", Namespace: "shop", Name: "web"}},
+		{arg: "no/worker-1", expected: ObjectRef{Kind: "Node", Name: "worker-1"}},
+		{arg: "web", expectedErr: "is not of the form <kind>/<name>"},
+		{arg: "secret/token", expectedErr: "unsupported kind"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.arg, func(t *testing.T) {
+			ref, err := ParseObjectRef(tt.arg, "shop")
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, ref)
+		})
+	}
+}
+
+func TestKindAliasesHaveAnalyzers(t *testing.T) {
+	_, analyzerMap := analyzer.GetAnalyzerMap()
+	for alias, kind := range kindAliases {
+		require.Contains(t, analyzerMap, kind, "alias %s", alias)
+	}
+}
+
+func TestRelatedObjects(t *testing.T) {
+	pod := ObjectRef{Kind: "Pod", Namespace: "shop", Name: "web-1-abc"}
+	rs := ObjectRef{Kind: "ReplicaSet", Namespace: "shop", Name: "web-1"}
+	deployment := ObjectRef{Kind: "Deployment", Namespace: "shop", Name: "web"}
+	pvc := ObjectRef{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "web-data"}
+	svc := ObjectRef{Kind: "Service", Namespace: "shop", Name: "web"}
+	ing := ObjectRef{Kind: "Ingress", Namespace: "shop", Name: "web"}
+
+	tests := []struct {
+		ref      ObjectRef
+		expected []ObjectRef
+	}{
+		{ref: pod, expected: []ObjectRef{pod, rs, deployment, pvc, svc}},
+		{ref: deployment, expected: []ObjectRef{deployment, rs, pod, pvc, svc}},
+		{ref: svc, expected: []ObjectRef{svc, pod, ing}},
+		{ref: pvc, expected: []ObjectRef{pvc, pod}},
+		{ref: ing, expected: []ObjectRef{ing, svc}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.ref.String(), func(t *testing.T) {
+			related, err := newObjectTestAnalysis().RelatedObjects(tt.ref)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, related)
+		})
+	}
+
+	_, err := newObjectTestAnalysis().RelatedObjects(ObjectRef{Kind: "Pod", Namespace: "shop", Name: "missing"})
+	require.Error(t, err)
+}
+
+func TestRunObjectAnalysis(t *testing.T) {
+	a := newObjectTestAnalysis()
+	_, err := a.RunObjectAnalysis(ObjectRef{Kind: "Pod", Namespace: "shop", Name: "web-1-abc"})
+	require.NoError(t, err)
+	for _, result := range a.Results {
+		// The other pending pod of the namespace is not part of the result.
+		require.NotEqual(t, "shop/other", result.Name)
+	}
+	require.Contains(t, a.Results[0].Name, "shop/web-1-abc")
+}
+
+func TestRunObjectAnalysisAnalyzerKey(t *testing.T) {
+	a := newObjectTestAnalysis()
+	ref, err := ParseObjectRef("hpa/web", "shop")
+	require.NoError(t, err)
+	_, err = a.RunObjectAnalysis(ref)
+	require.NoError(t, err)
+	require.Len(t, a.Results, 1)
+	require.Equal(t, "HorizontalPodAutoscaler", a.Results[0].Kind)
+	require.Equal(t, "shop/web", a.Results[0].Name)
+}
+
+func TestRunObjectAnalysisSuppressions(t *testing.T) {
+	a := newObjectTestAnalysis()
+	a.Suppressions = []Suppression{{Analyzer: "Pod", Namespace: "shop", Name: "web-*", Reason: "capacity is being added", Owner: "platform-team"}}
+	_, err := a.RunObjectAnalysis(ObjectRef{Kind: "Pod", Namespace: "shop", Name: "web-1-abc"})
+	require.NoError(t, err)
+	for _, result := range a.Results {
+		require.NotEqual(t, "Pod", result.Kind)
+	}
+	require.Positive(t, a.Suppressed)
+}
@@ -47,6 +47,13 @@ func (a *Analysis) jsonOutput() ([]byte, error) {
 		Results:  a.Results,
 		Errors:   a.Errors,
 		Status:   status,
+
+		Suppressed:          a.Suppressed,
+		ExpiredSuppressions: a.ExpiredSuppressions,
+	}
+	if a.Explain && a.Usage != nil {
+		usage := a.Usage.Summary()
+		result.Usage = &usage
 	}
 	output, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
@@ -72,7 +79,17 @@ func (a *Analysis) textOutput() ([]byte, error) {
 			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(aerror)))
 		}
 	}
+	if len(a.ExpiredSuppressions) != 0 {
+		output.WriteString("\n")
+		output.WriteString(color.YellowString("Expired suppressions : \n"))
+		for _, s := range a.ExpiredSuppressions {
+			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(s.String())))
+		}
+	}
 	output.WriteString("\n")
+	if a.Suppressed > 0 {
+		output.WriteString(fmt.Sprintf("%s\n\n", color.HiBlackString("%d problems suppressed", a.Suppressed)))
+	}
 	if len(a.Results) == 0 {
 		output.WriteString(color.GreenString("No problems detected\n"))
 		return []byte(output.String()), nil
@@ -83,12 +100,29 @@ func (a *Analysis) textOutput() ([]byte, error) {
 			color.YellowString(result.Name),
 			color.CyanString(result.ParentObject)))
 		for _, err := range result.Error {
-			output.WriteString(fmt.Sprintf("- %s %s\n", color.RedString("Error:"), color.RedString(err.Text)))
+			if err.Code != "" {
+				output.WriteString(fmt.Sprintf("- %s %s %s\n", color.RedString("Error:"), color.RedString(err.Text), color.HiBlackString("[%s]", err.Code)))
+			} else {
+				output.WriteString(fmt.Sprintf("- %s %s\n", color.RedString("Error:"), color.RedString(err.Text)))
+			}
 			if err.KubernetesDoc != "" {
 				output.WriteString(fmt.Sprintf("  %s %s\n", color.RedString("Kubernetes Doc:"), color.RedString(err.KubernetesDoc)))
 			}
+			if err.Remediation != "" {
+				output.WriteString(fmt.Sprintf("  %s %s\n", color.GreenString("Remediation:"), color.GreenString(err.Remediation)))
+			}
 		}
 		output.WriteString(color.GreenString(result.Details + "\n"))
 	}
+	if a.Explain && a.Usage != nil {
+		usage := a.Usage.Summary()
+		estimated := ""
+		if usage.Estimated {
+			estimated = " (estimated)"
+		}
+		output.WriteString(fmt.Sprintf("%s %d prompt + %d completion = %d tokens in %d requests%s, cost ~$%.4f\n",
+			color.YellowString("Token usage:"), usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens,
+			usage.Requests, estimated, usage.EstimatedCost))
+	}
 	return []byte(output.String()), nil
 }
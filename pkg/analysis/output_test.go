@@ -16,6 +16,7 @@ package analysis
 import (
 	"testing"
 
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,6 +42,22 @@ func TestPrintOutput(t *testing.T) {
 			format:         "text",
 			expectedOutput: "AI Provider: AI not used; --explain not set\n\nNo problems detected\n",
 		},
+		{
+			name: "text format with failure code",
+			a: &Analysis{
+				Results: []common.Result{{
+					Kind: "Pod",
+					Name: "default/web",
+					Error: []common.Failure{{
+						Text:        "back-off restarting failed container",
+						Code:        common.CodePodCrashLoop,
+						Remediation: "Check the logs of the previous container run",
+					}},
+				}},
+			},
+			format:         "text",
+			expectedOutput: "- Error: back-off restarting failed container [POD_CRASHLOOP]\n  Remediation: Check the logs of the previous container run\n",
+		},
 		{
 			name:        "unsupported format",
 			a:           &Analysis{},
@@ -0,0 +1,303 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IgnoreAnnotation hides all problems of an object when set to "true".
+const IgnoreAnnotation = "k8sgpt.ai/ignore"
+
+const expiresLayout = "2006-01-02"
+
+// Suppression hides known and accepted problems. Every field that is set must
+// match; a rule with a code hides only the failures with that code.
+type Suppression struct {
+	// Analyzer is the kind of the results, e.g. Pod or Ingress.
+	Analyzer  string `yaml:"analyzer,omitempty" json:"analyzer,omitempty"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	// Name is a glob matched against the name of the object, e.g. "web-*".
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Labels is a label selector matched against the labels of the object.
+	Labels string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Code   string `yaml:"code,omitempty" json:"code,omitempty"`
+	Reason string `yaml:"reason" json:"reason"`
+	Owner  string `yaml:"owner" json:"owner"`
+	// Expires is the last day, as YYYY-MM-DD, the suppression applies.
+	Expires string `yaml:"expires,omitempty" json:"expires,omitempty"`
+}
+
+type suppressionFile struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// Validate checks that the suppression matches something and is documented.
+func (s Suppression) Validate() error {
+	if s.Analyzer == "" && s.Namespace == "" && s.Name == "" && s.Labels == "" && s.Code == "" {
+		return errors.New("at least one of analyzer, namespace, name, labels or code is required")
+	}
+	if strings.TrimSpace(s.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	if strings.TrimSpace(s.Owner) == "" {
+		return errors.New("owner is required")
+	}
+	if _, err := path.Match(s.Name, ""); err != nil {
+		return fmt.Errorf("invalid name glob %q: %w", s.Name, err)
+	}
+	if _, err := labels.Parse(s.Labels); err != nil {
+		return fmt.Errorf("invalid label selector %q: %w", s.Labels, err)
+	}
+	if s.Expires != "" {
+		if _, err := time.Parse(expiresLayout, s.Expires); err != nil {
+			return fmt.Errorf("invalid expiry date %q, expected YYYY-MM-DD", s.Expires)
+		}
+	}
+	return nil
+}
+
+// Expired reports whether the last day of the suppression has passed.
+func (s Suppression) Expired(now time.Time) bool {
+	if s.Expires == "" {
+		return false
+	}
+	expires, err := time.ParseInLocation(expiresLayout, s.Expires, now.Location())
+	if err != nil {
+		return false
+	}
+	return !now.Before(expires.AddDate(0, 0, 1))
+}
+
+func (s Suppression) String() string {
+	var matchers []string
+	for _, m := range []struct{ key, value string }{
+		{"analyzer", s.Analyzer},
+		{"namespace", s.Namespace},
+		{"name", s.Name},
+		{"labels", s.Labels},
+		{"code", s.Code},
+	} {
+		if m.value != "" {
+			matchers = append(matchers, fmt.Sprintf("%s=%s", m.key, m.value))
+		}
+	}
+	description := fmt.Sprintf("%s (%s, owner %s", strings.Join(matchers, " "), s.Reason, s.Owner)
+	if s.Expires != "" {
+		description += ", expires " + s.Expires
+	}
+	return description + ")"
+}
+
+// matches reports whether the suppression applies to a failure of a result.
+// objectLabels is nil if the labels of the object are unknown.
+func (s Suppression) matches(ref ObjectRef, objectLabels map[string]string, failure common.Failure) bool {
+	if s.Analyzer != "" && !strings.EqualFold(s.Analyzer, ref.Kind) {
+		return false
+	}
+	if s.Namespace != "" && s.Namespace != ref.Namespace {
+		return false
+	}
+	if s.Name != "" {
+		if ok, _ := path.Match(s.Name, ref.Name); !ok {
+			return false
+		}
+	}
+	if s.Labels != "" {
+		selector, err := labels.Parse(s.Labels)
+		if err != nil || objectLabels == nil || !selector.Matches(labels.Set(objectLabels)) {
+			return false
+		}
+	}
+	return s.Code == "" || s.Code == failure.Code
+}
+
+// DefaultSuppressionsFile is the suppression file used unless another one is given.
+func DefaultSuppressionsFile() string {
+	return filepath.Join(xdg.ConfigHome, "k8sgpt", "suppressions.yaml")
+}
+
+// LoadSuppressions reads a suppression file. A missing file has no suppressions.
+func LoadSuppressions(file string) ([]Suppression, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f suppressionFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	for i, s := range f.Suppressions {
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("suppression %d in %s: %w", i+1, file, err)
+		}
+	}
+	return f.Suppressions, nil
+}
+
+// SaveSuppressions writes a suppression file.
+func SaveSuppressions(file string, suppressions []Suppression) error {
+	data, err := yaml.Marshal(suppressionFile{Suppressions: suppressions})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+// applySuppressions removes the suppressed failures, and results left without
+// failures, counting them in Suppressed. Objects annotated with
+// IgnoreAnnotation are hidden entirely. Expired suppressions no longer apply
+// and are collected in ExpiredSuppressions.
+func (a *Analysis) applySuppressions() {
+	now := time.Now()
+	var active []Suppression
+	for _, s := range a.Suppressions {
+		if s.Expired(now) {
+			a.ExpiredSuppressions = append(a.ExpiredSuppressions, s)
+		} else {
+			active = append(active, s)
+		}
+	}
+
+	metadata := a.newObjectMetadata()
+	var kept []common.Result
+	for _, result := range a.Results {
+		ref := ResultRef(result)
+		object := metadata.get(ref)
+		if object != nil && object.GetAnnotations()[IgnoreAnnotation] == "true" {
+			a.Suppressed += len(result.Error)
+			continue
+		}
+		var objectLabels map[string]string
+		if object != nil {
+			objectLabels = object.GetLabels()
+			if objectLabels == nil {
+				objectLabels = map[string]string{}
+			}
+		}
+
+		var failures []common.Failure
+		for _, failure := range result.Error {
+			if suppressed(active, ref, objectLabels, failure) {
+				a.Suppressed++
+			} else {
+				failures = append(failures, failure)
+			}
+		}
+		if len(failures) > 0 {
+			result.Error = failures
+			kept = append(kept, result)
+		}
+	}
+	a.Results = kept
+}
+
+func suppressed(suppressions []Suppression, ref ObjectRef, objectLabels map[string]string, failure common.Failure) bool {
+	for _, s := range suppressions {
+		if s.matches(ref, objectLabels, failure) {
+			return true
+		}
+	}
+	return false
+}
+
+// objectMetadata reads the metadata of the objects of results, with one List
+// per kind. Only the metadata is read, so Secrets are safe to look at.
+type objectMetadata struct {
+	a       *Analysis
+	mapper  meta.RESTMapper
+	objects map[string]map[ObjectRef]*metav1.PartialObjectMetadata
+}
+
+func (a *Analysis) newObjectMetadata() *objectMetadata {
+	m := &objectMetadata{a: a, objects: map[string]map[ObjectRef]*metav1.PartialObjectMetadata{}}
+	if a.Client != nil && a.Client.Client != nil && a.Client.CtrlClient != nil {
+		m.mapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(a.Client.Client.Discovery()))
+	}
+	return m
+}
+
+// get returns the metadata of the object of a result, or nil if its kind is
+// not a kind of the cluster or it does not exist.
+func (m *objectMetadata) get(ref ObjectRef) *metav1.PartialObjectMetadata {
+	if m.mapper == nil {
+		return nil
+	}
+	objects, ok := m.objects[ref.Kind]
+	if !ok {
+		var err error
+		objects, err = m.list(ref.Kind)
+		if err != nil {
+			m.a.Errors = append(m.a.Errors, fmt.Sprintf("[%s] reading labels and annotations for suppressions: %s", ref.Kind, err))
+		}
+		m.objects[ref.Kind] = objects
+	}
+	return objects[ref]
+}
+
+func (m *objectMetadata) list(kind string) (map[ObjectRef]*metav1.PartialObjectMetadata, error) {
+	// results name their kind, e.g. Pod or HorizontalPodAutoScaler, which
+	// the mapper resolves like kubectl does
+	gvk, err := m.mapper.KindFor(schema.GroupVersionResource{Resource: strings.ToLower(kind)})
+	if meta.IsNoMatchError(err) {
+		// results about something else than an object, e.g. a Helm release
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := m.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	var opts []ctrl.ListOption
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && m.a.Namespace != "" {
+		opts = append(opts, ctrl.InNamespace(m.a.Namespace))
+	}
+	if err := m.a.Client.CtrlClient.List(m.a.Context, list, opts...); err != nil {
+		return nil, err
+	}
+	objects := map[ObjectRef]*metav1.PartialObjectMetadata{}
+	for i := range list.Items {
+		item := &list.Items[i]
+		objects[ObjectRef{Kind: kind, Namespace: item.Namespace, Name: item.Name}] = item
+	}
+	return objects, nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestSuppressionValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		suppression Suppression
+		wantErr     string
+	}{
+		{
+			name:        "valid",
+			suppression: Suppression{Analyzer: "Pod", Name: "web-*", Labels: "team=legacy", Reason: "known", Owner: "ops", Expires: "2030-01-31"},
+		},
+		{
+			name:        "no matcher",
+			suppression: Suppression{Reason: "known", Owner: "ops"},
+			wantErr:     "at least one of",
+		},
+		{
+			name:        "no reason",
+			suppression: Suppression{Code: common.CodePodCrashLoop, Owner: "ops"},
+			wantErr:     "reason is required",
+		},
+		{
+			name:        "no owner",
+			suppression: Suppression{Code: common.CodePodCrashLoop, Reason: "known"},
+			wantErr:     "owner is required",
+		},
+		{
+			name:        "invalid selector",
+			suppression: Suppression{Labels: "team in legacy", Reason: "known", Owner: "ops"},
+			wantErr:     "invalid label selector",
+		},
+		{
+			name:        "invalid expiry",
+			suppression: Suppression{Namespace: "legacy", Reason: "known", Owner: "ops", Expires: "31/01/2030"},
+			wantErr:     "invalid expiry date",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.suppression.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSuppressionExpired(t *testing.T) {
+	s := Suppression{Expires: "2024-05-01"}
+	require.False(t, s.Expired(time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC)))
+	require.True(t, s.Expired(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)))
+	require.False(t, Suppression{}.Expired(time.Now()))
+}
+
+func TestLoadSaveSuppressions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "k8sgpt", "suppressions.yaml")
+	suppressions, err := LoadSuppressions(file)
+	require.NoError(t, err)
+	require.Empty(t, suppressions)
+
+	want := []Suppression{{Namespace: "legacy", Code: common.CodePodCrashLoop, Reason: "known", Owner: "ops", Expires: "2030-01-31"}}
+	require.NoError(t, SaveSuppressions(file, want))
+	suppressions, err = LoadSuppressions(file)
+	require.NoError(t, err)
+	require.Equal(t, want, suppressions)
+
+	require.NoError(t, os.WriteFile(file, []byte("suppressions:\n- namespace: legacy\n"), 0o644))
+	_, err = LoadSuppressions(file)
+	require.ErrorContains(t, err, "suppression 1")
+}
+
+func TestApplySuppressions(t *testing.T) {
+	ctrlClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: map[string]string{"team": "legacy"}}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "shop", Annotations: map[string]string{IgnoreAnnotation: "true"}}},
+	).Build()
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true},
+				{Name: "services", SingularName: "service", Kind: "Service", Namespaced: true},
+			},
+		},
+	}
+
+	results := func() []common.Result {
+		return []common.Result{
+			{Kind: "Pod", Name: "shop/web", Error: []common.Failure{{Text: "crash", Code: common.CodePodCrashLoop}}},
+			{Kind: "Pod", Name: "shop/api", Error: []common.Failure{
+				{Text: "crash", Code: common.CodePodCrashLoop},
+				{Text: "pull", Code: common.CodePodImagePullFailed},
+			}},
+			{Kind: "Pod", Name: "shop/batch", Error: []common.Failure{{Text: "crash", Code: common.CodePodCrashLoop}}},
+			{Kind: "Service", Name: "other/web", Error: []common.Failure{{Text: "no endpoints"}}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		suppressions []Suppression
+		wantResults  []string
+		wantFailures int
+		wantExpired  int
+	}{
+		{
+			name:         "annotation only",
+			wantResults:  []string{"shop/web", "shop/api", "other/web"},
+			wantFailures: 4,
+		},
+		{
+			name:         "analyzer and namespace",
+			suppressions: []Suppression{{Analyzer: "pod", Namespace: "shop", Reason: "known", Owner: "ops"}},
+			wantResults:  []string{"other/web"},
+			wantFailures: 1,
+		},
+		{
+			name:         "name glob",
+			suppressions: []Suppression{{Name: "w*", Reason: "known", Owner: "ops"}},
+			wantResults:  []string{"shop/api"},
+			wantFailures: 2,
+		},
+		{
+			name:         "label selector",
+			suppressions: []Suppression{{Labels: "team=legacy", Reason: "known", Owner: "ops"}},
+			wantResults:  []string{"shop/api", "other/web"},
+			wantFailures: 3,
+		},
+		{
+			name:         "code",
+			suppressions: []Suppression{{Code: common.CodePodCrashLoop, Reason: "known", Owner: "ops"}},
+			wantResults:  []string{"shop/api", "other/web"},
+			wantFailures: 2,
+		},
+		{
+			name:         "expired",
+			suppressions: []Suppression{{Analyzer: "Pod", Reason: "known", Owner: "ops", Expires: "2000-01-01"}},
+			wantResults:  []string{"shop/web", "shop/api", "other/web"},
+			wantFailures: 4,
+			wantExpired:  1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Analysis{
+				Context:      context.Background(),
+				Client:       &kubernetes.Client{Client: clientset, CtrlClient: ctrlClient},
+				Results:      results(),
+				Suppressions: tt.suppressions,
+			}
+			a.applySuppressions()
+			require.Empty(t, a.Errors)
+
+			var names []string
+			failures := 0
+			for _, result := range a.Results {
+				names = append(names, result.Name)
+				failures += len(result.Error)
+			}
+			require.Equal(t, tt.wantResults, names)
+			require.Equal(t, tt.wantFailures, failures)
+			require.Equal(t, 5-tt.wantFailures, a.Suppressed)
+			require.Len(t, a.ExpiredSuppressions, tt.wantExpired)
+		})
+	}
+}
+
+func TestApplySuppressionsListError(t *testing.T) {
+	ctrlClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, client ctrl.WithWatch, list ctrl.ObjectList, opts ...ctrl.ListOption) error {
+			return errors.New("forbidden")
+		},
+	}).Build()
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true}},
+		},
+	}
+
+	a := &Analysis{
+		Context: context.Background(),
+		Client:  &kubernetes.Client{Client: clientset, CtrlClient: ctrlClient},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "shop/web", Error: []common.Failure{{Text: "crash"}}},
+			{Kind: "Pod", Name: "shop/api", Error: []common.Failure{{Text: "crash"}}},
+			{Kind: "HelmRelease", Name: "shop/web", Error: []common.Failure{{Text: "deprecated"}}},
+		},
+		Suppressions: []Suppression{{Labels: "team=legacy", Reason: "known", Owner: "ops"}},
+	}
+	a.applySuppressions()
+
+	require.Len(t, a.Results, 3)
+	// the objects of a kind are listed once
+	require.Len(t, a.Errors, 1)
+	require.Contains(t, a.Errors[0], "forbidden")
+}
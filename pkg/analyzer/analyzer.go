@@ -39,20 +39,35 @@ var coreAnalyzerMap = map[string]common.IAnalyzer{
 	"Service":                        ServiceAnalyzer{},
 	"Ingress":                        IngressAnalyzer{},
 	"StatefulSet":                    StatefulSetAnalyzer{},
+	"DaemonSet":                      DaemonSetAnalyzer{},
 	"CronJob":                        CronJobAnalyzer{},
+	"Job":                            JobAnalyzer{},
 	"Node":                           NodeAnalyzer{},
 	"ValidatingWebhookConfiguration": ValidatingWebhookAnalyzer{},
 	"MutatingWebhookConfiguration":   MutatingWebhookAnalyzer{},
 }
 
 var additionalAnalyzerMap = map[string]common.IAnalyzer{
-	"HorizontalPodAutoScaler": HpaAnalyzer{},
-	"PodDisruptionBudget":     PdbAnalyzer{},
-	"NetworkPolicy":           NetworkPolicyAnalyzer{},
-	"Log":                     LogAnalyzer{},
-	"GatewayClass":            GatewayClassAnalyzer{},
-	"Gateway":                 GatewayAnalyzer{},
-	"HTTPRoute":               HTTPRouteAnalyzer{},
+	"HorizontalPodAutoScaler":  HpaAnalyzer{},
+	"PodDisruptionBudget":      PdbAnalyzer{},
+	"NetworkPolicy":            NetworkPolicyAnalyzer{},
+	"Log":                      LogAnalyzer{},
+	"GatewayClass":             GatewayClassAnalyzer{},
+	"Gateway":                  GatewayAnalyzer{},
+	"HTTPRoute":                HTTPRouteAnalyzer{},
+	"DeprecatedAPI":            DeprecatedAPIAnalyzer{},
+	"ConfigReference":          ConfigReferenceAnalyzer{},
+	"Certificate":              CertificateAnalyzer{},
+	"PersistentVolume":         PersistentVolumeAnalyzer{},
+	"StorageClass":             StorageClassAnalyzer{},
+	"VolumeAttachment":         VolumeAttachmentAnalyzer{},
+	"Namespace":                NamespaceAnalyzer{},
+	"Finalizer":                FinalizerAnalyzer{},
+	"APIService":               APIServiceAnalyzer{},
+	"CustomResourceDefinition": CustomResourceDefinitionAnalyzer{},
+	"ResourceQuota":            ResourceQuotaAnalyzer{},
+	"LimitRange":               LimitRangeAnalyzer{},
+	"Event":                    EventAnalyzer{},
 }
 
 func ListFilters() ([]string, []string, []string) {
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var apiServiceListGVK = schema.GroupVersionKind{Group: "apiregistration.k8s.io", Version: "v1", Kind: "APIServiceList"}
+
+// APIServiceAnalyzer is an analyzer that checks for unavailable aggregated
+// APIs, which break discovery and many kubectl commands
+type APIServiceAnalyzer struct{}
+
+// Analyze scans the APIServices of the cluster and the pods of the Services
+// serving them
+func (APIServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "APIService"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	services, err := listAPIServices(a)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, service := range services {
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: service.Name,
+				Masked:   util.MaskString(service.Name),
+			},
+		}
+		if ok, message := service.available(); !ok {
+			condition := meta.FindStatusCondition(service.Status.Conditions, "Available")
+			reason := ""
+			if condition != nil {
+				reason = condition.Reason
+			}
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("APIService %s is not available, the resources of %s/%s cannot be discovered or used: %s", service.Name, service.Spec.Group, service.Spec.Version, message),
+				Code:      common.CodeAPIServiceUnavailable,
+				Reason:    reason,
+				Sensitive: sensitive,
+			})
+		}
+
+		if svc := service.Spec.Service; svc != nil {
+			backend, err := getServiceBackend(a, svc.Namespace, svc.Name)
+			if err != nil {
+				return nil, err
+			}
+			reference := &common.ObjectReference{Kind: "Service", Namespace: svc.Namespace, Name: svc.Name}
+			sensitive := append(sensitive, common.Sensitive{
+				Unmasked: svc.Name,
+				Masked:   util.MaskString(svc.Name),
+			})
+			switch {
+			case !backend.Found:
+				failures = append(failures, common.Failure{
+					Text:      fmt.Sprintf("Service %s/%s serving APIService %s not found", svc.Namespace, svc.Name, service.Name),
+					Code:      common.CodeAPIServiceServiceNotFound,
+					Reference: reference,
+					Sensitive: sensitive,
+				})
+			case backend.Selected && len(backend.Pods) == 0:
+				failures = append(failures, common.Failure{
+					Text:      fmt.Sprintf("No active pods found within service %s/%s serving APIService %s", svc.Namespace, svc.Name, service.Name),
+					Code:      common.CodeAPIServiceNoActivePods,
+					Reference: reference,
+					Sensitive: sensitive,
+				})
+			}
+			for _, pod := range backend.inactivePods() {
+				failures = append(failures, common.Failure{
+					Text:      fmt.Sprintf("APIService %s is served by the inactive pod %s/%s", service.Name, pod.Namespace, pod.Name),
+					Code:      common.CodeAPIServiceInactivePod,
+					Reference: &common.ObjectReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name},
+					Sensitive: append(sensitive, common.Sensitive{
+						Unmasked: pod.Name,
+						Masked:   util.MaskString(pod.Name),
+					}),
+				})
+			}
+		}
+
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  kind,
+				Name:  service.Name,
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, service.Name, "").Set(float64(len(failures)))
+		}
+	}
+
+	return a.Results, nil
+}
+
+// apiService holds the fields of an apiregistration.k8s.io/v1 APIService the
+// analyzers read, the aggregator is not a dependency of k8sgpt.
+type apiService struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		// Service is nil for APIs served by the kube-apiserver itself.
+		Service *struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+			Port      *int32 `json:"port,omitempty"`
+		} `json:"service,omitempty"`
+		Group   string `json:"group"`
+		Version string `json:"version"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []metav1.Condition `json:"conditions,omitempty"`
+	} `json:"status"`
+}
+
+// available returns whether the APIService is available, and the message of
+// its Available condition if it is not.
+func (s apiService) available() (bool, string) {
+	condition := meta.FindStatusCondition(s.Status.Conditions, "Available")
+	if condition == nil {
+		return false, "the Available condition is not reported"
+	}
+	if condition.Status != metav1.ConditionTrue {
+		return false, condition.Message
+	}
+	return true, ""
+}
+
+// listAPIServices returns the APIServices of the cluster, or none if the
+// controller-runtime client is not set up.
+func listAPIServices(a common.Analyzer) ([]apiService, error) {
+	client := a.Client.CtrlClient
+	if client == nil {
+		return nil, nil
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(apiServiceListGVK)
+	if err := client.List(a.Context, list); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	services := make([]apiService, 0, len(list.Items))
+	for _, item := range list.Items {
+		var service apiService
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &service); err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+// unavailableAPIServices returns the APIServices served by an extension API
+// server that is not available, which breaks discovery of all their resources.
+func unavailableAPIServices(a common.Analyzer) ([]apiService, error) {
+	services, err := listAPIServices(a)
+	if err != nil {
+		return nil, err
+	}
+	var unavailable []apiService
+	for _, service := range services {
+		if service.Spec.Service == nil {
+			continue
+		}
+		if ok, _ := service.available(); !ok {
+			unavailable = append(unavailable, service)
+		}
+	}
+	return unavailable, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAPIServiceAnalyzer(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-server", Namespace: "kube-system"},
+		Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "metrics-server"}},
+	}
+	pod := func(phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics-server-1", Namespace: "kube-system", Labels: map[string]string{"app": "metrics-server"}},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		available metav1.ConditionStatus
+		objects   []runtime.Object
+		wantCodes []string
+	}{
+		{
+			name:      "available",
+			available: metav1.ConditionTrue,
+			objects:   []runtime.Object{service, pod(v1.PodRunning)},
+		},
+		{
+			name:      "service not found",
+			available: metav1.ConditionFalse,
+			wantCodes: []string{common.CodeAPIServiceUnavailable, common.CodeAPIServiceServiceNotFound},
+		},
+		{
+			name:      "no pods",
+			available: metav1.ConditionFalse,
+			objects:   []runtime.Object{service},
+			wantCodes: []string{common.CodeAPIServiceUnavailable, common.CodeAPIServiceNoActivePods},
+		},
+		{
+			name:      "inactive pod",
+			available: metav1.ConditionFalse,
+			objects:   []runtime.Object{service, pod(v1.PodPending)},
+			wantCodes: []string{common.CodeAPIServiceUnavailable, common.CodeAPIServiceInactivePod},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrlClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				newAPIService("v1beta1.metrics.k8s.io", true, tt.available),
+				newAPIService("v1.apps", false, metav1.ConditionTrue),
+			).Build()
+
+			results, err := APIServiceAnalyzer{}.Analyze(common.Analyzer{
+				Client:  &kubernetes.Client{Client: fake.NewSimpleClientset(tt.objects...), CtrlClient: ctrlClient},
+				Context: context.Background(),
+			})
+			require.NoError(t, err)
+			if len(tt.wantCodes) == 0 {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			require.Equal(t, "v1beta1.metrics.k8s.io", results[0].Name)
+			var codes []string
+			for _, failure := range results[0].Error {
+				codes = append(codes, failure.Code)
+			}
+			require.Equal(t, tt.wantCodes, codes)
+			require.Equal(t, "FailedDiscoveryCheck", results[0].Error[0].Reason)
+		})
+	}
+}
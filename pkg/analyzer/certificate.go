@@ -0,0 +1,352 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	gtwapi "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// DefaultCertificateExpiryWindow is how long before they expire certificates
+// are reported, unless certificate_expiry_window is configured.
+const DefaultCertificateExpiryWindow = 30 * 24 * time.Hour
+
+// tlsCAKey is the key of the optional CA certificate of TLS Secrets, as
+// written by cert-manager.
+const tlsCAKey = "ca.crt"
+
+// CertificateExpiryWindow returns the configured certificate_expiry_window.
+func CertificateExpiryWindow() time.Duration {
+	if window := viper.GetDuration("certificate_expiry_window"); window > 0 {
+		return window
+	}
+	return DefaultCertificateExpiryWindow
+}
+
+// CertificateAnalyzer is an analyzer that checks the certificates of TLS
+// Secrets and of the Ingresses and Gateways serving them
+type CertificateAnalyzer struct{}
+
+// tlsSecret is a Secret holding a certificate.
+type tlsSecret struct {
+	secret v1.Secret
+	chain  []*x509.Certificate
+	err    error
+}
+
+// Analyze scans all namespaces for expired, expiring, incomplete and
+// invalid certificates, and for Ingresses and Gateways serving hosts their
+// certificates are not valid for
+func (CertificateAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Certificate"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	secrets, err := a.Client.GetClient().CoreV1().Secrets(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	certificates := map[string]*tlsSecret{}
+	for _, secret := range secrets.Items {
+		if _, ok := secret.Data[v1.TLSCertKey]; !ok && secret.Type != v1.SecretTypeTLS {
+			continue
+		}
+		chain, err := parseCertificates(secret.Data[v1.TLSCertKey])
+		certificates[secret.Namespace+"/"+secret.Name] = &tlsSecret{secret: secret, chain: chain, err: err}
+	}
+
+	now := time.Now()
+	window := CertificateExpiryWindow()
+	for key, certificate := range certificates {
+		failures := certificateFailures(certificate, now, window)
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  "Secret",
+				Name:  key,
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, certificate.secret.Name, certificate.secret.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	ingresses, err := a.Client.GetClient().NetworkingV1().Ingresses(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ing := range ingresses.Items {
+		var failures []common.Failure
+		for _, tls := range ing.Spec.TLS {
+			// a missing secret is reported by the Ingress analyzer
+			certificate, ok := certificates[ing.Namespace+"/"+tls.SecretName]
+			if !ok || len(certificate.chain) == 0 {
+				continue
+			}
+			failures = append(failures, hostMismatches("Ingress", ing.Namespace, ing.Name, certificate, tls.Hosts)...)
+		}
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  "Ingress",
+				Name:  fmt.Sprintf("%s/%s", ing.Namespace, ing.Name),
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, ing.Name, ing.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	gateways, err := listGateways(a)
+	if err != nil {
+		return nil, err
+	}
+	for _, gtw := range gateways {
+		var failures []common.Failure
+		for _, listener := range gtw.Spec.Listeners {
+			if listener.TLS == nil || listener.Hostname == nil {
+				continue
+			}
+			for _, ref := range listener.TLS.CertificateRefs {
+				if (ref.Kind != nil && *ref.Kind != "Secret") || (ref.Group != nil && *ref.Group != "") {
+					continue
+				}
+				namespace := gtw.Namespace
+				if ref.Namespace != nil {
+					namespace = string(*ref.Namespace)
+				}
+				certificate, ok := certificates[namespace+"/"+string(ref.Name)]
+				if !ok || len(certificate.chain) == 0 {
+					continue
+				}
+				failures = append(failures, hostMismatches("Gateway", gtw.Namespace, gtw.Name, certificate, []string{string(*listener.Hostname)})...)
+			}
+		}
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  "Gateway",
+				Name:  fmt.Sprintf("%s/%s", gtw.Namespace, gtw.Name),
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, gtw.Name, gtw.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	return a.Results, nil
+}
+
+// listGateways returns the Gateways of the analyzed namespaces, or none if the
+// Gateway API is not installed.
+func listGateways(a common.Analyzer) ([]gtwapi.Gateway, error) {
+	client := a.Client.CtrlClient
+	if client == nil {
+		return nil, nil
+	}
+	if err := gtwapi.AddToScheme(client.Scheme()); err != nil {
+		return nil, err
+	}
+	gtwList := &gtwapi.GatewayList{}
+	if err := client.List(a.Context, gtwList, ctrl.InNamespace(a.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return gtwList.Items, nil
+}
+
+// parseCertificates decodes the PEM encoded certificates of a Secret, leaf
+// first.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, certificate)
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return chain, nil
+}
+
+func certificateFailures(certificate *tlsSecret, now time.Time, window time.Duration) []common.Failure {
+	secret := certificate.secret
+	sensitive := []common.Sensitive{
+		{
+			Unmasked: secret.Namespace,
+			Masked:   util.MaskString(secret.Namespace),
+		},
+		{
+			Unmasked: secret.Name,
+			Masked:   util.MaskString(secret.Name),
+		},
+	}
+	if certificate.err != nil {
+		return []common.Failure{{
+			Text:      fmt.Sprintf("Secret %s/%s has no valid certificate in %s: %s", secret.Namespace, secret.Name, v1.TLSCertKey, certificate.err),
+			Code:      common.CodeCertificateInvalid,
+			Sensitive: sensitive,
+		}}
+	}
+
+	var failures []common.Failure
+	leaf := certificate.chain[0]
+	subject := certificateName(leaf)
+	sensitive = append(sensitive, common.Sensitive{
+		Unmasked: subject,
+		Masked:   util.MaskString(subject),
+	})
+	switch {
+	case !now.Before(leaf.NotAfter):
+		failures = append(failures, common.Failure{
+			Text:      fmt.Sprintf("The certificate for %s in Secret %s/%s expired on %s", subject, secret.Namespace, secret.Name, leaf.NotAfter.UTC().Format(time.RFC3339)),
+			Code:      common.CodeCertificateExpired,
+			Sensitive: sensitive,
+		})
+	case leaf.NotAfter.Sub(now) < window:
+		failures = append(failures, common.Failure{
+			Text: fmt.Sprintf("The certificate for %s in Secret %s/%s expires in %d days on %s", subject, secret.Namespace, secret.Name,
+				int(leaf.NotAfter.Sub(now).Hours()/24), leaf.NotAfter.UTC().Format(time.RFC3339)),
+			Code:      common.CodeCertificateExpiring,
+			Sensitive: sensitive,
+		})
+	}
+
+	if missing := missingIssuer(certificate); missing != "" {
+		failures = append(failures, common.Failure{
+			Text:      fmt.Sprintf("The certificate chain in Secret %s/%s is incomplete, the issuer %s is neither in %s, %s nor a trusted root", secret.Namespace, secret.Name, missing, v1.TLSCertKey, tlsCAKey),
+			Code:      common.CodeCertificateChainIncomplete,
+			Sensitive: sensitive,
+		})
+	}
+	return failures
+}
+
+// missingIssuer returns the issuer a certificate chain cannot be verified up
+// to, or an empty string if it is complete or self-signed.
+func missingIssuer(certificate *tlsSecret) string {
+	leaf := certificate.chain[0]
+	if bytes.Equal(leaf.RawIssuer, leaf.RawSubject) {
+		return ""
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certificate.chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	if ca, err := parseCertificates(certificate.secret.Data[tlsCAKey]); err == nil {
+		for _, cert := range ca {
+			roots.AddCert(cert)
+		}
+	}
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		Roots:         roots,
+		// expiry is reported on its own
+		CurrentTime: leaf.NotBefore.Add(time.Second),
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	var unknownAuthority x509.UnknownAuthorityError
+	if err == nil || len(chains) > 0 || !errors.As(err, &unknownAuthority) {
+		return ""
+	}
+	last := leaf
+	for _, cert := range certificate.chain[1:] {
+		if bytes.Equal(last.RawIssuer, cert.RawSubject) {
+			last = cert
+		}
+	}
+	return last.Issuer.String()
+}
+
+// certificateName is the name a certificate is known by.
+func certificateName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return cert.Subject.String()
+}
+
+// hostMismatches reports the hosts a certificate served by an Ingress or
+// Gateway is not valid for.
+func hostMismatches(kind string, namespace string, name string, certificate *tlsSecret, hosts []string) []common.Failure {
+	leaf := certificate.chain[0]
+	var failures []common.Failure
+	for _, host := range hosts {
+		// a wildcard host needs a wildcard certificate
+		check := strings.Replace(host, "*", "k8sgpt-wildcard-check", 1)
+		if host == "" || leaf.VerifyHostname(check) == nil {
+			continue
+		}
+		valid := leaf.DNSNames
+		if len(valid) == 0 {
+			valid = []string{leaf.Subject.CommonName}
+		}
+		failures = append(failures, common.Failure{
+			Text: fmt.Sprintf("%s %s/%s serves %s with the certificate of Secret %s/%s, which is only valid for %s",
+				kind, namespace, name, host, certificate.secret.Namespace, certificate.secret.Name, strings.Join(valid, ", ")),
+			Code:      common.CodeCertificateHostMismatch,
+			Reference: &common.ObjectReference{Kind: "Secret", Namespace: certificate.secret.Namespace, Name: certificate.secret.Name},
+			Sensitive: []common.Sensitive{
+				{
+					Unmasked: namespace,
+					Masked:   util.MaskString(namespace),
+				},
+				{
+					Unmasked: name,
+					Masked:   util.MaskString(name),
+				},
+				{
+					Unmasked: host,
+					Masked:   util.MaskString(host),
+				},
+				{
+					Unmasked: certificate.secret.Name,
+					Masked:   util.MaskString(certificate.secret.Name),
+				},
+			},
+		})
+	}
+	return failures
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gtwapi "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCertificate creates a certificate signed by parent, or a self-signed
+// one if parent is nil.
+func newTestCertificate(t *testing.T, name string, notAfter time.Time, isCA bool, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	if isCA {
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.DNSNames = []string{name}
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCertificate{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func tlsSecretWith(name string, crt []byte, ca []byte) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Type:       v1.SecretTypeTLS,
+		Data:       map[string][]byte{v1.TLSCertKey: crt, v1.TLSPrivateKeyKey: []byte("key")},
+	}
+	if ca != nil {
+		secret.Data["ca.crt"] = ca
+	}
+	return secret
+}
+
+func TestCertificateAnalyzer(t *testing.T) {
+	now := time.Now()
+	root := newTestCertificate(t, "Test Root CA", now.AddDate(10, 0, 0), true, nil)
+	intermediate := newTestCertificate(t, "Test Intermediate CA", now.AddDate(5, 0, 0), true, root)
+	web := newTestCertificate(t, "web.example.com", now.AddDate(1, 0, 0), false, intermediate)
+	expired := newTestCertificate(t, "old.example.com", now.Add(-time.Minute), false, nil)
+	expiring := newTestCertificate(t, "soon.example.com", now.AddDate(0, 0, 10), false, nil)
+
+	clientset := fake.NewSimpleClientset(
+		tlsSecretWith("web", append(append([]byte{}, web.pem...), intermediate.pem...), root.pem),
+		tlsSecretWith("incomplete", web.pem, root.pem),
+		tlsSecretWith("expired", expired.pem, nil),
+		tlsSecretWith("expiring", expiring.pem, nil),
+		tlsSecretWith("invalid", []byte("not a certificate"), nil),
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "opaque", Namespace: "default"}},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"web.example.com", "api.example.com"}, SecretName: "web"},
+				{Hosts: []string{"missing.example.com"}, SecretName: "missing"},
+			}},
+		},
+	)
+
+	hostname := gtwapi.Hostname("*.example.com")
+	err := gtwapi.Install(scheme.Scheme)
+	require.NoError(t, err)
+	ctrlClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&gtwapi.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "default"},
+		Spec: gtwapi.GatewaySpec{Listeners: []gtwapi.Listener{{
+			Name:     "https",
+			Hostname: &hostname,
+			Protocol: gtwapi.HTTPSProtocolType,
+			TLS: &gtwapi.GatewayTLSConfig{CertificateRefs: []gtwapi.SecretObjectReference{
+				{Name: "web"},
+			}},
+		}}},
+	}).Build()
+
+	results, err := CertificateAnalyzer{}.Analyze(common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset, CtrlClient: ctrlClient},
+		Context:   context.Background(),
+		Namespace: "default",
+	})
+	require.NoError(t, err)
+
+	codes := map[string][]string{}
+	for _, result := range results {
+		for _, failure := range result.Error {
+			codes[result.Kind+" "+result.Name] = append(codes[result.Kind+" "+result.Name], failure.Code)
+		}
+	}
+	require.Equal(t, map[string][]string{
+		"Secret default/incomplete": {common.CodeCertificateChainIncomplete},
+		"Secret default/expired":    {common.CodeCertificateExpired},
+		"Secret default/expiring":   {common.CodeCertificateExpiring},
+		"Secret default/invalid":    {common.CodeCertificateInvalid},
+		"Ingress default/web":       {common.CodeCertificateHostMismatch},
+		"Gateway default/public":    {common.CodeCertificateHostMismatch},
+	}, codes)
+
+	for _, result := range results {
+		switch result.Kind + " " + result.Name {
+		case "Ingress default/web":
+			require.Contains(t, result.Error[0].Text, "serves api.example.com")
+			require.Contains(t, result.Error[0].Text, "only valid for web.example.com")
+		case "Secret default/incomplete":
+			require.Contains(t, result.Error[0].Text, "CN=Test Intermediate CA")
+		case "Secret default/expiring":
+			require.Contains(t, result.Error[0].Text, "expires in 9 days")
+		}
+	}
+}
+
+func TestCertificateExpiryWindow(t *testing.T) {
+	expiring := newTestCertificate(t, "soon.example.com", time.Now().AddDate(0, 0, 10), false, nil)
+	certificate := &tlsSecret{secret: *tlsSecretWith("soon", expiring.pem, nil), chain: []*x509.Certificate{expiring.cert}}
+
+	require.Len(t, certificateFailures(certificate, time.Now(), DefaultCertificateExpiryWindow), 1)
+	require.Empty(t, certificateFailures(certificate, time.Now(), 7*24*time.Hour))
+}
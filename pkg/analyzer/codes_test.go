@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAnalyzerFailureCodes(t *testing.T) {
+	deadline := int64(-60)
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&batchv1.CronJob{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backup",
+						Namespace: "default",
+					},
+					Spec: batchv1.CronJobSpec{
+						Schedule:                "*/5 * * * *",
+						StartingDeadlineSeconds: &deadline,
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := CronJobAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	common.AddRemediations(results)
+
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, 1)
+	require.Equal(t, common.CodeCronJobNegativeDeadline, results[0].Error[0].Code)
+	require.Contains(t, results[0].Error[0].Remediation, "startingDeadlineSeconds")
+}
+
+func TestPodFailureCodes(t *testing.T) {
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "web",
+						Namespace: "default",
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodRunning,
+						ContainerStatuses: []v1.ContainerStatus{
+							{
+								Name: "app",
+								State: v1.ContainerState{
+									Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+								},
+								LastTerminationState: v1.ContainerState{
+									Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled"},
+								},
+							},
+							{
+								Name: "sidecar",
+								State: v1.ContainerState{
+									Waiting: &v1.ContainerStateWaiting{
+										Reason:  "ImagePullBackOff",
+										Message: "Back-off pulling image",
+									},
+								},
+							},
+						},
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := PodAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, 2)
+
+	crash := results[0].Error[0]
+	require.Equal(t, common.CodePodCrashLoop, crash.Code)
+	require.Equal(t, "app", crash.Container)
+	require.Equal(t, "OOMKilled", crash.Reason)
+
+	pull := results[0].Error[1]
+	require.Equal(t, common.CodePodImagePullFailed, pull.Code)
+	require.Equal(t, "sidecar", pull.Container)
+	require.Equal(t, "ImagePullBackOff", pull.Reason)
+}
+
+func TestIngressFailureReference(t *testing.T) {
+	className := "nginx"
+	config := common.Analyzer{
+		Client: &kubernetes.Client{
+			Client: fake.NewSimpleClientset(
+				&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: className}},
+				&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "web",
+						Namespace: "default",
+					},
+					Spec: networkingv1.IngressSpec{
+						IngressClassName: &className,
+						TLS:              []networkingv1.IngressTLS{{SecretName: "web-tls"}},
+					},
+				},
+			),
+		},
+		Context:   context.Background(),
+		Namespace: "default",
+	}
+
+	results, err := IngressAnalyzer{}.Analyze(config)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, 1)
+	require.Equal(t, common.CodeIngressTLSSecretNotFound, results[0].Error[0].Code)
+	require.Equal(t, &common.ObjectReference{Kind: "Secret", Namespace: "default", Name: "web-tls"}, results[0].Error[0].Reference)
+}
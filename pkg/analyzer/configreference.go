@@ -0,0 +1,369 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// configReference is a use of a ConfigMap or Secret, or of one of its keys,
+// by a pod spec.
+type configReference struct {
+	kind      string // ConfigMap or Secret
+	name      string
+	key       string // empty if the whole object is used
+	optional  bool
+	container string // empty for volumes
+	usage     string // e.g. "env DB_PASSWORD" or "volume config"
+}
+
+// podConfigReferences returns the ConfigMaps and Secrets used by a pod spec
+// through env, envFrom, volumes and projected volumes.
+func podConfigReferences(spec v1.PodSpec) []configReference {
+	var refs []configReference
+	containers := append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				refs = append(refs, configReference{"ConfigMap", ref.Name, ref.Key, isOptional(ref.Optional), container.Name, "env " + env.Name})
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				refs = append(refs, configReference{"Secret", ref.Name, ref.Key, isOptional(ref.Optional), container.Name, "env " + env.Name})
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if ref := envFrom.ConfigMapRef; ref != nil {
+				refs = append(refs, configReference{"ConfigMap", ref.Name, "", isOptional(ref.Optional), container.Name, "envFrom"})
+			}
+			if ref := envFrom.SecretRef; ref != nil {
+				refs = append(refs, configReference{"Secret", ref.Name, "", isOptional(ref.Optional), container.Name, "envFrom"})
+			}
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		usage := "volume " + volume.Name
+		if cm := volume.ConfigMap; cm != nil {
+			refs = append(refs, volumeReferences("ConfigMap", cm.Name, cm.Items, isOptional(cm.Optional), usage)...)
+		}
+		if secret := volume.Secret; secret != nil {
+			refs = append(refs, volumeReferences("Secret", secret.SecretName, secret.Items, isOptional(secret.Optional), usage)...)
+		}
+		if projected := volume.Projected; projected != nil {
+			for _, source := range projected.Sources {
+				if cm := source.ConfigMap; cm != nil {
+					refs = append(refs, volumeReferences("ConfigMap", cm.Name, cm.Items, isOptional(cm.Optional), usage)...)
+				}
+				if secret := source.Secret; secret != nil {
+					refs = append(refs, volumeReferences("Secret", secret.Name, secret.Items, isOptional(secret.Optional), usage)...)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// volumeReferences returns the use of the object by a volume, and of each of
+// the keys it projects.
+func volumeReferences(kind string, name string, items []v1.KeyToPath, optional bool, usage string) []configReference {
+	refs := []configReference{{kind: kind, name: name, optional: optional, usage: usage}}
+	for _, item := range items {
+		refs = append(refs, configReference{kind: kind, name: name, key: item.Key, optional: optional, usage: usage})
+	}
+	return refs
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// configObjects holds the keys of the ConfigMaps and Secrets of the analyzed
+// namespaces, by kind and namespace/name.
+type configObjects map[string]map[string]map[string]bool
+
+// listConfigObjects returns the ConfigMaps and Secrets of the analyzed
+// namespaces, and those of them that are expected to be used by pods.
+func listConfigObjects(a common.Analyzer) (configObjects, []common.ObjectReference, error) {
+	objects := configObjects{"ConfigMap": {}, "Secret": {}}
+	var usable []common.ObjectReference
+	configMaps, err := a.Client.GetClient().CoreV1().ConfigMaps(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, cm := range configMaps.Items {
+		keys := map[string]bool{}
+		for key := range cm.Data {
+			keys[key] = true
+		}
+		for key := range cm.BinaryData {
+			keys[key] = true
+		}
+		objects["ConfigMap"][cm.Namespace+"/"+cm.Name] = keys
+		// every namespace gets the CA bundle of the API server
+		if cm.Name != "kube-root-ca.crt" && usedByPods(cm.ObjectMeta) {
+			usable = append(usable, common.ObjectReference{Kind: "ConfigMap", Namespace: cm.Namespace, Name: cm.Name})
+		}
+	}
+	secrets, err := a.Client.GetClient().CoreV1().Secrets(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, secret := range secrets.Items {
+		keys := map[string]bool{}
+		for key := range secret.Data {
+			keys[key] = true
+		}
+		for key := range secret.StringData {
+			keys[key] = true
+		}
+		objects["Secret"][secret.Namespace+"/"+secret.Name] = keys
+		if !unusedExemptSecretTypes[secret.Type] && usedByPods(secret.ObjectMeta) {
+			usable = append(usable, common.ObjectReference{Kind: "Secret", Namespace: secret.Namespace, Name: secret.Name})
+		}
+	}
+	return objects, usable, nil
+}
+
+// usedByPods reports whether a ConfigMap or Secret is meant to be used by
+// pods. Objects of the control plane and objects owned by controllers, like
+// certificates, are used by other means.
+func usedByPods(meta metav1.ObjectMeta) bool {
+	return !systemNamespaces[meta.Namespace] && len(meta.OwnerReferences) == 0
+}
+
+// listUsedConfigObjects returns the ConfigMaps and Secrets used by the pods,
+// workloads, ServiceAccounts and Ingresses of the analyzed namespaces, by
+// kind/namespace/name.
+func listUsedConfigObjects(a common.Analyzer, templates []workloadTemplate) (map[string]bool, error) {
+	used := map[string]bool{}
+	use := func(kind string, namespace string, name string) {
+		used[kind+"/"+namespace+"/"+name] = true
+	}
+	usePodSpec := func(namespace string, spec v1.PodSpec) {
+		for _, ref := range podConfigReferences(spec) {
+			use(ref.kind, namespace, ref.name)
+		}
+		for _, secret := range spec.ImagePullSecrets {
+			use("Secret", namespace, secret.Name)
+		}
+	}
+
+	for _, workload := range templates {
+		usePodSpec(workload.meta.Namespace, workload.spec)
+	}
+	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		usePodSpec(pod.Namespace, pod.Spec)
+	}
+	serviceAccounts, err := a.Client.GetClient().CoreV1().ServiceAccounts(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, sa := range serviceAccounts.Items {
+		for _, secret := range sa.Secrets {
+			use("Secret", sa.Namespace, secret.Name)
+		}
+		for _, secret := range sa.ImagePullSecrets {
+			use("Secret", sa.Namespace, secret.Name)
+		}
+	}
+	ingresses, err := a.Client.GetClient().NetworkingV1().Ingresses(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ingress := range ingresses.Items {
+		for _, tls := range ingress.Spec.TLS {
+			use("Secret", ingress.Namespace, tls.SecretName)
+		}
+	}
+	return used, nil
+}
+
+// ConfigReferenceAnalyzer is an analyzer that checks for workloads using
+// ConfigMaps, Secrets or keys of them that do not exist, before their pods
+// fail to start, and for ConfigMaps and Secrets nothing uses. Unused objects
+// are low severity findings, they may be used outside of the cluster.
+type ConfigReferenceAnalyzer struct{}
+
+// Analyze scans the Deployments, StatefulSets, DaemonSets and CronJobs of all
+// namespaces for missing ConfigMaps and Secrets, and all namespaces for
+// unused ones
+func (ConfigReferenceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "ConfigReference"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	objects, usable, err := listConfigObjects(a)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := listWorkloadTemplates(a)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, workload := range templates {
+		// report every missing object or key once, with all its uses
+		var missing []configReference
+		usages := map[configReference][]string{}
+		containers := map[configReference][]string{}
+		for _, ref := range podConfigReferences(workload.spec) {
+			if ref.optional {
+				continue
+			}
+			keys, found := objects[ref.kind][workload.meta.Namespace+"/"+ref.name]
+			if found && (ref.key == "" || keys[ref.key]) {
+				continue
+			}
+			// the keys of a missing object are not reported on their own
+			id := configReference{kind: ref.kind, name: ref.name}
+			if found {
+				id.key = ref.key
+			}
+			if _, seen := usages[id]; !seen {
+				missing = append(missing, id)
+			}
+			usage := ref.usage
+			if ref.container != "" {
+				usage = fmt.Sprintf("container %s %s", ref.container, ref.usage)
+			}
+			usages[id] = appendUnique(usages[id], usage)
+			containers[id] = appendUnique(containers[id], ref.container)
+		}
+
+		var failures []common.Failure
+		for _, ref := range missing {
+			var code, text string
+			if ref.key == "" {
+				code = common.CodeConfigMapNotFound
+				if ref.kind == "Secret" {
+					code = common.CodeSecretNotFound
+				}
+				text = fmt.Sprintf("%s %s/%s uses the %s %s which does not exist", workload.kind, workload.meta.Namespace, workload.meta.Name, ref.kind, ref.name)
+			} else {
+				code = common.CodeConfigMapKeyNotFound
+				if ref.kind == "Secret" {
+					code = common.CodeSecretKeyNotFound
+				}
+				text = fmt.Sprintf("%s %s/%s uses the key %s of the %s %s which does not exist", workload.kind, workload.meta.Namespace, workload.meta.Name, ref.key, ref.kind, ref.name)
+			}
+			failure := common.Failure{
+				Text:      fmt.Sprintf("%s (%s)", text, strings.Join(usages[ref], ", ")),
+				Code:      code,
+				Reference: &common.ObjectReference{Kind: ref.kind, Namespace: workload.meta.Namespace, Name: ref.name},
+				Sensitive: []common.Sensitive{
+					{
+						Unmasked: workload.meta.Namespace,
+						Masked:   util.MaskString(workload.meta.Namespace),
+					},
+					{
+						Unmasked: workload.meta.Name,
+						Masked:   util.MaskString(workload.meta.Name),
+					},
+					{
+						Unmasked: ref.name,
+						Masked:   util.MaskString(ref.name),
+					},
+				},
+			}
+			if len(containers[ref]) == 1 {
+				failure.Container = containers[ref][0]
+			}
+			failures = append(failures, failure)
+		}
+
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  workload.kind,
+				Name:  fmt.Sprintf("%s/%s", workload.meta.Namespace, workload.meta.Name),
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, workload.meta.Name, workload.meta.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	used, err := listUsedConfigObjects(a, templates)
+	if err != nil {
+		return a.Results, err
+	}
+	for _, object := range usable {
+		if used[object.Kind+"/"+object.Namespace+"/"+object.Name] {
+			continue
+		}
+		reference := object
+		a.Results = append(a.Results, common.Result{
+			Kind: kind,
+			Name: fmt.Sprintf("%s/%s", object.Namespace, object.Name),
+			Error: []common.Failure{
+				{
+					Text:      fmt.Sprintf("%s %s/%s is not used by any pod, workload, ServiceAccount or Ingress, this is low severity as it may be used outside of the cluster", object.Kind, object.Namespace, object.Name),
+					Code:      common.CodeConfigReferenceUnused,
+					Reference: &reference,
+					Sensitive: []common.Sensitive{
+						{
+							Unmasked: object.Namespace,
+							Masked:   util.MaskString(object.Namespace),
+						},
+						{
+							Unmasked: object.Name,
+							Masked:   util.MaskString(object.Name),
+						},
+					},
+				},
+			},
+		})
+		AnalyzerErrorsMetric.WithLabelValues(kind, object.Name, object.Namespace).Set(1)
+	}
+
+	return a.Results, nil
+}
+
+// systemNamespaces hold ConfigMaps and Secrets used by the control plane,
+// which are not referenced by pods.
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// unusedExemptSecretTypes are Secret types used by other means than pods.
+var unusedExemptSecretTypes = map[v1.SecretType]bool{
+	v1.SecretTypeServiceAccountToken: true,
+	v1.SecretTypeBootstrapToken:      true,
+	"helm.sh/release.v1":             true,
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
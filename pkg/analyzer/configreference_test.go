@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigReferenceAnalyzer(t *testing.T) {
+	optional := true
+	clientset := fake.NewSimpleClientset(
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"},
+			Data:       map[string]string{"log-level": "info"},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		},
+		// The faulty-deployment-secret case of test.yaml.
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "faulty-deployment-secret", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{
+					Name: "faulty-container",
+					Env: []v1.EnvVar{{
+						Name: "MANDATORY_ENV",
+						ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "non-existent-secret"},
+							Key:                  "some-key",
+						}},
+					}},
+				}},
+			}}},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec: appsv1.StatefulSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{
+					Name: "postgres",
+					Env: []v1.EnvVar{
+						{
+							Name: "PASSWORD",
+							ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+								LocalObjectReference: v1.LocalObjectReference{Name: "db"},
+								Key:                  "password",
+							}},
+						},
+						{
+							Name: "USER",
+							ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+								LocalObjectReference: v1.LocalObjectReference{Name: "db"},
+								Key:                  "user",
+							}},
+						},
+						{
+							Name: "LOG_LEVEL",
+							ValueFrom: &v1.EnvVarSource{ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+								LocalObjectReference: v1.LocalObjectReference{Name: "settings"},
+								Key:                  "log-level",
+							}},
+						},
+					},
+					EnvFrom: []v1.EnvFromSource{
+						{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "extra"}, Optional: &optional}},
+					},
+				}},
+			}}},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+			Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{
+					Name:    "agent",
+					EnvFrom: []v1.EnvFromSource{{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "agent-token"}}}},
+				}},
+				Volumes: []v1.Volume{{
+					Name: "config",
+					VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{
+						{ConfigMap: &v1.ConfigMapProjection{
+							LocalObjectReference: v1.LocalObjectReference{Name: "settings"},
+							Items:                []v1.KeyToPath{{Key: "agent.yaml", Path: "agent.yaml"}},
+						}},
+						{Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "agent-token"}}},
+					}}},
+				}},
+			}}},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"},
+			Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Volumes: []v1.Volume{{
+					Name:         "scripts",
+					VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: "backup-scripts"}}},
+				}},
+			}}}}},
+		},
+	)
+
+	results, err := ConfigReferenceAnalyzer{}.Analyze(common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset},
+		Context:   context.Background(),
+		Namespace: "default",
+	})
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Kind < results[j].Kind
+	})
+
+	type failure struct {
+		code      string
+		reference string
+		container string
+	}
+	expectations := []struct {
+		kind     string
+		name     string
+		failures []failure
+	}{
+		{"CronJob", "default/backup", []failure{{common.CodeConfigMapNotFound, "backup-scripts", ""}}},
+		{"DaemonSet", "default/agent", []failure{
+			{common.CodeSecretNotFound, "agent-token", ""},
+			{common.CodeConfigMapKeyNotFound, "settings", ""},
+		}},
+		{"Deployment", "default/faulty-deployment-secret", []failure{{common.CodeSecretNotFound, "non-existent-secret", "faulty-container"}}},
+		{"StatefulSet", "default/db", []failure{{common.CodeSecretKeyNotFound, "db", "postgres"}}},
+	}
+	require.Len(t, results, len(expectations))
+	for i, expected := range expectations {
+		require.Equal(t, expected.kind, results[i].Kind)
+		require.Equal(t, expected.name, results[i].Name)
+		var failures []failure
+		for _, f := range results[i].Error {
+			failures = append(failures, failure{f.Code, f.Reference.Name, f.Container})
+		}
+		require.Equal(t, expected.failures, failures)
+	}
+	require.Contains(t, results[1].Error[0].Text, "container agent envFrom, volume config")
+	require.Contains(t, results[3].Error[0].Text, "key user")
+}
+
+func TestConfigReferenceAnalyzerUnused(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "default"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "leftover", Namespace: "default"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cluster-info", Namespace: "kube-system"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "default"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "default"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "old-password", Namespace: "default"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "sa-token", Namespace: "default"}, Type: v1.SecretTypeServiceAccountToken},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name: "issued", Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Certificate", Name: "issued"}},
+		}},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1.PodSpec{Volumes: []v1.Volume{{
+				Name:         "settings",
+				VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: "settings"}}},
+			}}},
+		},
+		&v1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "registry"}},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{{SecretName: "web-tls"}}},
+		},
+	)
+
+	results, err := ConfigReferenceAnalyzer{}.Analyze(common.Analyzer{
+		Client:  &kubernetes.Client{Client: clientset},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+
+	var found []string
+	for _, result := range results {
+		require.Equal(t, "ConfigReference", result.Kind)
+		require.Len(t, result.Error, 1)
+		require.Equal(t, common.CodeConfigReferenceUnused, result.Error[0].Code)
+		found = append(found, result.Error[0].Reference.Kind+" "+result.Name)
+	}
+	require.ElementsMatch(t, []string{"ConfigMap default/leftover", "Secret default/old-password"}, found)
+}
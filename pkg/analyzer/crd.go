@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"k8s.io/apiextensions-apiserver/pkg/apihelpers"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CustomResourceDefinitionAnalyzer is an analyzer that checks for CRDs whose
+// names conflict and for conversion webhooks without a working backend
+type CustomResourceDefinitionAnalyzer struct{}
+
+// Analyze scans the CustomResourceDefinitions of the cluster
+func (CustomResourceDefinitionAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "CustomResourceDefinition"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "apiextensions.k8s.io",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	client := a.Client.CtrlClient
+	if client == nil {
+		return nil, nil
+	}
+	if err := apiextensionsv1.AddToScheme(client.Scheme()); err != nil {
+		return nil, err
+	}
+	crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := client.List(a.Context, crdList); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for i := range crdList.Items {
+		crd := &crdList.Items[i]
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: crd.Name,
+				Masked:   util.MaskString(crd.Name),
+			},
+		}
+
+		if condition := apihelpers.FindCRDCondition(crd, apiextensionsv1.NamesAccepted); condition != nil && condition.Status == apiextensionsv1.ConditionFalse {
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("The names of CustomResourceDefinition %s are not accepted, its resources are not served: %s", crd.Name, condition.Message),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.names"),
+				Code:          common.CodeCRDNamesNotAccepted,
+				Reason:        condition.Reason,
+				Sensitive:     sensitive,
+			})
+		} else if condition := apihelpers.FindCRDCondition(crd, apiextensionsv1.Established); condition != nil && condition.Status == apiextensionsv1.ConditionFalse {
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("CustomResourceDefinition %s is not established, its resources are not served: %s", crd.Name, condition.Message),
+				Code:      common.CodeCRDNotEstablished,
+				Reason:    condition.Reason,
+				Sensitive: sensitive,
+			})
+		}
+
+		if conversion := crd.Spec.Conversion; conversion != nil && conversion.Strategy == apiextensionsv1.WebhookConverter &&
+			conversion.Webhook != nil && conversion.Webhook.ClientConfig != nil && conversion.Webhook.ClientConfig.Service != nil {
+			svc := conversion.Webhook.ClientConfig.Service
+			backend, err := getServiceBackend(a, svc.Namespace, svc.Name)
+			if err != nil {
+				return nil, err
+			}
+			reference := &common.ObjectReference{Kind: "Service", Namespace: svc.Namespace, Name: svc.Name}
+			sensitive := append(sensitive, common.Sensitive{
+				Unmasked: svc.Name,
+				Masked:   util.MaskString(svc.Name),
+			})
+			doc := apiDoc.GetApiDocV2("spec.conversion.webhook.clientConfig.service")
+			switch {
+			case !backend.Found:
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("Service %s/%s of the conversion webhook of CustomResourceDefinition %s not found, objects stored in other versions cannot be read", svc.Namespace, svc.Name, crd.Name),
+					KubernetesDoc: doc,
+					Code:          common.CodeCRDConversionServiceNotFound,
+					Reference:     reference,
+					Sensitive:     sensitive,
+				})
+			case backend.Selected && len(backend.Pods) == 0:
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("No active pods found within service %s/%s of the conversion webhook of CustomResourceDefinition %s", svc.Namespace, svc.Name, crd.Name),
+					KubernetesDoc: doc,
+					Code:          common.CodeCRDConversionNoActivePods,
+					Reference:     reference,
+					Sensitive:     sensitive,
+				})
+			}
+			for _, pod := range backend.inactivePods() {
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("The conversion webhook of CustomResourceDefinition %s is pointing to the inactive pod %s/%s", crd.Name, pod.Namespace, pod.Name),
+					KubernetesDoc: doc,
+					Code:          common.CodeCRDConversionInactivePod,
+					Reference:     &common.ObjectReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name},
+					Sensitive: append(sensitive, common.Sensitive{
+						Unmasked: pod.Name,
+						Masked:   util.MaskString(pod.Name),
+					}),
+				})
+			}
+		}
+
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  kind,
+				Name:  crd.Name,
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, crd.Name, "").Set(float64(len(failures)))
+		}
+	}
+
+	return a.Results, nil
+}
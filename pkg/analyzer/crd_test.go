@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCustomResourceDefinitionAnalyzer(t *testing.T) {
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme.Scheme))
+	conversion := &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{Namespace: "operators", Name: "webhook"},
+			},
+		},
+	}
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "operators"},
+		Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "operator"}},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator-1", Namespace: "operators", Labels: map[string]string{"app": "operator"}},
+		Status:     v1.PodStatus{Phase: v1.PodFailed},
+	}
+
+	tests := []struct {
+		name       string
+		conditions []apiextensionsv1.CustomResourceDefinitionCondition
+		conversion *apiextensionsv1.CustomResourceConversion
+		objects    []runtime.Object
+		wantCodes  []string
+	}{
+		{
+			name: "established",
+			conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionTrue},
+				{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+			},
+		},
+		{
+			name: "names not accepted",
+			conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionFalse, Reason: "ListKindConflict", Message: "\"WidgetList\" is already in use"},
+				{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionFalse, Reason: "NotAccepted"},
+			},
+			wantCodes: []string{common.CodeCRDNamesNotAccepted},
+		},
+		{
+			name: "not established",
+			conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionFalse, Reason: "Installing"},
+			},
+			wantCodes: []string{common.CodeCRDNotEstablished},
+		},
+		{
+			name:       "conversion service not found",
+			conversion: conversion,
+			wantCodes:  []string{common.CodeCRDConversionServiceNotFound},
+		},
+		{
+			name:       "conversion webhook without pods",
+			conversion: conversion,
+			objects:    []runtime.Object{service},
+			wantCodes:  []string{common.CodeCRDConversionNoActivePods},
+		},
+		{
+			name:       "conversion webhook with an inactive pod",
+			conversion: conversion,
+			objects:    []runtime.Object{service, pod},
+			wantCodes:  []string{common.CodeCRDConversionInactivePod},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			crd := &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"},
+				Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "example.com", Conversion: tt.conversion},
+				Status:     apiextensionsv1.CustomResourceDefinitionStatus{Conditions: tt.conditions},
+			}
+			ctrlClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(crd).Build()
+
+			results, err := CustomResourceDefinitionAnalyzer{}.Analyze(common.Analyzer{
+				Client:  &kubernetes.Client{Client: fake.NewSimpleClientset(tt.objects...), CtrlClient: ctrlClient},
+				Context: context.Background(),
+			})
+			require.NoError(t, err)
+			if len(tt.wantCodes) == 0 {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			require.Equal(t, "widgets.example.com", results[0].Name)
+			var codes []string
+			for _, failure := range results[0].Error {
+				codes = append(codes, failure.Code)
+			}
+			require.Equal(t, tt.wantCodes, codes)
+		})
+	}
+}
@@ -14,17 +14,24 @@ limitations under the License.
 package analyzer
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	cron "github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// missedScheduleGrace is how late a run may start before it counts as missed,
+// unless the CronJob sets a starting deadline.
+const missedScheduleGrace = 5 * time.Minute
+
 type CronJobAnalyzer struct{}
 
 func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
@@ -48,6 +55,21 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 		return nil, err
 	}
 
+	jobList, err := a.Client.GetClient().BatchV1().Jobs(a.Namespace).List(a.Context, v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// the Jobs spawned by each CronJob, by namespace and name of the CronJob
+	jobs := map[string][]batchv1.Job{}
+	for _, job := range jobList.Items {
+		for _, owner := range job.OwnerReferences {
+			if owner.Kind == "CronJob" {
+				key := fmt.Sprintf("%s/%s", job.Namespace, owner.Name)
+				jobs[key] = append(jobs[key], job)
+			}
+		}
+	}
+
 	var preAnalysis = map[string]common.PreAnalysis{}
 
 	for _, cronJob := range cronJobList.Items {
@@ -58,6 +80,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 			failures = append(failures, common.Failure{
 				Text:          fmt.Sprintf("CronJob %s is suspended", cronJob.Name),
 				KubernetesDoc: doc,
+				Code:          common.CodeCronJobSuspended,
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: cronJob.Namespace,
@@ -77,6 +100,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("CronJob %s has an invalid schedule: %s", cronJob.Name, err.Error()),
 					KubernetesDoc: doc,
+					Code:          common.CodeCronJobInvalidSchedule,
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: cronJob.Namespace,
@@ -99,6 +123,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 					failures = append(failures, common.Failure{
 						Text:          fmt.Sprintf("CronJob %s has a negative starting deadline", cronJob.Name),
 						KubernetesDoc: doc,
+						Code:          common.CodeCronJobNegativeDeadline,
 						Sensitive: []common.Sensitive{
 							{
 								Unmasked: cronJob.Namespace,
@@ -114,6 +139,52 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 				}
 			}
 
+			// check the runs of the CronJob
+			if failed, latest := consecutiveFailedRuns(cronJob, jobs[fmt.Sprintf("%s/%s", cronJob.Namespace, cronJob.Name)]); failed > 0 {
+				doc := apiDoc.GetApiDocV2("spec.failedJobsHistoryLimit")
+
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("CronJob %s failed its last %d runs, the latest is Job %s", cronJob.Name, failed, latest),
+					KubernetesDoc: doc,
+					Code:          common.CodeCronJobConsecutiveFailures,
+					Reference:     &common.ObjectReference{Kind: "Job", Namespace: cronJob.Namespace, Name: latest},
+					Sensitive: []common.Sensitive{
+						{
+							Unmasked: cronJob.Namespace,
+							Masked:   util.MaskString(cronJob.Namespace),
+						},
+						{
+							Unmasked: cronJob.Name,
+							Masked:   util.MaskString(cronJob.Name),
+						},
+						{
+							Unmasked: latest,
+							Masked:   util.MaskString(latest),
+						},
+					},
+				})
+			}
+			if missed, since := missedSchedules(cronJob, time.Now()); missed > 0 {
+				text := fmt.Sprintf("CronJob %s missed %d scheduled runs since %s", cronJob.Name, missed, since.Format(time.RFC3339))
+				if cronJob.Spec.ConcurrencyPolicy == batchv1.ForbidConcurrent && len(cronJob.Status.Active) > 0 {
+					text += ", a previous run is still active and the concurrency policy is Forbid"
+				}
+				failures = append(failures, common.Failure{
+					Text:          text,
+					KubernetesDoc: apiDoc.GetApiDocV2("spec.startingDeadlineSeconds"),
+					Code:          common.CodeCronJobMissedSchedules,
+					Sensitive: []common.Sensitive{
+						{
+							Unmasked: cronJob.Namespace,
+							Masked:   util.MaskString(cronJob.Namespace),
+						},
+						{
+							Unmasked: cronJob.Name,
+							Masked:   util.MaskString(cronJob.Name),
+						},
+					},
+				})
+			}
 		}
 
 		if len(failures) > 0 {
@@ -137,6 +208,108 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 	return a.Results, nil
 }
 
+// consecutiveFailedRuns returns the number of most recent runs of a CronJob
+// that failed and the name of the latest of them. A single failure is only
+// reported when the CronJob keeps no more than one failed Job.
+func consecutiveFailedRuns(cronJob batchv1.CronJob, jobs []batchv1.Job) (int, string) {
+	var finished []batchv1.Job
+	for _, job := range jobs {
+		if jobFinished(job) {
+			finished = append(finished, job)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[j].CreationTimestamp.Before(&finished[i].CreationTimestamp)
+	})
+
+	failed := 0
+	for _, job := range finished {
+		if jobCondition(job, batchv1.JobFailed) == nil {
+			break
+		}
+		failed++
+	}
+
+	threshold := 2
+	if limit := cronJob.Spec.FailedJobsHistoryLimit; limit == nil || *limit < 2 {
+		threshold = 1
+	}
+	if failed < threshold {
+		return 0, ""
+	}
+	return failed, finished[0].Name
+}
+
+// missedSchedules returns the number of scheduled runs of a CronJob that did
+// not start in time, and the time they are counted from. Runs skipped while
+// the CronJob was suspended are not missed, so counting starts no earlier
+// than the last change of spec.suspend.
+func missedSchedules(cronJob batchv1.CronJob, now time.Time) (int, time.Time) {
+	since := cronJob.CreationTimestamp.Time
+	if cronJob.Status.LastScheduleTime != nil {
+		since = cronJob.Status.LastScheduleTime.Time
+	}
+	suspendChanged, ok := suspendChangedAt(cronJob)
+	if !ok {
+		return 0, since
+	}
+	if suspendChanged.After(since) {
+		since = suspendChanged
+	}
+	if since.IsZero() {
+		return 0, since
+	}
+	spec := cronJob.Spec.Schedule
+	if cronJob.Spec.TimeZone != nil {
+		spec = fmt.Sprintf("CRON_TZ=%s %s", *cronJob.Spec.TimeZone, spec)
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return 0, since
+	}
+	grace := missedScheduleGrace
+	if deadline := cronJob.Spec.StartingDeadlineSeconds; deadline != nil && *deadline > 0 {
+		grace = time.Duration(*deadline) * time.Second
+	}
+
+	missed := 0
+	// stop counting at 100, a CronJob missing that many runs is clearly broken
+	for next := schedule.Next(since); !next.IsZero() && next.Add(grace).Before(now) && missed < 100; next = schedule.Next(next) {
+		missed++
+	}
+	return missed, since
+}
+
+// suspendChangedAt returns the latest time a field manager of spec.suspend
+// wrote the CronJob, which is no earlier than the CronJob was last unsuspended.
+// It reports false when spec.suspend is managed but that time is unknown.
+// Without managed fields there is no record of a suspension and the zero time
+// is returned.
+func suspendChangedAt(cronJob batchv1.CronJob) (time.Time, bool) {
+	var changed time.Time
+	for _, entry := range cronJob.ManagedFields {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		var fields struct {
+			Spec map[string]json.RawMessage `json:"f:spec"`
+		}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		if _, ok := fields.Spec["f:suspend"]; !ok {
+			continue
+		}
+		if entry.Time == nil {
+			return time.Time{}, false
+		}
+		if entry.Time.After(changed) {
+			changed = entry.Time.Time
+		}
+	}
+	return changed, true
+}
+
 // Check CRON schedule format
 func CheckCronScheduleIsValid(schedule string) (bool, error) {
 	_, err := cron.ParseStandard(schedule)
@@ -17,6 +17,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
@@ -156,3 +157,155 @@ func TestCronJobAnalyzer(t *testing.T) {
 		require.Equal(t, expectations[i], result.Name)
 	}
 }
+
+func TestCronJobAnalyzerRuns(t *testing.T) {
+	failedJob := func(name string, created time.Time) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(created),
+				OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "failing"}},
+			},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Reason: "BackoffLimitExceeded"}},
+			},
+		}
+	}
+	historyLimit := int32(3)
+	now := time.Now()
+	lastSchedule := metav1.NewTime(now.Add(-3*time.Hour - 30*time.Minute))
+	// the latest hourly run, the next one is not due yet
+	recentSchedule := metav1.NewTime(now.Truncate(time.Hour))
+	utc := "Etc/UTC"
+
+	clientset := fake.NewSimpleClientset(
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "failing", Namespace: "default"},
+			Spec: batchv1.CronJobSpec{
+				Schedule:               "0 0 1 1 *",
+				FailedJobsHistoryLimit: &historyLimit,
+			},
+		},
+		failedJob("failing-1", now.Add(-3*time.Hour)),
+		failedJob("failing-2", now.Add(-2*time.Hour)),
+		failedJob("failing-3", now.Add(-time.Hour)),
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "default"},
+			Spec: batchv1.CronJobSpec{
+				Schedule:          "0 * * * *",
+				ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			},
+			Status: batchv1.CronJobStatus{
+				LastScheduleTime: &lastSchedule,
+				Active:           []v1.ObjectReference{{Kind: "Job", Name: "missing-1"}},
+			},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "on-time", Namespace: "default"},
+			Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *", TimeZone: &utc},
+			Status:     batchv1.CronJobStatus{LastScheduleTime: &recentSchedule},
+		},
+	)
+
+	results, err := CronJobAnalyzer{}.Analyze(common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset},
+		Context:   context.Background(),
+		Namespace: "default",
+	})
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	require.Len(t, results, 2)
+	require.Equal(t, "default/failing", results[0].Name)
+	require.Equal(t, common.CodeCronJobConsecutiveFailures, results[0].Error[0].Code)
+	require.Contains(t, results[0].Error[0].Text, "last 3 runs")
+	require.Equal(t, "failing-3", results[0].Error[0].Reference.Name)
+
+	require.Equal(t, "default/missing", results[1].Name)
+	require.Equal(t, common.CodeCronJobMissedSchedules, results[1].Error[0].Code)
+	require.Contains(t, results[1].Error[0].Text, "Forbid")
+}
+
+func TestMissedSchedules(t *testing.T) {
+	deadline := int64(7200)
+	lastSchedule := metav1.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	unsuspended := metav1.NewTime(time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC))
+	now := time.Date(2024, 5, 1, 14, 3, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cronJob batchv1.CronJob
+		missed  int
+	}{
+		{
+			name:    "never scheduled",
+			cronJob: batchv1.CronJob{Spec: batchv1.CronJobSpec{Schedule: "0 * * * *"}},
+		},
+		{
+			name: "hourly",
+			cronJob: batchv1.CronJob{
+				Spec:   batchv1.CronJobSpec{Schedule: "0 * * * *"},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &lastSchedule},
+			},
+			// 11:00, 12:00 and 13:00; 14:00 may still start
+			missed: 3,
+		},
+		{
+			name: "starting deadline",
+			cronJob: batchv1.CronJob{
+				Spec:   batchv1.CronJobSpec{Schedule: "0 * * * *", StartingDeadlineSeconds: &deadline},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &lastSchedule},
+			},
+			missed: 2,
+		},
+		{
+			name: "unsuspended",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+					{
+						Manager:  "kubectl-create",
+						Time:     &lastSchedule,
+						FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:schedule":{}}}`)},
+					},
+					{
+						Manager:  "kubectl-patch",
+						Time:     &unsuspended,
+						FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:suspend":{}}}`)},
+					},
+				}},
+				Spec:   batchv1.CronJobSpec{Schedule: "0 * * * *"},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &lastSchedule},
+			},
+			// 13:00 only, the earlier runs were skipped while suspended
+			missed: 1,
+		},
+		{
+			name: "suspended at an unknown time",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+					{
+						Manager:  "kubectl-patch",
+						FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:suspend":{}}}`)},
+					},
+				}},
+				Spec:   batchv1.CronJobSpec{Schedule: "0 * * * *"},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &lastSchedule},
+			},
+		},
+		{
+			name: "created recently",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-30 * time.Minute))},
+				Spec:       batchv1.CronJobSpec{Schedule: "0 0 * * *"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			missed, _ := missedSchedules(tt.cronJob, now)
+			require.Equal(t, tt.missed, missed)
+		})
+	}
+}
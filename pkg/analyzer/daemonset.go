@@ -0,0 +1,269 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// daemonSetTolerations are added to every DaemonSet pod by the DaemonSet
+// controller, so these taints never keep a DaemonSet off a node.
+var daemonSetTolerations = []v1.Toleration{
+	{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+	{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+	{Key: v1.TaintNodeDiskPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	{Key: v1.TaintNodeMemoryPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	{Key: v1.TaintNodePIDPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	{Key: v1.TaintNodeUnschedulable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+}
+
+// DaemonSetAnalyzer is an analyzer that checks for DaemonSets whose pods are
+// not running on all the nodes they should
+type DaemonSetAnalyzer struct{}
+
+// Analyze scans all namespaces for DaemonSets with missing, unready or
+// outdated pods
+func (DaemonSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "DaemonSet"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "apps",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().AppsV1().DaemonSets(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	nodes, err := a.Client.GetClient().CoreV1().Nodes().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var preAnalysis = map[string]common.PreAnalysis{}
+
+	for _, ds := range list.Items {
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: ds.Namespace,
+				Masked:   util.MaskString(ds.Namespace),
+			},
+			{
+				Unmasked: ds.Name,
+				Masked:   util.MaskString(ds.Name),
+			},
+		}
+		status := ds.Status
+		desired := status.DesiredNumberScheduled
+
+		// a rolling update takes up to maxUnavailable pods down on purpose, so
+		// until every pod is updated only a larger shortfall is reported
+		rollingOut := ds.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType && status.UpdatedNumberScheduled < desired
+		budget := daemonSetMaxUnavailable(ds)
+		if status.NumberReady < desired && (!rollingOut || int(desired-status.NumberReady) > budget) {
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("DaemonSet %s/%s has %d of %d pods ready", ds.Namespace, ds.Name, status.NumberReady, desired),
+				Code:      common.CodeDaemonSetPodsNotReady,
+				Sensitive: sensitive,
+			})
+		} else if status.NumberAvailable < desired && (!rollingOut || int(desired-status.NumberAvailable) > budget) {
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("DaemonSet %s/%s has %d of %d pods available, ready pods become available after %d seconds", ds.Namespace, ds.Name, status.NumberAvailable, desired, ds.Spec.MinReadySeconds),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.minReadySeconds"),
+				Code:          common.CodeDaemonSetPodsUnavailable,
+				Sensitive:     sensitive,
+			})
+		}
+
+		if status.NumberMisscheduled > 0 {
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("DaemonSet %s/%s has %d pods running on nodes they should not run on", ds.Namespace, ds.Name, status.NumberMisscheduled),
+				Code:      common.CodeDaemonSetPodsMisscheduled,
+				Sensitive: sensitive,
+			})
+		}
+
+		if updated, maxUnavailable, stalled := daemonSetRolloutStalled(ds); stalled {
+			failures = append(failures, common.Failure{
+				Text: fmt.Sprintf("DaemonSet %s/%s rollout is stalled: %d of %d pods are updated and %d unavailable pods exhaust maxUnavailable of %d",
+					ds.Namespace, ds.Name, updated, desired, status.NumberUnavailable, maxUnavailable),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.updateStrategy.rollingUpdate.maxUnavailable"),
+				Code:          common.CodeDaemonSetRolloutStalled,
+				Sensitive:     sensitive,
+			})
+		}
+
+		// nodes the DaemonSet is kept off are only reported when it runs on
+		// no node at all, staying off e.g. control plane nodes is intended
+		if desired == 0 && len(nodes.Items) > 0 {
+			selected, tainted := daemonSetExcludedNodes(ds, nodes.Items)
+			if selected == 0 {
+				matchers := fmt.Sprintf("node selector %s", labels.SelectorFromSet(ds.Spec.Template.Spec.NodeSelector))
+				if daemonSetRequiredNodeAffinity(ds) != nil {
+					matchers += " and required node affinity"
+				}
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("DaemonSet %s/%s runs on no node, none of the %d nodes matches its %s", ds.Namespace, ds.Name, len(nodes.Items), matchers),
+					KubernetesDoc: apiDoc.GetApiDocV2("spec.template.spec.nodeSelector"),
+					Code:          common.CodeDaemonSetNoMatchingNodes,
+					Sensitive:     sensitive,
+				})
+			} else if len(tainted) == selected {
+				taintedSensitive := sensitive
+				var excluded []string
+				for _, node := range tainted {
+					excluded = append(excluded, fmt.Sprintf("%s (%s)", node.name, node.taint.ToString()))
+					taintedSensitive = append(taintedSensitive, common.Sensitive{
+						Unmasked: node.name,
+						Masked:   util.MaskString(node.name),
+					})
+				}
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("DaemonSet %s/%s runs on no node, all %d nodes it selects have taints it does not tolerate: %s", ds.Namespace, ds.Name, len(tainted), strings.Join(excluded, ", ")),
+					KubernetesDoc: apiDoc.GetApiDocV2("spec.template.spec.tolerations"),
+					Code:          common.CodeDaemonSetNodesTainted,
+					Sensitive:     taintedSensitive,
+				})
+			}
+		}
+
+		if len(failures) > 0 {
+			preAnalysis[fmt.Sprintf("%s/%s", ds.Namespace, ds.Name)] = common.PreAnalysis{
+				DaemonSet:      ds,
+				FailureDetails: failures,
+			}
+			AnalyzerErrorsMetric.WithLabelValues(kind, ds.Name, ds.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	for key, value := range preAnalysis {
+		var currentAnalysis = common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		}
+
+		parent, found := util.GetParent(a.Client, value.DaemonSet.ObjectMeta)
+		if found {
+			currentAnalysis.ParentObject = parent
+		}
+		a.Results = append(a.Results, currentAnalysis)
+	}
+
+	return a.Results, nil
+}
+
+// daemonSetRolloutStalled reports whether a rolling update cannot make
+// progress because the unavailable pods use up its maxUnavailable budget.
+func daemonSetRolloutStalled(ds appsv1.DaemonSet) (int32, int, bool) {
+	status := ds.Status
+	if ds.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType ||
+		status.UpdatedNumberScheduled >= status.DesiredNumberScheduled || status.NumberUnavailable == 0 {
+		return status.UpdatedNumberScheduled, 0, false
+	}
+	budget := daemonSetMaxUnavailable(ds)
+	return status.UpdatedNumberScheduled, budget, int(status.NumberUnavailable) >= budget
+}
+
+// daemonSetMaxUnavailable returns the number of pods a rolling update of the
+// DaemonSet may take down at once.
+func daemonSetMaxUnavailable(ds appsv1.DaemonSet) int {
+	maxUnavailable := intstr.FromInt(1)
+	if rollingUpdate := ds.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.MaxUnavailable != nil {
+		maxUnavailable = *rollingUpdate.MaxUnavailable
+	}
+	budget, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailable, int(ds.Status.DesiredNumberScheduled), true)
+	// with maxUnavailable 0 the rollout surges, and still waits for every
+	// new pod to become available
+	if err != nil || budget < 1 {
+		return 1
+	}
+	return budget
+}
+
+type taintedNode struct {
+	name  string
+	taint v1.Taint
+}
+
+// daemonSetExcludedNodes returns the number of nodes matching the node
+// selector and required node affinity of a DaemonSet, and those of them it is
+// kept off by a taint.
+func daemonSetExcludedNodes(ds appsv1.DaemonSet, nodes []v1.Node) (int, []taintedNode) {
+	selector := labels.SelectorFromSet(ds.Spec.Template.Spec.NodeSelector)
+	affinity := daemonSetRequiredNodeAffinity(ds)
+	tolerations := append(append([]v1.Toleration{}, ds.Spec.Template.Spec.Tolerations...), daemonSetTolerations...)
+	if ds.Spec.Template.Spec.HostNetwork {
+		tolerations = append(tolerations, v1.Toleration{Key: v1.TaintNodeNetworkUnavailable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule})
+	}
+
+	selected := 0
+	var tainted []taintedNode
+	for _, node := range nodes {
+		if !selector.Matches(labels.Set(node.Labels)) || (affinity != nil && !nodeSelectorMatches(node, affinity)) {
+			continue
+		}
+		selected++
+		for _, taint := range node.Spec.Taints {
+			taint := taint
+			if taint.Effect == v1.TaintEffectPreferNoSchedule || tolerated(tolerations, &taint) {
+				continue
+			}
+			tainted = append(tainted, taintedNode{name: node.Name, taint: taint})
+			break
+		}
+	}
+	return selected, tainted
+}
+
+// daemonSetRequiredNodeAffinity returns the node affinity the nodes of a
+// DaemonSet must satisfy, if it has one.
+func daemonSetRequiredNodeAffinity(ds appsv1.DaemonSet) *v1.NodeSelector {
+	affinity := ds.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return nil
+	}
+	return affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+}
+
+func tolerated(tolerations []v1.Toleration, taint *v1.Taint) bool {
+	for _, toleration := range tolerations {
+		if toleration.ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
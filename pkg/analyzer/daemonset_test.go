@@ -0,0 +1,248 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDaemonSetAnalyzer(t *testing.T) {
+	maxUnavailable := intstr.FromString("50%")
+	nodes := []runtime.Object{
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"role": "worker"}}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{"role": "worker"}}},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu-1", Labels: map[string]string{"role": "gpu"}},
+			Spec: v1.NodeSpec{Taints: []v1.Taint{
+				{Key: "nvidia.com/gpu", Value: "true", Effect: v1.TaintEffectNoSchedule},
+			}},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-3", Labels: map[string]string{"role": "worker"}},
+			Spec: v1.NodeSpec{Taints: []v1.Taint{
+				{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule},
+				{Key: "prefer", Effect: v1.TaintEffectPreferNoSchedule},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		daemonSet appsv1.DaemonSet
+		wantCodes []string
+	}{
+		{
+			name: "healthy",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+					Tolerations: []v1.Toleration{{Operator: v1.TolerationOpExists}},
+				}}},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 4, NumberReady: 4, NumberAvailable: 4, UpdatedNumberScheduled: 4},
+			},
+		},
+		{
+			name: "not ready and misscheduled",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+					NodeSelector: map[string]string{"role": "worker"},
+				}}},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2, NumberAvailable: 2, UpdatedNumberScheduled: 3, NumberMisscheduled: 1},
+			},
+			wantCodes: []string{common.CodeDaemonSetPodsNotReady, common.CodeDaemonSetPodsMisscheduled},
+		},
+		{
+			name: "unavailable",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{
+					MinReadySeconds: 30,
+					Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+						NodeSelector: map[string]string{"role": "worker"},
+					}},
+				},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3, NumberAvailable: 2, UpdatedNumberScheduled: 3},
+			},
+			wantCodes: []string{common.CodeDaemonSetPodsUnavailable},
+		},
+		{
+			name: "rollout stalled",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{
+					UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+					},
+					Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+						NodeSelector: map[string]string{"role": "worker"},
+					}},
+				},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 1, NumberAvailable: 1, UpdatedNumberScheduled: 2, NumberUnavailable: 2},
+			},
+			wantCodes: []string{common.CodeDaemonSetRolloutStalled},
+		},
+		{
+			name: "rolling update within maxUnavailable",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{
+					UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+					},
+					Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+						NodeSelector: map[string]string{"role": "worker"},
+					}},
+				},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2, NumberAvailable: 2, UpdatedNumberScheduled: 1, NumberUnavailable: 1},
+			},
+		},
+		{
+			name: "rolling update beyond maxUnavailable",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{
+					UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+					},
+					Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+						NodeSelector: map[string]string{"role": "worker"},
+					}},
+				},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 0, NumberAvailable: 0, UpdatedNumberScheduled: 2, NumberUnavailable: 3},
+			},
+			wantCodes: []string{common.CodeDaemonSetPodsNotReady, common.CodeDaemonSetRolloutStalled},
+		},
+		{
+			name: "on delete is never stalled",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{
+					UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+					Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+						NodeSelector: map[string]string{"role": "worker"},
+					}},
+				},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3, UpdatedNumberScheduled: 1, NumberUnavailable: 1},
+			},
+		},
+		{
+			name: "tainted node",
+			daemonSet: appsv1.DaemonSet{
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3, UpdatedNumberScheduled: 3},
+			},
+		},
+		{
+			name: "all selected nodes tainted",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+					NodeSelector: map[string]string{"role": "gpu"},
+				}}},
+			},
+			wantCodes: []string{common.CodeDaemonSetNodesTainted},
+		},
+		{
+			name: "no matching nodes",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+					NodeSelector: map[string]string{"role": "storage"},
+				}}},
+			},
+			wantCodes: []string{common.CodeDaemonSetNoMatchingNodes},
+		},
+		{
+			name: "node affinity matching no node",
+			daemonSet: appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+					Affinity: &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{
+							MatchExpressions: []v1.NodeSelectorRequirement{{Key: "role", Operator: v1.NodeSelectorOpIn, Values: []string{"storage", "edge"}}},
+						}}},
+					}},
+				}}},
+			},
+			wantCodes: []string{common.CodeDaemonSetNoMatchingNodes},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.daemonSet.ObjectMeta = metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"}
+			clientset := fake.NewSimpleClientset(append([]runtime.Object{&tt.daemonSet}, nodes...)...)
+
+			results, err := DaemonSetAnalyzer{}.Analyze(common.Analyzer{
+				Client:    &kubernetes.Client{Client: clientset},
+				Context:   context.Background(),
+				Namespace: "kube-system",
+			})
+			require.NoError(t, err)
+			if len(tt.wantCodes) == 0 {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			require.Equal(t, "kube-system/agent", results[0].Name)
+			var codes []string
+			for _, failure := range results[0].Error {
+				codes = append(codes, failure.Code)
+			}
+			require.Equal(t, tt.wantCodes, codes)
+		})
+	}
+}
+
+func TestDaemonSetAnalyzerTaintedNodes(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "cni", Namespace: "kube-system"},
+			Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				HostNetwork: true,
+			}}},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "new-node"},
+			Spec: v1.NodeSpec{Taints: []v1.Taint{
+				{Key: v1.TaintNodeNetworkUnavailable, Effect: v1.TaintEffectNoSchedule},
+				{Key: "dedicated", Value: "infra", Effect: v1.TaintEffectNoSchedule},
+			}},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "control-plane"},
+			Spec: v1.NodeSpec{Taints: []v1.Taint{
+				{Key: "node-role.kubernetes.io/control-plane", Effect: v1.TaintEffectNoSchedule},
+			}},
+		},
+	)
+
+	results, err := DaemonSetAnalyzer{}.Analyze(common.Analyzer{
+		Client:  &kubernetes.Client{Client: clientset},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, 1)
+	require.Equal(t, common.CodeDaemonSetNodesTainted, results[0].Error[0].Code)
+	require.Contains(t, results[0].Error[0].Text, "all 2 nodes")
+	require.Contains(t, results[0].Error[0].Text, "new-node (dedicated=infra:NoSchedule)")
+	require.Contains(t, results[0].Error[0].Text, "control-plane (node-role.kubernetes.io/control-plane:NoSchedule)")
+	// host network pods tolerate nodes without a network
+	require.NotContains(t, results[0].Error[0].Text, v1.TaintNodeNetworkUnavailable)
+}
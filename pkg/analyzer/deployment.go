@@ -60,6 +60,7 @@ func (d DeploymentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 			failures = append(failures, common.Failure{
 				Text:          fmt.Sprintf("Deployment %s/%s has %d replicas but %d are available", deployment.Namespace, deployment.Name, *deployment.Spec.Replicas, deployment.Status.Replicas),
 				KubernetesDoc: doc,
+				Code:          common.CodeDeploymentReplicaMismatch,
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: deployment.Namespace,
@@ -0,0 +1,432 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// deprecatedAPI is an API version of a kind that is deprecated and removed in
+// a Kubernetes release.
+type deprecatedAPI struct {
+	GroupVersion string
+	Kind         string
+	Deprecated   *version.Version
+	Removed      *version.Version
+	// Replacement is the apiVersion to use instead, if any.
+	Replacement string
+}
+
+func deprecation(groupVersion string, kinds []string, deprecated string, removed string, replacement string) []deprecatedAPI {
+	var apis []deprecatedAPI
+	for _, kind := range kinds {
+		apis = append(apis, deprecatedAPI{
+			GroupVersion: groupVersion,
+			Kind:         kind,
+			Deprecated:   version.MustParseGeneric(deprecated),
+			Removed:      version.MustParseGeneric(removed),
+			Replacement:  replacement,
+		})
+	}
+	return apis
+}
+
+// deprecatedAPIs are the API versions removed from Kubernetes, see
+// https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var deprecatedAPIs = func() map[string]deprecatedAPI {
+	var apis []deprecatedAPI
+	for _, d := range [][]deprecatedAPI{
+		deprecation("extensions/v1beta1", []string{"Deployment", "DaemonSet", "ReplicaSet"}, "1.8", "1.16", "apps/v1"),
+		deprecation("apps/v1beta1", []string{"Deployment", "StatefulSet", "ReplicaSet"}, "1.9", "1.16", "apps/v1"),
+		deprecation("apps/v1beta2", []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet"}, "1.9", "1.16", "apps/v1"),
+		deprecation("extensions/v1beta1", []string{"NetworkPolicy"}, "1.9", "1.16", "networking.k8s.io/v1"),
+		deprecation("extensions/v1beta1", []string{"PodSecurityPolicy"}, "1.10", "1.16", "policy/v1beta1"),
+		deprecation("extensions/v1beta1", []string{"Ingress"}, "1.14", "1.22", "networking.k8s.io/v1"),
+		deprecation("networking.k8s.io/v1beta1", []string{"Ingress", "IngressClass"}, "1.19", "1.22", "networking.k8s.io/v1"),
+		deprecation("admissionregistration.k8s.io/v1beta1", []string{"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"}, "1.16", "1.22", "admissionregistration.k8s.io/v1"),
+		deprecation("apiextensions.k8s.io/v1beta1", []string{"CustomResourceDefinition"}, "1.16", "1.22", "apiextensions.k8s.io/v1"),
+		deprecation("apiregistration.k8s.io/v1beta1", []string{"APIService"}, "1.19", "1.22", "apiregistration.k8s.io/v1"),
+		deprecation("authentication.k8s.io/v1beta1", []string{"TokenReview"}, "1.19", "1.22", "authentication.k8s.io/v1"),
+		deprecation("authorization.k8s.io/v1beta1", []string{"SubjectAccessReview", "LocalSubjectAccessReview", "SelfSubjectAccessReview"}, "1.19", "1.22", "authorization.k8s.io/v1"),
+		deprecation("certificates.k8s.io/v1beta1", []string{"CertificateSigningRequest"}, "1.19", "1.22", "certificates.k8s.io/v1"),
+		deprecation("coordination.k8s.io/v1beta1", []string{"Lease"}, "1.19", "1.22", "coordination.k8s.io/v1"),
+		deprecation("rbac.authorization.k8s.io/v1beta1", []string{"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}, "1.17", "1.22", "rbac.authorization.k8s.io/v1"),
+		deprecation("scheduling.k8s.io/v1beta1", []string{"PriorityClass"}, "1.14", "1.22", "scheduling.k8s.io/v1"),
+		deprecation("storage.k8s.io/v1beta1", []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, "1.19", "1.22", "storage.k8s.io/v1"),
+		deprecation("batch/v1beta1", []string{"CronJob"}, "1.21", "1.25", "batch/v1"),
+		deprecation("discovery.k8s.io/v1beta1", []string{"EndpointSlice"}, "1.21", "1.25", "discovery.k8s.io/v1"),
+		deprecation("events.k8s.io/v1beta1", []string{"Event"}, "1.19", "1.25", "events.k8s.io/v1"),
+		deprecation("autoscaling/v2beta1", []string{"HorizontalPodAutoscaler"}, "1.22", "1.25", "autoscaling/v2"),
+		deprecation("policy/v1beta1", []string{"PodDisruptionBudget"}, "1.21", "1.25", "policy/v1"),
+		deprecation("policy/v1beta1", []string{"PodSecurityPolicy"}, "1.21", "1.25", ""),
+		deprecation("node.k8s.io/v1beta1", []string{"RuntimeClass"}, "1.22", "1.25", "node.k8s.io/v1"),
+		deprecation("autoscaling/v2beta2", []string{"HorizontalPodAutoscaler"}, "1.23", "1.26", "autoscaling/v2"),
+		deprecation("flowcontrol.apiserver.k8s.io/v1beta1", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"),
+		deprecation("storage.k8s.io/v1beta1", []string{"CSIStorageCapacity"}, "1.24", "1.27", "storage.k8s.io/v1"),
+		deprecation("flowcontrol.apiserver.k8s.io/v1beta2", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"),
+		deprecation("flowcontrol.apiserver.k8s.io/v1beta3", []string{"FlowSchema", "PriorityLevelConfiguration"}, "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"),
+	} {
+		apis = append(apis, d...)
+	}
+	byKey := map[string]deprecatedAPI{}
+	for _, api := range apis {
+		byKey[api.GroupVersion+"/"+api.Kind] = api
+	}
+	return byKey
+}()
+
+// lookupDeprecatedAPI returns the deprecation of an apiVersion and kind, if it
+// is deprecated in the target version.
+func lookupDeprecatedAPI(apiVersion string, kind string, target *version.Version) (deprecatedAPI, bool) {
+	api, ok := deprecatedAPIs[apiVersion+"/"+kind]
+	if !ok || !target.AtLeast(api.Deprecated) {
+		return deprecatedAPI{}, false
+	}
+	return api, true
+}
+
+// failure describes the use of a deprecated API by an object.
+func (api deprecatedAPI) failure(object string, target *version.Version, sensitive []common.Sensitive) common.Failure {
+	code := common.CodeDeprecatedAPIDeprecated
+	text := fmt.Sprintf("%s uses %s, which is deprecated since Kubernetes %s and removed in %s", object, api.GroupVersion, api.Deprecated, api.Removed)
+	if target.AtLeast(api.Removed) {
+		code = common.CodeDeprecatedAPIRemoved
+		text = fmt.Sprintf("%s uses %s, which is removed in Kubernetes %s", object, api.GroupVersion, api.Removed)
+	}
+	if api.Replacement != "" {
+		text += fmt.Sprintf(", use %s instead", api.Replacement)
+	} else {
+		text += ", it has no replacement"
+	}
+	return common.Failure{
+		Text:      text,
+		Code:      code,
+		Sensitive: sensitive,
+	}
+}
+
+// deprecatedAPITargetVersion returns the Kubernetes version the deprecated
+// APIs are checked against: the configured target_version, or else the
+// version of the cluster.
+func deprecatedAPITargetVersion(serverVersion string) (*version.Version, error) {
+	if target := viper.GetString("target_version"); target != "" {
+		return version.ParseGeneric(target)
+	}
+	if serverVersion == "" {
+		return nil, errors.New("the Kubernetes version of the cluster is unknown, set a target version")
+	}
+	return version.ParseGeneric(serverVersion)
+}
+
+// DeprecatedAPIAnalyzer is an analyzer that checks for objects using API
+// versions deprecated or removed in the target Kubernetes version. It checks
+// the apiVersion live objects were last applied with, the manifests of the
+// deployed Helm releases and the manifest files configured in manifests
+type DeprecatedAPIAnalyzer struct{}
+
+// Analyze scans the live objects and Helm releases of the analyzed namespaces
+// and the configured manifest files
+func (DeprecatedAPIAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "DeprecatedAPI"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	serverVersion := ""
+	if a.Client.ServerVersion != nil {
+		serverVersion = a.Client.ServerVersion.GitVersion
+	}
+	target, err := deprecatedAPITargetVersion(serverVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := deprecatedLiveObjects(a, target)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := deprecatedHelmReleases(a, target)
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := deprecatedManifests(viper.GetStringSlice("manifests"), target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range append(append(live, releases...), manifests...) {
+		a.Results = append(a.Results, result)
+		AnalyzerErrorsMetric.WithLabelValues(kind, result.Name, "").Set(float64(len(result.Error)))
+	}
+	return a.Results, nil
+}
+
+// deprecatedLiveObjects checks the apiVersion recorded in the
+// last-applied-configuration annotation of the objects of the kinds that have
+// deprecated API versions. Only the metadata of the objects is read.
+func deprecatedLiveObjects(a common.Analyzer, target *version.Version) ([]common.Result, error) {
+	client := a.Client.CtrlClient
+	if client == nil {
+		return nil, nil
+	}
+	// objects created with a deprecated version are served by the group of
+	// its replacement, e.g. extensions/v1beta1 NetworkPolicies
+	deprecatedKinds := map[schema.GroupKind]bool{}
+	for _, api := range deprecatedAPIs {
+		for _, apiVersion := range []string{api.GroupVersion, api.Replacement} {
+			if gv, err := schema.ParseGroupVersion(apiVersion); err == nil && apiVersion != "" {
+				deprecatedKinds[gv.WithKind(api.Kind).GroupKind()] = true
+			}
+		}
+	}
+
+	resourceLists, err := discovery.ServerPreferredResources(a.Client.GetClient().Discovery())
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	var results []common.Result
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") || !deprecatedKinds[gv.WithKind(resource.Kind).GroupKind()] {
+				continue
+			}
+			if a.Namespace != "" && !resource.Namespaced {
+				continue
+			}
+			list := &metav1.PartialObjectMetadataList{}
+			list.SetGroupVersionKind(gv.WithKind(resource.Kind + "List"))
+			if err := client.List(a.Context, list, ctrl.InNamespace(a.Namespace)); err != nil {
+				continue
+			}
+			for _, item := range list.Items {
+				lastApplied := item.Annotations[lastAppliedAnnotation]
+				if lastApplied == "" {
+					continue
+				}
+				var applied metav1.TypeMeta
+				if err := json.Unmarshal([]byte(lastApplied), &applied); err != nil {
+					continue
+				}
+				api, ok := lookupDeprecatedAPI(applied.APIVersion, resource.Kind, target)
+				if !ok {
+					continue
+				}
+				name := item.Name
+				sensitive := []common.Sensitive{
+					{
+						Unmasked: item.Name,
+						Masked:   util.MaskString(item.Name),
+					},
+				}
+				if item.Namespace != "" {
+					name = fmt.Sprintf("%s/%s", item.Namespace, item.Name)
+					sensitive = append(sensitive, common.Sensitive{
+						Unmasked: item.Namespace,
+						Masked:   util.MaskString(item.Namespace),
+					})
+				}
+				failure := api.failure(fmt.Sprintf("%s %s was last applied with a manifest that", resource.Kind, name), target, sensitive)
+				results = append(results, common.Result{
+					Kind:  resource.Kind,
+					Name:  name,
+					Error: []common.Failure{failure},
+				})
+			}
+		}
+	}
+	return results, nil
+}
+
+// helmRelease holds the fields of a Helm 3 release the analyzer reads.
+type helmRelease struct {
+	Name     string `json:"name"`
+	Version  int    `json:"version"`
+	Manifest string `json:"manifest"`
+}
+
+// decodeHelmRelease decodes the release stored by Helm 3 in a Secret: base64
+// encoded, usually gzipped, JSON.
+func decodeHelmRelease(data []byte) (helmRelease, error) {
+	var release helmRelease
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return release, err
+	}
+	if bytes.HasPrefix(decoded, []byte{0x1f, 0x8b, 0x08}) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return release, err
+		}
+		defer reader.Close()
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return release, err
+		}
+	}
+	err = json.Unmarshal(decoded, &release)
+	return release, err
+}
+
+// deprecatedHelmReleases checks the manifests of the deployed Helm releases,
+// which Helm cannot upgrade once one of their API versions is removed.
+func deprecatedHelmReleases(a common.Analyzer, target *version.Version) ([]common.Result, error) {
+	secrets, err := a.Client.GetClient().CoreV1().Secrets(a.Namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: "owner=helm,status=deployed",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []common.Result
+	for _, secret := range secrets.Items {
+		if secret.Type != v1.SecretType("helm.sh/release.v1") {
+			continue
+		}
+		release, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			continue
+		}
+		objects, err := manifestObjects([]byte(release.Manifest))
+		if err != nil {
+			continue
+		}
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: secret.Namespace,
+				Masked:   util.MaskString(secret.Namespace),
+			},
+			{
+				Unmasked: release.Name,
+				Masked:   util.MaskString(release.Name),
+			},
+		}
+		var failures []common.Failure
+		for _, object := range objects {
+			if api, ok := lookupDeprecatedAPI(object.APIVersion, object.Kind, target); ok {
+				failures = append(failures, api.failure(fmt.Sprintf("%s %s of revision %d", object.Kind, object.Name, release.Version), target, sensitive))
+			}
+		}
+		if len(failures) > 0 {
+			results = append(results, common.Result{
+				Kind:  "HelmRelease",
+				Name:  fmt.Sprintf("%s/%s", secret.Namespace, release.Name),
+				Error: failures,
+			})
+		}
+	}
+	return results, nil
+}
+
+// skippedManifestDirs are the directories of vendored code that are not
+// walked for manifests.
+var skippedManifestDirs = map[string]bool{
+	"vendor":       true,
+	"node_modules": true,
+}
+
+// deprecatedManifests checks the YAML and JSON files of the given paths,
+// walking directories. Hidden and vendored directories are skipped, as are
+// the files that are not Kubernetes manifests, e.g. Helm templates.
+func deprecatedManifests(paths []string, target *version.Version) ([]common.Result, error) {
+	var results []common.Result
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if path != root && (strings.HasPrefix(entry.Name(), ".") || skippedManifestDirs[entry.Name()]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if path != root && !strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml") && !strings.HasSuffix(path, ".json") {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			// the objects decoded before an invalid document are still checked
+			objects, _ := manifestObjects(data)
+			var failures []common.Failure
+			for _, object := range objects {
+				if api, ok := lookupDeprecatedAPI(object.APIVersion, object.Kind, target); ok {
+					failures = append(failures, api.failure(fmt.Sprintf("%s %s", object.Kind, object.Name), target, []common.Sensitive{}))
+				}
+			}
+			if len(failures) > 0 {
+				results = append(results, common.Result{
+					Kind:  "Manifest",
+					Name:  path,
+					Error: failures,
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// manifestObjects decodes the type and metadata of the objects of a YAML or
+// JSON manifest. On error, the objects decoded so far are returned.
+func manifestObjects(data []byte) ([]metav1.PartialObjectMetadata, error) {
+	decoder := yamlutil.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	var objects []metav1.PartialObjectMetadata
+	for {
+		var object metav1.PartialObjectMetadata
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return objects, err
+		}
+		if object.Kind == "" {
+			continue
+		}
+		objects = append(objects, object)
+	}
+}
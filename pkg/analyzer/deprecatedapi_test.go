@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	k8sversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const deprecatedManifest = `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: web
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`
+
+func helmReleaseSecret(t *testing.T, manifest string) *v1.Secret {
+	release, err := json.Marshal(helmRelease{Name: "shop", Version: 3, Manifest: manifest})
+	require.NoError(t, err)
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	_, err = writer.Write(release)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1.shop.v3",
+			Namespace: "default",
+			Labels:    map[string]string{"owner": "helm", "status": "deployed"},
+		},
+		Type: "helm.sh/release.v1",
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(gzipped.Bytes()))},
+	}
+}
+
+func TestLookupDeprecatedAPI(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		target     string
+		wantCode   string
+	}{
+		{"current version", "batch/v1", "CronJob", "1.29", ""},
+		{"not deprecated yet", "batch/v1beta1", "CronJob", "1.20", ""},
+		{"deprecated", "batch/v1beta1", "CronJob", "1.21", common.CodeDeprecatedAPIDeprecated},
+		{"removed", "batch/v1beta1", "CronJob", "1.25.3", common.CodeDeprecatedAPIRemoved},
+		{"other kind of the group version", "batch/v1beta1", "Job", "1.25", ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			target := version.MustParseGeneric(tt.target)
+			api, ok := lookupDeprecatedAPI(tt.apiVersion, tt.kind, target)
+			require.Equal(t, tt.wantCode != "", ok)
+			if ok {
+				failure := api.failure("CronJob backup", target, nil)
+				require.Equal(t, tt.wantCode, failure.Code)
+				require.Contains(t, failure.Text, "use batch/v1 instead")
+			}
+		})
+	}
+}
+
+func TestDeprecatedAPIAnalyzer(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(deprecatedManifest), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("apiVersion: batch/v1beta1"), 0o644))
+	for _, skipped := range []string{".git", "vendor", "node_modules"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, skipped), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, skipped, "app.yaml"), []byte(deprecatedManifest), 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "templates"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "cronjob.yaml"), []byte("apiVersion: batch/v1beta1\nkind: CronJob\nmetadata:\n  name: web\n---\nspec:\n  schedule: [{{ .Values.schedule }}\n"), 0o644))
+	viper.Set("manifests", []string{dir})
+	t.Cleanup(func() { viper.Set("manifests", nil) })
+
+	clientset := fake.NewSimpleClientset(helmReleaseSecret(t, deprecatedManifest))
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "networking.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "ingresses", Kind: "Ingress", Namespaced: true, Verbs: metav1.Verbs{"list"}},
+				{Name: "ingresses/status", Kind: "Ingress", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+			},
+		},
+	}
+	ctrlClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		&networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "legacy",
+			Namespace:   "default",
+			Annotations: map[string]string{lastAppliedAnnotation: `{"apiVersion":"extensions/v1beta1","kind":"Ingress"}`},
+		}},
+		&networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "current",
+			Namespace:   "default",
+			Annotations: map[string]string{lastAppliedAnnotation: `{"apiVersion":"networking.k8s.io/v1","kind":"Ingress"}`},
+		}},
+	).Build()
+
+	results, err := DeprecatedAPIAnalyzer{}.Analyze(common.Analyzer{
+		Client: &kubernetes.Client{
+			Client:        clientset,
+			CtrlClient:    ctrlClient,
+			ServerVersion: &k8sversion.Info{Major: "1", Minor: "27+", GitVersion: "v1.27.3-gke.100"},
+		},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+
+	byName := map[string]common.Result{}
+	for _, result := range results {
+		byName[result.Kind+" "+result.Name] = result
+	}
+	require.Len(t, byName, 4)
+
+	live := byName["Ingress default/legacy"]
+	require.Len(t, live.Error, 1)
+	require.Equal(t, common.CodeDeprecatedAPIRemoved, live.Error[0].Code)
+	require.Contains(t, live.Error[0].Text, "use networking.k8s.io/v1 instead")
+
+	release := byName["HelmRelease default/shop"]
+	require.Len(t, release.Error, 2)
+	require.Contains(t, release.Error[0].Text, "Ingress web of revision 3")
+
+	manifest := byName["Manifest "+filepath.Join(dir, "app.yaml")]
+	require.Len(t, manifest.Error, 2)
+	require.Equal(t, common.CodeDeprecatedAPIRemoved, manifest.Error[1].Code)
+
+	template := byName["Manifest "+filepath.Join(dir, "templates", "cronjob.yaml")]
+	require.Len(t, template.Error, 1)
+	require.Contains(t, template.Error[0].Text, "CronJob web")
+}
+
+func TestDeprecatedAPIAnalyzerTargetVersion(t *testing.T) {
+	viper.Set("target_version", "1.21")
+	t.Cleanup(func() { viper.Set("target_version", "") })
+
+	results, err := DeprecatedAPIAnalyzer{}.Analyze(common.Analyzer{
+		Client: &kubernetes.Client{
+			Client:        fake.NewSimpleClientset(helmReleaseSecret(t, deprecatedManifest)),
+			ServerVersion: &k8sversion.Info{GitVersion: "v1.27.3"},
+		},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	var codes []string
+	for _, failure := range results[0].Error {
+		codes = append(codes, failure.Code)
+	}
+	require.Equal(t, []string{common.CodeDeprecatedAPIDeprecated, common.CodeDeprecatedAPIDeprecated}, codes)
+}
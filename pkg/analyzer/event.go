@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultEventsWindow is how far back Warning events are read, unless
+// events_window is configured.
+const DefaultEventsWindow = time.Hour
+
+// maxEventReasons is the number of reasons reported for an object, those
+// occurring most often.
+const maxEventReasons = 3
+
+// EventsWindow returns the configured events_window.
+func EventsWindow() time.Duration {
+	if window := viper.GetDuration("events_window"); window > 0 {
+		return window
+	}
+	return DefaultEventsWindow
+}
+
+// EventAnalyzer is an analyzer that reports the Warning events of the
+// objects no other analyzer looks at, e.g. FailedMount of a VolumeSnapshot
+type EventAnalyzer struct{}
+
+// eventReason aggregates the events of an object with the same reason.
+type eventReason struct {
+	reason    string
+	count     int32
+	message   string
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// analyzedKinds returns the kinds, in lower case, that have an analyzer of
+// their own and whose events are not reported by the event analyzer.
+func analyzedKinds() map[string]bool {
+	kinds := map[string]bool{}
+	for _, analyzers := range []map[string]common.IAnalyzer{coreAnalyzerMap, additionalAnalyzerMap} {
+		for name := range analyzers {
+			kinds[strings.ToLower(name)] = true
+		}
+	}
+	return kinds
+}
+
+// Analyze reads the Warning events of the configured window, from
+// events.k8s.io/v1 if events_v1 is set, and reports the most frequent
+// reasons of each object they are about
+func (EventAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Event"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	window := EventsWindow()
+	events, err := util.FetchWarningEvents(a.Context, a.Client, a.Namespace, time.Now().Add(-window), viper.GetBool("events_v1"))
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := analyzedKinds()
+	objects := map[v1.ObjectReference]map[string]*eventReason{}
+	for _, event := range events {
+		if skipped[strings.ToLower(event.Object.Kind)] {
+			continue
+		}
+		object := v1.ObjectReference{Kind: event.Object.Kind, Namespace: event.Object.Namespace, Name: event.Object.Name}
+		if objects[object] == nil {
+			objects[object] = map[string]*eventReason{}
+		}
+		reason, ok := objects[object][event.Reason]
+		if !ok {
+			reason = &eventReason{reason: event.Reason, firstSeen: event.FirstSeen}
+			objects[object][event.Reason] = reason
+		}
+		// events are deduplicated by the event recorder, their count is the
+		// number of occurrences since the event was first seen, which may be
+		// before the window
+		reason.count += event.Count
+		if event.FirstSeen.Before(reason.firstSeen) {
+			reason.firstSeen = event.FirstSeen
+		}
+		if !event.LastSeen.Before(reason.lastSeen) {
+			reason.message = event.Message
+			reason.lastSeen = event.LastSeen
+		}
+	}
+
+	for object, reasons := range objects {
+		failures := eventFailures(object, topEventReasons(reasons))
+		name := object.Name
+		if object.Namespace != "" {
+			name = fmt.Sprintf("%s/%s", object.Namespace, object.Name)
+		}
+		a.Results = append(a.Results, common.Result{
+			Kind:         kind,
+			Name:         name,
+			Error:        failures,
+			ParentObject: fmt.Sprintf("%s/%s", object.Kind, name),
+		})
+		AnalyzerErrorsMetric.WithLabelValues(kind, object.Name, object.Namespace).Set(float64(len(failures)))
+	}
+
+	return a.Results, nil
+}
+
+// topEventReasons returns the reasons occurring most often, at most
+// maxEventReasons of them.
+func topEventReasons(reasons map[string]*eventReason) []eventReason {
+	var sorted []eventReason
+	for _, reason := range reasons {
+		sorted = append(sorted, *reason)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].reason < sorted[j].reason
+	})
+	if len(sorted) > maxEventReasons {
+		sorted = sorted[:maxEventReasons]
+	}
+	return sorted
+}
+
+func eventFailures(object v1.ObjectReference, reasons []eventReason) []common.Failure {
+	var failures []common.Failure
+	for _, reason := range reasons {
+		text := fmt.Sprintf("%s %s had the %s Warning %d times since %s", object.Kind, object.Name, reason.reason, reason.count, reason.firstSeen.UTC().Format(time.RFC3339))
+		if reason.message != "" {
+			text = fmt.Sprintf("%s, the latest: %s", text, reason.message)
+		}
+		failures = append(failures, common.Failure{
+			Text:   text,
+			Code:   common.CodeEventWarning,
+			Reason: reason.reason,
+			Sensitive: []common.Sensitive{
+				{
+					Unmasked: object.Namespace,
+					Masked:   util.MaskString(object.Namespace),
+				},
+				{
+					Unmasked: object.Name,
+					Masked:   util.MaskString(object.Name),
+				},
+			},
+		})
+	}
+	return failures
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newWarningEvent(name string, object v1.ObjectReference, reason string, count int32, age time.Duration) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: object,
+		Type:           v1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " " + name,
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(time.Now().Add(-age - time.Duration(count)*time.Minute)),
+		LastTimestamp:  metav1.NewTime(time.Now().Add(-age)),
+	}
+}
+
+// eventFirstSeen parses the time a failure of the event analyzer reports the
+// events were first seen at.
+func eventFirstSeen(t *testing.T, text string) time.Time {
+	_, after, found := strings.Cut(text, " since ")
+	require.True(t, found)
+	since, _, _ := strings.Cut(after, ",")
+	firstSeen, err := time.Parse(time.RFC3339, since)
+	require.NoError(t, err)
+	return firstSeen
+}
+
+func TestEventAnalyzer(t *testing.T) {
+	snapshot := v1.ObjectReference{Kind: "VolumeSnapshot", Namespace: "default", Name: "web"}
+	issuer := v1.ObjectReference{Kind: "ClusterIssuer", Name: "letsencrypt"}
+	clientset := fake.NewSimpleClientset(
+		newWarningEvent("web.1", snapshot, "SnapshotContentCreationFailed", 10, time.Minute),
+		// the same reason, recorded by a restarted controller
+		newWarningEvent("web.2", snapshot, "SnapshotContentCreationFailed", 5, 2*time.Minute),
+		newWarningEvent("web.3", snapshot, "SnapshotFinalizerError", 8, time.Minute),
+		newWarningEvent("web.4", snapshot, "ErrorPVCNotFound", 2, time.Minute),
+		newWarningEvent("web.5", snapshot, "SnapshotDeletePending", 1, time.Minute),
+		// outside the window
+		newWarningEvent("web.6", snapshot, "SnapshotCreationFailed", 100, 2*time.Hour),
+		newWarningEvent("letsencrypt.1", issuer, "ErrRegisterACMEAccount", 1, time.Minute),
+		// reported by the pod and node analyzers
+		newWarningEvent("api.1", v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "api"}, "BackOff", 3, time.Minute),
+		newWarningEvent("worker-1.1", v1.ObjectReference{Kind: "Node", Name: "worker-1"}, "NodeNotReady", 1, time.Minute),
+		&v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.7", Namespace: "default"},
+			InvolvedObject: snapshot,
+			Type:           v1.EventTypeNormal,
+			Reason:         "Pulled",
+			LastTimestamp:  metav1.NewTime(time.Now()),
+		},
+	)
+
+	results, err := EventAnalyzer{}.Analyze(common.Analyzer{
+		Client:  &kubernetes.Client{Client: clientset},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	byName := map[string]common.Result{}
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+	web := byName["default/web"]
+	require.Equal(t, "Event", web.Kind)
+	require.Equal(t, "VolumeSnapshot/default/web", web.ParentObject)
+	var reasons []string
+	for _, failure := range web.Error {
+		require.Equal(t, common.CodeEventWarning, failure.Code)
+		reasons = append(reasons, failure.Reason)
+	}
+	require.Equal(t, []string{"SnapshotContentCreationFailed", "SnapshotFinalizerError", "ErrorPVCNotFound"}, reasons)
+	require.Contains(t, web.Error[0].Text, "had the SnapshotContentCreationFailed Warning 15 times since ")
+	// web.1 was first seen 11 minutes ago, before web.2
+	require.WithinDuration(t, time.Now().Add(-11*time.Minute), eventFirstSeen(t, web.Error[0].Text), 5*time.Second)
+	require.Contains(t, web.Error[0].Text, "the latest: SnapshotContentCreationFailed web.1")
+
+	require.Equal(t, "ClusterIssuer/letsencrypt", byName["letsencrypt"].ParentObject)
+	require.Len(t, byName["letsencrypt"].Error, 1)
+}
+
+func TestEventAnalyzerEventsV1(t *testing.T) {
+	viper.Set("events_v1", true)
+	t.Cleanup(func() { viper.Set("events_v1", nil) })
+
+	clientset := fake.NewSimpleClientset(&eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "data.1", Namespace: "default"},
+		Regarding:  v1.ObjectReference{Kind: "VolumeSnapshot", Namespace: "default", Name: "data"},
+		Type:       v1.EventTypeWarning,
+		Reason:     "SnapshotCreationFailed",
+		Note:       "volumesnapshotclass.snapshot.storage.k8s.io \"fast\" not found",
+		EventTime:  metav1.NewMicroTime(time.Now().Add(-3 * time.Hour)),
+		Series:     &eventsv1.EventSeries{Count: 4, LastObservedTime: metav1.NewMicroTime(time.Now().Add(-time.Minute))},
+	})
+
+	results, err := EventAnalyzer{}.Analyze(common.Analyzer{
+		Client:  &kubernetes.Client{Client: clientset},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "VolumeSnapshot/default/data", results[0].ParentObject)
+	require.Equal(t, "default/data", results[0].Name)
+	require.Len(t, results[0].Error, 1)
+	require.Contains(t, results[0].Error[0].Text, "had the SnapshotCreationFailed Warning 4 times since ")
+	// the series started before the window
+	require.WithinDuration(t, time.Now().Add(-3*time.Hour), eventFirstSeen(t, results[0].Error[0].Text), 5*time.Second)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FinalizerAnalyzer is an analyzer that checks for objects whose finalizers
+// block their deletion
+type FinalizerAnalyzer struct{}
+
+// Analyze lists, through discovery, the objects of every namespaced resource.
+// Objects of Terminating namespaces are left to the namespace analyzer.
+func (FinalizerAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Finalizer"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	namespaces, err := a.Client.GetClient().CoreV1().Namespaces().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	terminating := map[string]bool{}
+	for _, ns := range namespaces.Items {
+		if ns.Status.Phase == v1.NamespaceTerminating {
+			terminating[ns.Name] = true
+		}
+	}
+
+	// the objects found are still reported when some resources cannot be listed
+	objects, listErr := stuckObjects(a, []string{a.Namespace}, time.Now())
+	for _, object := range objects {
+		if terminating[object.Namespace] {
+			continue
+		}
+		name := fmt.Sprintf("%s/%s", object.Namespace, object.Name)
+		a.Results = append(a.Results, common.Result{
+			Kind: object.Kind,
+			Name: name,
+			Error: []common.Failure{
+				{
+					Text: fmt.Sprintf("%s %s was deleted at %s but its finalizers %s have not been removed",
+						object.Kind, name, object.DeletedAt.UTC().Format(time.RFC3339), strings.Join(object.Finalizers, ", ")),
+					Code: common.CodeObjectDeletionBlocked,
+					Sensitive: []common.Sensitive{
+						{
+							Unmasked: object.Namespace,
+							Masked:   util.MaskString(object.Namespace),
+						},
+						{
+							Unmasked: object.Name,
+							Masked:   util.MaskString(object.Name),
+						},
+					},
+				},
+			},
+		})
+		AnalyzerErrorsMetric.WithLabelValues(kind, object.Name, object.Namespace).Set(1)
+	}
+
+	return a.Results, listErr
+}
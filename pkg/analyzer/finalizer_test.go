@@ -0,0 +1,38 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestFinalizerAnalyzer(t *testing.T) {
+	clientset, ctrlClient := newStuckDeletionClients(interceptor.Funcs{})
+	results, err := FinalizerAnalyzer{}.Analyze(common.Analyzer{
+		Client:  &kubernetes.Client{Client: clientset, CtrlClient: ctrlClient},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "ConfigMap", results[0].Kind)
+	require.Equal(t, "default/orphan", results[0].Name)
+	require.Equal(t, common.CodeObjectDeletionBlocked, results[0].Error[0].Code)
+	require.Contains(t, results[0].Error[0].Text, "its finalizers example.com/cleanup have not been removed")
+}
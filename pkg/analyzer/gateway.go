@@ -61,6 +61,8 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					"Gateway uses the GatewayClass %s which does not exist.",
 					gtw.Spec.GatewayClassName,
 				),
+				Code:      common.CodeGatewayClassNotFound,
+				Reference: &common.ObjectReference{Kind: "GatewayClass", Name: string(gtw.Spec.GatewayClassName)},
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: string(gtw.Spec.GatewayClassName),
@@ -79,6 +81,7 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					gtwName,
 					gtw.Status.Conditions[0].Message,
 				),
+				Code: common.CodeGatewayNotAccepted,
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: gtwNamespace,
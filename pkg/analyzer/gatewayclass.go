@@ -59,6 +59,7 @@ func (GatewayClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 					gc.Spec.ControllerName,
 					gc.Status.Conditions[0].Message,
 				),
+				Code: common.CodeGatewayClassNotAccepted,
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: gcName,
@@ -81,6 +81,7 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		default:
 			failures = append(failures, common.Failure{
 				Text:      fmt.Sprintf("HorizontalPodAutoscaler uses %s as ScaleTargetRef which is not an option.", scaleTargetRef.Kind),
+				Code:      common.CodeHPAInvalidScaleTargetKind,
 				Sensitive: []common.Sensitive{},
 			})
 		}
@@ -91,6 +92,8 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			failures = append(failures, common.Failure{
 				Text:          fmt.Sprintf("HorizontalPodAutoscaler uses %s/%s as ScaleTargetRef which does not exist.", scaleTargetRef.Kind, scaleTargetRef.Name),
 				KubernetesDoc: doc,
+				Code:          common.CodeHPAScaleTargetNotFound,
+				Reference:     &common.ObjectReference{Kind: scaleTargetRef.Kind, Namespace: hpa.Namespace, Name: scaleTargetRef.Name},
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: scaleTargetRef.Name,
@@ -112,6 +115,8 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("%s %s/%s does not have resource configured.", scaleTargetRef.Kind, a.Namespace, scaleTargetRef.Name),
 					KubernetesDoc: doc,
+					Code:          common.CodeHPAScaleTargetNoResources,
+					Reference:     &common.ObjectReference{Kind: scaleTargetRef.Kind, Namespace: hpa.Namespace, Name: scaleTargetRef.Name},
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: scaleTargetRef.Name,
@@ -67,6 +67,8 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 						namespace,
 						gtwref.Name,
 					),
+					Code:      common.CodeHTTPRouteGatewayNotFound,
+					Reference: &common.ObjectReference{Kind: "Gateway", Namespace: namespace, Name: string(gtwref.Name)},
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: gtw.Namespace,
@@ -93,6 +95,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 										gtw.Namespace,
 										gtw.Name,
 									),
+									Code: common.CodeHTTPRouteNamespaceNotAllowed,
 									Sensitive: []common.Sensitive{
 										{
 											Unmasked: route.Namespace,
@@ -124,6 +127,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 										gtw.Namespace,
 										gtw.Name,
 									),
+									Code: common.CodeHTTPRouteSelectorMismatch,
 									Sensitive: []common.Sensitive{
 										{
 											Unmasked: route.Namespace,
@@ -163,6 +167,8 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 							route.Namespace,
 							backend.Name,
 						),
+						Code:      common.CodeHTTPRouteServiceNotFound,
+						Reference: &common.ObjectReference{Kind: "Service", Namespace: route.Namespace, Name: string(backend.Name)},
 						Sensitive: []common.Sensitive{
 							{
 								Unmasked: service.Namespace,
@@ -190,6 +196,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 								service.Namespace,
 								service.Name,
 							),
+							Code: common.CodeHTTPRoutePortMismatch,
 							Sensitive: []common.Sensitive{
 								{
 									Unmasked: string(backend.Name),
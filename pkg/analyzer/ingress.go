@@ -61,6 +61,7 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("Ingress %s/%s does not specify an Ingress class.", ing.Namespace, ing.Name),
 					KubernetesDoc: doc,
+					Code:          common.CodeIngressNoClass,
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: ing.Namespace,
@@ -86,6 +87,8 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("Ingress uses the ingress class %s which does not exist.", *ingressClassName),
 					KubernetesDoc: doc,
+					Code:          common.CodeIngressClassNotFound,
+					Reference:     &common.ObjectReference{Kind: "IngressClass", Name: *ingressClassName},
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: *ingressClassName,
@@ -108,6 +111,8 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 						failures = append(failures, common.Failure{
 							Text:          fmt.Sprintf("Ingress uses the service %s/%s which does not exist.", ing.Namespace, path.Backend.Service.Name),
 							KubernetesDoc: doc,
+							Code:          common.CodeIngressServiceNotFound,
+							Reference:     &common.ObjectReference{Kind: "Service", Namespace: ing.Namespace, Name: path.Backend.Service.Name},
 							Sensitive: []common.Sensitive{
 								{
 									Unmasked: ing.Namespace,
@@ -132,6 +137,8 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("Ingress uses the secret %s/%s as a TLS certificate which does not exist.", ing.Namespace, tls.SecretName),
 					KubernetesDoc: doc,
+					Code:          common.CodeIngressTLSSecretNotFound,
+					Reference:     &common.ObjectReference{Kind: "Secret", Namespace: ing.Namespace, Name: tls.SecretName},
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: ing.Namespace,
@@ -0,0 +1,318 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// jobStuckAfter is how long a started Job may have no active pods before it
+// is reported as stuck.
+const jobStuckAfter = 5 * time.Minute
+
+// The pods of a Job are recreated after a delay doubling with every failure,
+// starting at jobBackoffBase and capped at jobBackoffMax.
+const (
+	jobBackoffBase = 10 * time.Second
+	jobBackoffMax  = 6 * time.Minute
+)
+
+// exitCodeMeanings explains the exit codes containers commonly fail with.
+var exitCodeMeanings = map[int32]string{
+	1:   "application error",
+	2:   "misuse of a shell builtin",
+	126: "command cannot be executed",
+	127: "command not found",
+	128: "invalid exit argument",
+	130: "interrupted (SIGINT)",
+	134: "aborted (SIGABRT)",
+	137: "killed (SIGKILL), often because it ran out of memory",
+	139: "segmentation fault (SIGSEGV)",
+	143: "terminated (SIGTERM)",
+}
+
+// JobAnalyzer is an analyzer that checks for failed and stuck Jobs
+type JobAnalyzer struct{}
+
+// Analyze scans all namespaces for Jobs that failed or make no progress
+func (JobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Job"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "batch",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().BatchV1().Jobs(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var preAnalysis = map[string]common.PreAnalysis{}
+	namespacePods := map[string][]v1.Pod{}
+
+	for _, job := range list.Items {
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: job.Namespace,
+				Masked:   util.MaskString(job.Namespace),
+			},
+			{
+				Unmasked: job.Name,
+				Masked:   util.MaskString(job.Name),
+			},
+		}
+
+		failed := jobCondition(job, batchv1.JobFailed)
+		switch {
+		case failed == nil:
+		case failed.Reason == "BackoffLimitExceeded":
+			backoffLimit := int32(6)
+			if job.Spec.BackoffLimit != nil {
+				backoffLimit = *job.Spec.BackoffLimit
+			}
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("Job %s/%s has failed %d times and reached its backoff limit of %d", job.Namespace, job.Name, job.Status.Failed, backoffLimit),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.backoffLimit"),
+				Code:          common.CodeJobBackoffLimitExceeded,
+				Reason:        failed.Reason,
+				Sensitive:     sensitive,
+			})
+		case failed.Reason == "DeadlineExceeded":
+			var deadline int64
+			if job.Spec.ActiveDeadlineSeconds != nil {
+				deadline = *job.Spec.ActiveDeadlineSeconds
+			}
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("Job %s/%s was terminated after running longer than its active deadline of %d seconds", job.Namespace, job.Name, deadline),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.activeDeadlineSeconds"),
+				Code:          common.CodeJobDeadlineExceeded,
+				Reason:        failed.Reason,
+				Sensitive:     sensitive,
+			})
+		default:
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("Job %s/%s has failed: %s %s", job.Namespace, job.Name, failed.Reason, failed.Message),
+				Code:      common.CodeJobFailed,
+				Reason:    failed.Reason,
+				Sensitive: sensitive,
+			})
+		}
+
+		// the failed pods of Jobs that completed after a retry are not a
+		// problem
+		var lastFailure time.Time
+		if job.Status.Failed > 0 && jobCondition(job, batchv1.JobComplete) == nil {
+			pods, err := jobPods(a, job, namespacePods)
+			if err != nil {
+				return nil, err
+			}
+			failures = append(failures, jobPodFailures(job, pods, sensitive)...)
+			lastFailure = lastPodFailure(pods)
+		}
+
+		if jobStuck(job, lastFailure, time.Now()) {
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("Job %s/%s has no active pods and has neither completed nor failed since it started at %s", job.Namespace, job.Name, job.Status.StartTime.Format(time.RFC3339)),
+				Code:      common.CodeJobNoActivePods,
+				Sensitive: sensitive,
+			})
+		}
+
+		if len(failures) > 0 {
+			preAnalysis[fmt.Sprintf("%s/%s", job.Namespace, job.Name)] = common.PreAnalysis{
+				Job:            job,
+				FailureDetails: failures,
+			}
+			AnalyzerErrorsMetric.WithLabelValues(kind, job.Name, job.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	for key, value := range preAnalysis {
+		var currentAnalysis = common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		}
+
+		parent, found := util.GetParent(a.Client, value.Job.ObjectMeta)
+		if found {
+			currentAnalysis.ParentObject = parent
+		}
+		a.Results = append(a.Results, currentAnalysis)
+	}
+
+	return a.Results, nil
+}
+
+func jobCondition(job batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == v1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// jobFinished reports whether a Job has completed or failed.
+func jobFinished(job batchv1.Job) bool {
+	return jobCondition(job, batchv1.JobComplete) != nil || jobCondition(job, batchv1.JobFailed) != nil
+}
+
+// jobStuck reports whether a running Job has had no active pods for a while.
+// After a pod failed, the time the Job waits before retrying is not counted.
+func jobStuck(job batchv1.Job, lastFailure time.Time, now time.Time) bool {
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		return false
+	}
+	if jobFinished(job) || job.Status.Active > 0 || job.Status.StartTime == nil {
+		return false
+	}
+	since := job.Status.StartTime.Time
+	if lastFailure.After(since) {
+		since = lastFailure
+	}
+	return now.Sub(since) > jobBackoffDelay(job.Status.Failed)+jobStuckAfter
+}
+
+// jobBackoffDelay returns how long a Job waits before recreating a pod after
+// the given number of failures.
+func jobBackoffDelay(failed int32) time.Duration {
+	if failed <= 0 {
+		return 0
+	}
+	delay := jobBackoffBase
+	for i := int32(1); i < failed && delay < jobBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > jobBackoffMax {
+		return jobBackoffMax
+	}
+	return delay
+}
+
+// jobPods returns the pods of a Job. The pods of each namespace are listed
+// once and kept in namespacePods.
+func jobPods(a common.Analyzer, job batchv1.Job, namespacePods map[string][]v1.Pod) ([]v1.Pod, error) {
+	if job.Spec.Selector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("job %s/%s has an invalid selector: %w", job.Namespace, job.Name, err)
+	}
+	pods, ok := namespacePods[job.Namespace]
+	if !ok {
+		list, err := a.Client.GetClient().CoreV1().Pods(job.Namespace).List(a.Context, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		pods = list.Items
+		namespacePods[job.Namespace] = pods
+	}
+
+	var selected []v1.Pod
+	for _, pod := range pods {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			selected = append(selected, pod)
+		}
+	}
+	return selected, nil
+}
+
+// lastPodFailure returns when the latest container of the pods exited with an
+// error.
+func lastPodFailure(pods []v1.Pod) time.Time {
+	var last time.Time
+	for _, pod := range pods {
+		statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			for _, terminated := range []*v1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+				if terminated != nil && terminated.ExitCode != 0 && terminated.FinishedAt.After(last) {
+					last = terminated.FinishedAt.Time
+				}
+			}
+		}
+	}
+	return last
+}
+
+// jobPodFailures reports the containers of the pods of a Job that exited with
+// an error, once per container and exit code.
+func jobPodFailures(job batchv1.Job, pods []v1.Pod, sensitive []common.Sensitive) []common.Failure {
+
+	type exit struct {
+		container string
+		code      int32
+		reason    string
+	}
+	counts := map[exit]int{}
+	var exits []exit
+	for _, pod := range pods {
+		statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			terminated := status.State.Terminated
+			if terminated == nil {
+				terminated = status.LastTerminationState.Terminated
+			}
+			if terminated == nil || terminated.ExitCode == 0 {
+				continue
+			}
+			e := exit{container: status.Name, code: terminated.ExitCode, reason: terminated.Reason}
+			if counts[e] == 0 {
+				exits = append(exits, e)
+			}
+			counts[e]++
+		}
+	}
+	sort.SliceStable(exits, func(i, j int) bool { return counts[exits[i]] > counts[exits[j]] })
+
+	var failures []common.Failure
+	for _, e := range exits {
+		text := fmt.Sprintf("Container %s of %d pods of Job %s/%s exited with code %d", e.container, counts[e], job.Namespace, job.Name, e.code)
+		if meaning, ok := exitCodeMeanings[e.code]; ok {
+			text += fmt.Sprintf(" (%s)", meaning)
+		}
+		if e.reason != "" {
+			text += fmt.Sprintf(", reason %s", e.reason)
+		}
+		failures = append(failures, common.Failure{
+			Text:      text,
+			Code:      common.CodeJobPodFailed,
+			Container: e.container,
+			Reason:    e.reason,
+			Sensitive: sensitive,
+		})
+	}
+	return failures
+}
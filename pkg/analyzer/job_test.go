@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestJobAnalyzer(t *testing.T) {
+	backoffLimit := int32(2)
+	deadline := int64(60)
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	justStarted := metav1.NewTime(time.Now())
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": "backoff"}}
+
+	clientset := fake.NewSimpleClientset(
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", UID: "cronjob-uid"},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "backoff", Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "nightly", UID: "cronjob-uid"}},
+			},
+			Spec: batchv1.JobSpec{BackoffLimit: &backoffLimit, Selector: selector},
+			Status: batchv1.JobStatus{
+				Failed:     3,
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Reason: "BackoffLimitExceeded"}},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "backoff-1", Namespace: "default", Labels: map[string]string{"job-name": "backoff"}},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "main",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+			}}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "backoff-2", Namespace: "default", Labels: map[string]string{"job-name": "backoff"}},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "main",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+			}}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", Labels: map[string]string{"job-name": "other"}},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "main",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1}},
+			}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "deadline", Namespace: "default"},
+			Spec:       batchv1.JobSpec{ActiveDeadlineSeconds: &deadline},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Reason: "DeadlineExceeded"}},
+			},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "default"},
+			Status:     batchv1.JobStatus{StartTime: &started},
+		},
+		&batchv1.Job{
+			// Just started, its pods are still being created.
+			ObjectMeta: metav1.ObjectMeta{Name: "starting", Namespace: "default"},
+			Status:     batchv1.JobStatus{StartTime: &justStarted},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"},
+			Status:     batchv1.JobStatus{StartTime: &started, Active: 1},
+		},
+		&batchv1.Job{
+			// Completed after a retry.
+			ObjectMeta: metav1.ObjectMeta{Name: "retried", Namespace: "default"},
+			Spec:       batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": "retried"}}},
+			Status: batchv1.JobStatus{
+				StartTime:  &started,
+				Failed:     1,
+				Succeeded:  1,
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "retried-1", Namespace: "default", Labels: map[string]string{"job-name": "retried"}},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "main",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1}},
+			}}},
+		},
+		&batchv1.Job{
+			// Waiting to recreate its pod after the fourth failure.
+			ObjectMeta: metav1.ObjectMeta{Name: "retrying", Namespace: "default"},
+			Spec:       batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": "retrying"}}},
+			Status:     batchv1.JobStatus{StartTime: &started, Failed: 4},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "retrying-4", Namespace: "default", Labels: map[string]string{"job-name": "retrying"}},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name: "main",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+					ExitCode:   1,
+					FinishedAt: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+				}},
+			}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "complete", Namespace: "default"},
+			Status: batchv1.JobStatus{
+				StartTime:  &started,
+				Succeeded:  1,
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}},
+			},
+		},
+	)
+
+	results, err := JobAnalyzer{}.Analyze(common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset},
+		Context:   context.Background(),
+		Namespace: "default",
+	})
+	require.NoError(t, err)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	// the pods of the namespace are listed once for all Jobs
+	podLists := 0
+	for _, action := range clientset.Actions() {
+		if action.Matches("list", "pods") {
+			podLists++
+		}
+	}
+	require.Equal(t, 1, podLists)
+
+	require.Len(t, results, 4)
+
+	require.Equal(t, "default/backoff", results[0].Name)
+	require.Equal(t, "CronJob/nightly", results[0].ParentObject)
+	require.Len(t, results[0].Error, 2)
+	require.Equal(t, common.CodeJobBackoffLimitExceeded, results[0].Error[0].Code)
+	require.Contains(t, results[0].Error[0].Text, "backoff limit of 2")
+	require.Equal(t, common.CodeJobPodFailed, results[0].Error[1].Code)
+	require.Equal(t, "main", results[0].Error[1].Container)
+	require.Equal(t, "OOMKilled", results[0].Error[1].Reason)
+	require.Contains(t, results[0].Error[1].Text, "of 2 pods")
+	require.Contains(t, results[0].Error[1].Text, "code 137")
+
+	require.Equal(t, "default/deadline", results[1].Name)
+	require.Len(t, results[1].Error, 1)
+	require.Equal(t, common.CodeJobDeadlineExceeded, results[1].Error[0].Code)
+
+	// only the failed pod is reported, the Job is not stuck while it waits
+	// to retry
+	require.Equal(t, "default/retrying", results[2].Name)
+	require.Len(t, results[2].Error, 1)
+	require.Equal(t, common.CodeJobPodFailed, results[2].Error[0].Code)
+
+	require.Equal(t, "default/stuck", results[3].Name)
+	require.Len(t, results[3].Error, 1)
+	require.Equal(t, common.CodeJobNoActivePods, results[3].Error[0].Code)
+}
+
+func TestJobPodsInvalidSelector(t *testing.T) {
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "default"},
+		Spec: batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "job-name", Operator: "Matches"},
+		}}},
+	}
+
+	_, err := jobPods(common.Analyzer{
+		Client:  &kubernetes.Client{Client: fake.NewSimpleClientset()},
+		Context: context.Background(),
+	}, job, map[string][]v1.Pod{})
+	require.ErrorContains(t, err, "job default/broken has an invalid selector")
+}
+
+func TestJobBackoffDelay(t *testing.T) {
+	require.Equal(t, time.Duration(0), jobBackoffDelay(0))
+	require.Equal(t, 10*time.Second, jobBackoffDelay(1))
+	require.Equal(t, 80*time.Second, jobBackoffDelay(4))
+	require.Equal(t, 6*time.Minute, jobBackoffDelay(7))
+	require.Equal(t, 6*time.Minute, jobBackoffDelay(100))
+}
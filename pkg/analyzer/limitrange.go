@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// LimitRangeAnalyzer is an analyzer that checks for LimitRanges whose defaults
+// violate their own bounds and for containers whose requests or limits they
+// reject
+type LimitRangeAnalyzer struct{}
+
+// Analyze scans the LimitRanges of the analyzed namespaces and the pod
+// templates of the workloads they apply to
+func (LimitRangeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "LimitRange"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().CoreV1().LimitRanges(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	templates, err := listWorkloadTemplates(a)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := listLimitRangeDefaults(a)
+	if err != nil {
+		return nil, err
+	}
+
+	var preAnalysis = map[string]common.PreAnalysis{}
+
+	for _, limitRange := range list.Items {
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: limitRange.Namespace,
+				Masked:   util.MaskString(limitRange.Namespace),
+			},
+			{
+				Unmasked: limitRange.Name,
+				Masked:   util.MaskString(limitRange.Name),
+			},
+		}
+
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != v1.LimitTypeContainer {
+				continue
+			}
+			for _, problem := range limitRangeItemProblems(item) {
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("LimitRange %s is inconsistent: %s", limitRange.Name, problem),
+					KubernetesDoc: apiDoc.GetApiDocV2("spec.limits"),
+					Code:          common.CodeLimitRangeInvalidDefault,
+					Sensitive:     sensitive,
+				})
+			}
+
+			for _, workload := range templates {
+				if workload.meta.Namespace != limitRange.Namespace {
+					continue
+				}
+				name := fmt.Sprintf("%s/%s", workload.meta.Namespace, workload.meta.Name)
+				for _, container := range append(append([]v1.Container{}, workload.spec.InitContainers...), workload.spec.Containers...) {
+					requests, limits := containerResources(container, defaults[workload.meta.Namespace])
+					for _, problem := range containerLimitProblems(item, requests, limits) {
+						failures = append(failures, common.Failure{
+							Text:      fmt.Sprintf("LimitRange %s rejects the pods of %s %s, container %s %s", limitRange.Name, workload.kind, name, container.Name, problem),
+							Code:      common.CodeLimitRangeConflict,
+							Container: container.Name,
+							Reference: &common.ObjectReference{Kind: workload.kind, Namespace: workload.meta.Namespace, Name: workload.meta.Name},
+							Sensitive: append(sensitive, common.Sensitive{
+								Unmasked: workload.meta.Name,
+								Masked:   util.MaskString(workload.meta.Name),
+							}),
+						})
+					}
+				}
+			}
+		}
+
+		if len(failures) > 0 {
+			preAnalysis[fmt.Sprintf("%s/%s", limitRange.Namespace, limitRange.Name)] = common.PreAnalysis{
+				FailureDetails: failures,
+			}
+			AnalyzerErrorsMetric.WithLabelValues(kind, limitRange.Name, limitRange.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	for key, value := range preAnalysis {
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		})
+	}
+
+	return a.Results, nil
+}
+
+// limitRangeItemProblems returns the defaults of a LimitRange item that
+// violate its own minimum, maximum or each other.
+func limitRangeItemProblems(item v1.LimitRangeItem) []string {
+	var problems []string
+	for _, name := range sortedResourceNames(item.Default) {
+		limit := item.Default[name]
+		if max, ok := item.Max[name]; ok && limit.Cmp(max) > 0 {
+			problems = append(problems, fmt.Sprintf("the default %s limit %s is above the maximum %s", name, limit.String(), max.String()))
+		}
+		if request, ok := item.DefaultRequest[name]; ok && request.Cmp(limit) > 0 {
+			problems = append(problems, fmt.Sprintf("the default %s request %s is above the default limit %s", name, request.String(), limit.String()))
+		}
+	}
+	for _, name := range sortedResourceNames(item.DefaultRequest) {
+		request := item.DefaultRequest[name]
+		if min, ok := item.Min[name]; ok && request.Cmp(min) < 0 {
+			problems = append(problems, fmt.Sprintf("the default %s request %s is below the minimum %s", name, request.String(), min.String()))
+		}
+	}
+	return problems
+}
+
+// containerLimitProblems returns the bounds of a LimitRange item the requests
+// and limits of a container violate.
+func containerLimitProblems(item v1.LimitRangeItem, requests v1.ResourceList, limits v1.ResourceList) []string {
+	var problems []string
+	for _, name := range sortedResourceNames(item.Min) {
+		min := item.Min[name]
+		if request, ok := requests[name]; !ok {
+			problems = append(problems, fmt.Sprintf("sets no %s request but the minimum is %s", name, min.String()))
+		} else if request.Cmp(min) < 0 {
+			problems = append(problems, fmt.Sprintf("requests %s %s, below the minimum %s", request.String(), name, min.String()))
+		}
+	}
+	for _, name := range sortedResourceNames(item.Max) {
+		max := item.Max[name]
+		if limit, ok := limits[name]; !ok {
+			problems = append(problems, fmt.Sprintf("sets no %s limit but the maximum is %s", name, max.String()))
+		} else if limit.Cmp(max) > 0 {
+			problems = append(problems, fmt.Sprintf("limits %s to %s, above the maximum %s", name, limit.String(), max.String()))
+		}
+	}
+	for _, name := range sortedResourceNames(item.MaxLimitRequestRatio) {
+		ratio := item.MaxLimitRequestRatio[name]
+		request, hasRequest := requests[name]
+		limit, hasLimit := limits[name]
+		if !hasRequest || !hasLimit || request.IsZero() {
+			continue
+		}
+		if actual := limit.AsApproximateFloat64() / request.AsApproximateFloat64(); actual > ratio.AsApproximateFloat64() {
+			problems = append(problems, fmt.Sprintf("has a %s limit to request ratio of %.1f, above the maximum %s", name, actual, ratio.String()))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLimitRangeAnalyzer(t *testing.T) {
+	limits := v1.LimitRangeItem{
+		Type:                 v1.LimitTypeContainer,
+		Min:                  v1.ResourceList{v1.ResourceMemory: resource.MustParse("64Mi")},
+		Max:                  v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		Default:              v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+		DefaultRequest:       v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("128Mi")},
+		MaxLimitRequestRatio: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+	}
+
+	tests := []struct {
+		name      string
+		item      v1.LimitRangeItem
+		resources v1.ResourceRequirements
+		wantCodes []string
+		wantText  string
+	}{
+		{
+			name: "within bounds",
+			item: limits,
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			},
+		},
+		{
+			name: "above maximum",
+			item: limits,
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")},
+			},
+			wantCodes: []string{common.CodeLimitRangeConflict},
+			wantText:  "LimitRange limits rejects the pods of Deployment default/web, container app limits cpu to 3, above the maximum 2",
+		},
+		{
+			name: "below minimum and above ratio",
+			item: limits,
+			resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("32Mi")},
+			},
+			wantCodes: []string{common.CodeLimitRangeConflict, common.CodeLimitRangeConflict},
+		},
+		{
+			name: "inconsistent defaults",
+			item: v1.LimitRangeItem{
+				Type:           v1.LimitTypeContainer,
+				Max:            v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				Default:        v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+				DefaultRequest: v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")},
+			},
+			wantCodes: []string{common.CodeLimitRangeInvalidDefault, common.CodeLimitRangeInvalidDefault, common.CodeLimitRangeConflict},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(
+				&v1.LimitRange{
+					ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "default"},
+					Spec:       v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{tt.item}},
+				},
+				quotaDeployment("web", 1, 1, tt.resources),
+			)
+
+			results, err := LimitRangeAnalyzer{}.Analyze(common.Analyzer{
+				Client:  &kubernetes.Client{Client: clientset},
+				Context: context.Background(),
+			})
+			require.NoError(t, err)
+			if len(tt.wantCodes) == 0 {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			var codes []string
+			for _, failure := range results[0].Error {
+				codes = append(codes, failure.Code)
+			}
+			require.Equal(t, tt.wantCodes, codes)
+			if tt.wantText != "" {
+				require.Equal(t, tt.wantText, results[0].Error[0].Text)
+				require.Equal(t, "app", results[0].Error[0].Container)
+			}
+		})
+	}
+}
@@ -66,6 +66,8 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 							Masked:   util.MaskString(pod.Name),
 						},
 					},
+					Code:      common.CodeLogFetchFailed,
+					Container: c.Name,
 				})
 			} else {
 				rawlogs := string(podLogs)
@@ -78,6 +80,8 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 								Masked:   util.MaskString(pod.Name),
 							},
 						},
+						Code:      common.CodeLogErrors,
+						Container: c.Name,
 					})
 				}
 			}
@@ -57,13 +57,18 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 				continue
 			}
 			svc := webhook.ClientConfig.Service
-			// Get the service
-			service, err := a.Client.GetClient().CoreV1().Services(svc.Namespace).Get(context.Background(), svc.Name, v1.GetOptions{})
+			// Get the service and its pods
+			backend, err := getServiceBackend(a, svc.Namespace, svc.Name)
 			if err != nil {
+				return nil, err
+			}
+			if !backend.Found {
 				// If the service is not found, we can't check the pods
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("Service %s not found as mapped to by Mutating Webhook %s", svc.Name, webhook.Name),
 					KubernetesDoc: apiDoc.GetApiDocV2("spec.webhook.clientConfig.service"),
+					Code:          common.CodeWebhookServiceNotFound,
+					Reference:     &common.ObjectReference{Kind: "Service", Namespace: svc.Namespace, Name: svc.Name},
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: webhookConfig.Namespace,
@@ -84,21 +89,16 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 			}
 
 			// When Service selectors are empty we defer to service analyser
-			if len(service.Spec.Selector) == 0 {
+			if !backend.Selected {
 				continue
 			}
-			// Get pods within service
-			pods, err := a.Client.GetClient().CoreV1().Pods(svc.Namespace).List(context.Background(), v1.ListOptions{
-				LabelSelector: util.MapToString(service.Spec.Selector),
-			})
-			if err != nil {
-				return nil, err
-			}
 
-			if len(pods.Items) == 0 {
+			if len(backend.Pods) == 0 {
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("No active pods found within service %s as mapped to by Mutating Webhook %s", svc.Name, webhook.Name),
 					KubernetesDoc: apiDoc.GetApiDocV2("spec.webhook.clientConfig.service"),
+					Code:          common.CodeWebhookNoActivePods,
+					Reference:     &common.ObjectReference{Kind: "Service", Namespace: svc.Namespace, Name: svc.Name},
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: webhookConfig.Namespace,
@@ -108,32 +108,32 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 				})
 
 			}
-			for _, pod := range pods.Items {
-				if pod.Status.Phase != "Running" {
-					doc := apiDoc.GetApiDocV2("spec.webhook")
-					failures = append(failures, common.Failure{
-						Text: fmt.Sprintf(
-							"Mutating Webhook (%s) is pointing to an inactive receiver pod (%s)",
-							webhook.Name,
-							pod.Name,
-						),
-						KubernetesDoc: doc,
-						Sensitive: []common.Sensitive{
-							{
-								Unmasked: webhookConfig.Namespace,
-								Masked:   util.MaskString(webhookConfig.Namespace),
-							},
-							{
-								Unmasked: webhook.Name,
-								Masked:   util.MaskString(webhook.Name),
-							},
-							{
-								Unmasked: pod.Name,
-								Masked:   util.MaskString(pod.Name),
-							},
+			for _, pod := range backend.inactivePods() {
+				doc := apiDoc.GetApiDocV2("spec.webhook")
+				failures = append(failures, common.Failure{
+					Text: fmt.Sprintf(
+						"Mutating Webhook (%s) is pointing to an inactive receiver pod (%s)",
+						webhook.Name,
+						pod.Name,
+					),
+					KubernetesDoc: doc,
+					Code:          common.CodeWebhookInactivePod,
+					Reference:     &common.ObjectReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name},
+					Sensitive: []common.Sensitive{
+						{
+							Unmasked: webhookConfig.Namespace,
+							Masked:   util.MaskString(webhookConfig.Namespace),
 						},
-					})
-				}
+						{
+							Unmasked: webhook.Name,
+							Masked:   util.MaskString(webhook.Name),
+						},
+						{
+							Unmasked: pod.Name,
+							Masked:   util.MaskString(pod.Name),
+						},
+					},
+				})
 			}
 			if len(failures) > 0 {
 				preAnalysis[fmt.Sprintf("%s/%s", webhookConfig.Namespace, webhook.Name)] = common.PreAnalysis{
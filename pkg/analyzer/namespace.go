@@ -0,0 +1,252 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deletionStuckAfter is how long a namespace or an object may take to be
+// deleted before it is reported as stuck.
+const deletionStuckAfter = 5 * time.Minute
+
+// maxListedObjects is the number of blocking objects listed in the failure of
+// a namespace.
+const maxListedObjects = 10
+
+// stuckObject is an object that is being deleted but still has finalizers.
+type stuckObject struct {
+	Kind       string
+	Namespace  string
+	Name       string
+	Finalizers []string
+	DeletedAt  time.Time
+}
+
+func (o stuckObject) String() string {
+	return fmt.Sprintf("%s %s (finalizers %s)", o.Kind, o.Name, strings.Join(o.Finalizers, ", "))
+}
+
+// NamespaceAnalyzer is an analyzer that checks for namespaces stuck in the
+// Terminating phase and for the objects blocking their deletion
+type NamespaceAnalyzer struct{}
+
+// Analyze scans the namespaces and, through discovery, the objects left in
+// those stuck Terminating
+func (NamespaceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "Namespace"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().CoreV1().Namespaces().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var stuck []v1.Namespace
+	var names []string
+	for _, ns := range list.Items {
+		if ns.Status.Phase != v1.NamespaceTerminating || (a.Namespace != "" && ns.Name != a.Namespace) {
+			continue
+		}
+		if ns.DeletionTimestamp == nil || now.Sub(ns.DeletionTimestamp.Time) < deletionStuckAfter {
+			continue
+		}
+		stuck = append(stuck, ns)
+		names = append(names, ns.Name)
+	}
+	if len(stuck) == 0 {
+		return nil, nil
+	}
+	unavailable, err := unavailableAPIServices(a)
+	if err != nil {
+		return nil, err
+	}
+	// the namespaces are still reported when some resources cannot be listed
+	objects, listErr := stuckObjects(a, names, now)
+
+	for _, ns := range stuck {
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: ns.Name,
+				Masked:   util.MaskString(ns.Name),
+			},
+		}
+
+		text := fmt.Sprintf("Namespace %s has been Terminating since %s", ns.Name, ns.DeletionTimestamp.UTC().Format(time.RFC3339))
+		if len(ns.Spec.Finalizers) > 0 {
+			var finalizers []string
+			for _, finalizer := range ns.Spec.Finalizers {
+				finalizers = append(finalizers, string(finalizer))
+			}
+			text += fmt.Sprintf(", its finalizers %s remain", strings.Join(finalizers, ", "))
+		}
+		failures = append(failures, common.Failure{
+			Text:          text,
+			KubernetesDoc: apiDoc.GetApiDocV2("spec.finalizers"),
+			Code:          common.CodeNamespaceTerminating,
+			Sensitive:     sensitive,
+		})
+
+		for _, condition := range ns.Status.Conditions {
+			if condition.Status != v1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case v1.NamespaceDeletionContentFailure, v1.NamespaceDeletionDiscoveryFailure, v1.NamespaceDeletionGVParsingFailure:
+				failures = append(failures, common.Failure{
+					Text:      fmt.Sprintf("Deleting the content of namespace %s failed: %s", ns.Name, condition.Message),
+					Code:      common.CodeNamespaceDeletionFailed,
+					Reason:    condition.Reason,
+					Sensitive: sensitive,
+				})
+			case v1.NamespaceFinalizersRemaining:
+				failures = append(failures, common.Failure{
+					Text:      fmt.Sprintf("Namespace %s cannot be deleted: %s", ns.Name, condition.Message),
+					Code:      common.CodeNamespaceFinalizersRemaining,
+					Reason:    condition.Reason,
+					Sensitive: sensitive,
+				})
+			}
+		}
+
+		var remaining []string
+		count := 0
+		for _, object := range objects {
+			if object.Namespace != ns.Name {
+				continue
+			}
+			count++
+			if len(remaining) < maxListedObjects {
+				remaining = append(remaining, object.String())
+			}
+		}
+		if count > 0 {
+			text := fmt.Sprintf("%d objects in namespace %s wait for their finalizers to be removed: %s", count, ns.Name, strings.Join(remaining, "; "))
+			if count > maxListedObjects {
+				text += fmt.Sprintf(" and %d more", count-maxListedObjects)
+			}
+			failures = append(failures, common.Failure{
+				Text:      text,
+				Code:      common.CodeNamespaceObjectsRemaining,
+				Sensitive: sensitive,
+			})
+		}
+
+		for _, service := range unavailable {
+			_, message := service.available()
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("APIService %s is unavailable (%s), the resources of %s/%s cannot be discovered and deleted from namespace %s", service.Name, message, service.Spec.Group, service.Spec.Version, ns.Name),
+				Code:      common.CodeNamespaceAPIServiceUnavailable,
+				Reference: &common.ObjectReference{Kind: "APIService", Name: service.Name},
+				Sensitive: sensitive,
+			})
+		}
+
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  ns.Name,
+			Error: failures,
+		})
+		AnalyzerErrorsMetric.WithLabelValues(kind, ns.Name, "").Set(float64(len(failures)))
+	}
+
+	return a.Results, listErr
+}
+
+// stuckObjects returns the objects of the given namespaces, all of them for
+// "", that were deleted more than deletionStuckAfter ago but still have
+// finalizers. Only the metadata of the objects is read. The objects found are
+// returned along with the errors of the resources that cannot be listed.
+func stuckObjects(a common.Analyzer, namespaces []string, now time.Time) ([]stuckObject, error) {
+	client := a.Client.CtrlClient
+	if client == nil || len(namespaces) == 0 {
+		return nil, nil
+	}
+	resourceLists, err := discovery.ServerPreferredNamespacedResources(a.Client.GetClient().Discovery())
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	var objects []stuckObject
+	var errs []error
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") || !slices.Contains(resource.Verbs, "list") {
+				continue
+			}
+			for _, namespace := range namespaces {
+				list := &metav1.PartialObjectMetadataList{}
+				list.SetGroupVersionKind(gv.WithKind(resource.Kind + "List"))
+				if err := client.List(a.Context, list, ctrl.InNamespace(namespace)); err != nil {
+					errs = append(errs, fmt.Errorf("listing %s: %w", gv.WithResource(resource.Name), err))
+					continue
+				}
+				for _, item := range list.Items {
+					if item.DeletionTimestamp == nil || len(item.Finalizers) == 0 || now.Sub(item.DeletionTimestamp.Time) < deletionStuckAfter {
+						continue
+					}
+					objects = append(objects, stuckObject{
+						Kind:       resource.Kind,
+						Namespace:  item.Namespace,
+						Name:       item.Name,
+						Finalizers: item.Finalizers,
+						DeletedAt:  item.DeletionTimestamp.Time,
+					})
+				}
+			}
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Namespace != objects[j].Namespace {
+			return objects[i].Namespace < objects[j].Namespace
+		}
+		if objects[i].Kind != objects[j].Kind {
+			return objects[i].Kind < objects[j].Kind
+		}
+		return objects[i].Name < objects[j].Name
+	})
+	return objects, errors.Join(errs...)
+}
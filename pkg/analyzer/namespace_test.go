@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newAPIService(name string, service bool, available metav1.ConditionStatus) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       map[string]interface{}{"group": "metrics.k8s.io", "version": "v1beta1"},
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Available", "status": string(available), "reason": "FailedDiscoveryCheck", "message": "no response from https://10.0.0.1:443"},
+		}},
+	}}
+	if service {
+		u.Object["spec"].(map[string]interface{})["service"] = map[string]interface{}{"namespace": "kube-system", "name": "metrics-server"}
+	}
+	return u
+}
+
+// newStuckDeletionClients returns a cluster with a namespace stuck
+// Terminating, one that just started terminating and an active one, each with
+// a ConfigMap waiting for its finalizer.
+func newStuckDeletionClients(funcs interceptor.Funcs) (*fake.Clientset, ctrl.Client) {
+	deleted := metav1.NewTime(time.Now().Add(-time.Hour))
+	justDeleted := metav1.NewTime(time.Now())
+	clientset := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Status: v1.NamespaceStatus{Phase: v1.NamespaceActive}},
+		&v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "old-app", DeletionTimestamp: &deleted},
+			Spec:       v1.NamespaceSpec{Finalizers: []v1.FinalizerName{v1.FinalizerKubernetes}},
+			Status: v1.NamespaceStatus{
+				Phase: v1.NamespaceTerminating,
+				Conditions: []v1.NamespaceCondition{
+					{Type: v1.NamespaceDeletionContentFailure, Status: v1.ConditionFalse, Reason: "ContentDeleted"},
+					{Type: v1.NamespaceDeletionDiscoveryFailure, Status: v1.ConditionTrue, Reason: "DiscoveryFailed", Message: "Discovery failed for some groups, 1 failing: unable to retrieve the complete list of server APIs: metrics.k8s.io/v1beta1"},
+					{Type: v1.NamespaceFinalizersRemaining, Status: v1.ConditionTrue, Reason: "SomeFinalizersRemain", Message: "Some content in the namespace has finalizers remaining: example.com/cleanup in 1 resource instances"},
+				},
+			},
+		},
+		&v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "new-app", DeletionTimestamp: &justDeleted},
+			Status:     v1.NamespaceStatus{Phase: v1.NamespaceTerminating},
+		},
+	)
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: metav1.Verbs{"list", "delete"}},
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"list", "delete"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+				{Name: "namespaces", Kind: "Namespace", Verbs: metav1.Verbs{"list", "delete"}},
+			},
+		},
+	}
+	stuck := func(namespace, name string, deletedAt metav1.Time) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: namespace, DeletionTimestamp: &deletedAt, Finalizers: []string{"example.com/cleanup"},
+		}}
+	}
+	ctrlClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		stuck("old-app", "settings", deleted),
+		stuck("new-app", "settings", deleted),
+		stuck("default", "orphan", deleted),
+		stuck("default", "deleting", justDeleted),
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		newAPIService("v1beta1.metrics.k8s.io", true, metav1.ConditionFalse),
+		newAPIService("v1.apps", false, metav1.ConditionTrue),
+	).WithInterceptorFuncs(funcs).Build()
+	return clientset, ctrlClient
+}
+
+func TestNamespaceAnalyzer(t *testing.T) {
+	clientset, ctrlClient := newStuckDeletionClients(interceptor.Funcs{})
+	results, err := NamespaceAnalyzer{}.Analyze(common.Analyzer{
+		Client:  &kubernetes.Client{Client: clientset, CtrlClient: ctrlClient},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	require.Equal(t, "Namespace", results[0].Kind)
+	require.Equal(t, "old-app", results[0].Name)
+	var codes []string
+	for _, failure := range results[0].Error {
+		codes = append(codes, failure.Code)
+	}
+	require.Equal(t, []string{
+		common.CodeNamespaceTerminating,
+		common.CodeNamespaceDeletionFailed,
+		common.CodeNamespaceFinalizersRemaining,
+		common.CodeNamespaceObjectsRemaining,
+		common.CodeNamespaceAPIServiceUnavailable,
+	}, codes)
+	require.Contains(t, results[0].Error[0].Text, "its finalizers kubernetes remain")
+	require.Contains(t, results[0].Error[3].Text, "ConfigMap settings (finalizers example.com/cleanup)")
+	require.Equal(t, "v1beta1.metrics.k8s.io", results[0].Error[4].Reference.Name)
+}
+
+func TestNamespaceAnalyzerListError(t *testing.T) {
+	clientset, ctrlClient := newStuckDeletionClients(interceptor.Funcs{
+		List: func(ctx context.Context, client ctrl.WithWatch, list ctrl.ObjectList, opts ...ctrl.ListOption) error {
+			if list.GetObjectKind().GroupVersionKind().Kind == "PodList" {
+				return errors.New("pods is forbidden")
+			}
+			return client.List(ctx, list, opts...)
+		},
+	})
+	results, err := NamespaceAnalyzer{}.Analyze(common.Analyzer{
+		Client:  &kubernetes.Client{Client: clientset, CtrlClient: ctrlClient},
+		Context: context.Background(),
+	})
+	require.ErrorContains(t, err, "listing /v1, Resource=pods: pods is forbidden")
+	require.Len(t, results, 1)
+	require.Equal(t, "old-app", results[0].Name)
+	require.Equal(t, common.CodeNamespaceObjectsRemaining, results[0].Error[3].Code)
+}
+
+func TestNamespaceAnalyzerNamespaceFilter(t *testing.T) {
+	deleted := metav1.NewTime(time.Now().Add(-time.Hour))
+	clientset := fake.NewSimpleClientset(
+		&v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "old-app", DeletionTimestamp: &deleted},
+			Status:     v1.NamespaceStatus{Phase: v1.NamespaceTerminating},
+		},
+	)
+
+	results, err := NamespaceAnalyzer{}.Analyze(common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset},
+		Context:   context.Background(),
+		Namespace: "default",
+	})
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
@@ -60,6 +60,7 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 			failures = append(failures, common.Failure{
 				Text:          fmt.Sprintf("Network policy allows traffic to all pods: %s", policy.Name),
 				KubernetesDoc: doc,
+				Code:          common.CodeNetworkPolicyAllowsAll,
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: policy.Name,
@@ -76,6 +77,7 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 			if len(podList.Items) == 0 {
 				failures = append(failures, common.Failure{
 					Text: fmt.Sprintf("Network policy is not applied to any pods: %s", policy.Name),
+					Code: common.CodeNetworkPolicyNoPods,
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: policy.Name,
@@ -85,6 +85,10 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 }
 
 func addNodeConditionFailure(failures []common.Failure, nodeName string, nodeCondition v1.NodeCondition) []common.Failure {
+	code := common.CodeNodeCondition
+	if nodeCondition.Type == v1.NodeReady {
+		code = common.CodeNodeNotReady
+	}
 	failures = append(failures, common.Failure{
 		Text: fmt.Sprintf("%s has condition of type %s, reason %s: %s", nodeName, nodeCondition.Type, nodeCondition.Reason, nodeCondition.Message),
 		Sensitive: []common.Sensitive{
@@ -93,6 +97,8 @@ func addNodeConditionFailure(failures []common.Failure, nodeName string, nodeCon
 				Masked:   util.MaskString(nodeName),
 			},
 		},
+		Code:   code,
+		Reason: nodeCondition.Reason,
 	})
 	return failures
 }
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// nodeSelectorOperators maps the operators of node selector requirements to
+// those of label selectors.
+var nodeSelectorOperators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// nodeSelectorMatches reports whether a node satisfies any of the terms of a
+// node selector.
+func nodeSelectorMatches(node v1.Node, selector *v1.NodeSelector) bool {
+	nodeFields := fields.Set{"metadata.name": node.Name}
+	for _, term := range selector.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if requirementsMatch(term.MatchExpressions, labels.Set(node.Labels)) && requirementsMatch(term.MatchFields, labels.Set(nodeFields)) {
+			return true
+		}
+	}
+	return false
+}
+
+func requirementsMatch(requirements []v1.NodeSelectorRequirement, set labels.Set) bool {
+	for _, requirement := range requirements {
+		operator, ok := nodeSelectorOperators[requirement.Operator]
+		if !ok {
+			return false
+		}
+		r, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil || !r.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
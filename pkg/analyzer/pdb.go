@@ -68,6 +68,7 @@ func (PdbAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					failures = append(failures, common.Failure{
 						Text:          fmt.Sprintf("%s, expected pdb pod label %s=%s", pdb.Status.Conditions[0].Reason, k, v),
 						KubernetesDoc: doc,
+						Code:          common.CodePDBNoMatchingPods,
 						Sensitive: []common.Sensitive{
 							{
 								Unmasked: k,
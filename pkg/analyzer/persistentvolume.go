@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// provisionedByAnnotation is set on PersistentVolumes created by a provisioner.
+const provisionedByAnnotation = "pv.kubernetes.io/provisioned-by"
+
+// PersistentVolumeAnalyzer is an analyzer that checks for released, failed
+// and unschedulable PersistentVolumes, and for bound claims no pod mounts
+type PersistentVolumeAnalyzer struct{}
+
+// Analyze scans the PersistentVolumes of the cluster, or those claimed from
+// the analyzed namespace, and the PersistentVolumeClaims of all namespaces
+func (PersistentVolumeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "PersistentVolume"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().CoreV1().PersistentVolumes().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := a.Client.GetClient().CoreV1().Nodes().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pv := range list.Items {
+		if a.Namespace != "" && (pv.Spec.ClaimRef == nil || pv.Spec.ClaimRef.Namespace != a.Namespace) {
+			continue
+		}
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: pv.Name,
+				Masked:   util.MaskString(pv.Name),
+			},
+		}
+		claim := ""
+		if pv.Spec.ClaimRef != nil {
+			claim = fmt.Sprintf("%s/%s", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+			sensitive = append(sensitive, common.Sensitive{
+				Unmasked: pv.Spec.ClaimRef.Name,
+				Masked:   util.MaskString(pv.Spec.ClaimRef.Name),
+			})
+		}
+
+		switch pv.Status.Phase {
+		case v1.VolumeReleased:
+			if pv.Spec.PersistentVolumeReclaimPolicy == v1.PersistentVolumeReclaimRetain {
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("PersistentVolume %s is Released, its claim %s was deleted and the Retain reclaim policy keeps the volume, but no claim can bind it", pv.Name, claim),
+					KubernetesDoc: apiDoc.GetApiDocV2("spec.persistentVolumeReclaimPolicy"),
+					Code:          common.CodePVReleased,
+					Sensitive:     sensitive,
+				})
+			} else {
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("PersistentVolume %s is Released and not reclaimed although its reclaim policy is %s: %s", pv.Name, pv.Spec.PersistentVolumeReclaimPolicy, pv.Status.Message),
+					KubernetesDoc: apiDoc.GetApiDocV2("spec.persistentVolumeReclaimPolicy"),
+					Code:          common.CodePVReclaimStuck,
+					Sensitive:     sensitive,
+				})
+			}
+		case v1.VolumeFailed:
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("PersistentVolume %s has failed: %s %s", pv.Name, pv.Status.Reason, pv.Status.Message),
+				Code:      common.CodePVFailed,
+				Reason:    pv.Status.Reason,
+				Sensitive: sensitive,
+			})
+		}
+
+		switch {
+		case pv.Spec.PersistentVolumeReclaimPolicy == v1.PersistentVolumeReclaimRecycle:
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("PersistentVolume %s uses the deprecated Recycle reclaim policy", pv.Name),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.persistentVolumeReclaimPolicy"),
+				Code:          common.CodePVReclaimPolicy,
+				Sensitive:     sensitive,
+			})
+		case pv.Spec.PersistentVolumeReclaimPolicy == v1.PersistentVolumeReclaimDelete &&
+			pv.Annotations[provisionedByAnnotation] == "" && pv.Status.Phase != v1.VolumeReleased:
+			failures = append(failures, common.Failure{
+				Text:          fmt.Sprintf("PersistentVolume %s was created by hand but has the reclaim policy Delete, deleting its claim deletes the storage and its data", pv.Name),
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.persistentVolumeReclaimPolicy"),
+				Code:          common.CodePVReclaimPolicy,
+				Sensitive:     sensitive,
+			})
+		}
+
+		if affinity := pv.Spec.NodeAffinity; affinity != nil && affinity.Required != nil && len(nodes.Items) > 0 {
+			matched := false
+			for _, node := range nodes.Items {
+				if nodeSelectorMatches(node, affinity.Required) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				failures = append(failures, common.Failure{
+					Text:          fmt.Sprintf("No node matches the node affinity of PersistentVolume %s, pods using it cannot be scheduled", pv.Name),
+					KubernetesDoc: apiDoc.GetApiDocV2("spec.nodeAffinity"),
+					Code:          common.CodePVNodeAffinityUnsatisfiable,
+					Sensitive:     sensitive,
+				})
+			}
+		}
+
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  kind,
+				Name:  pv.Name,
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, pv.Name, "").Set(float64(len(failures)))
+		}
+	}
+
+	unmounted, err := unmountedClaims(a)
+	if err != nil {
+		return nil, err
+	}
+	return append(a.Results, unmounted...), nil
+}
+
+// unmountedClaims reports the bound PersistentVolumeClaims no pod mounts.
+func unmountedClaims(a common.Analyzer) ([]common.Result, error) {
+	pvcs, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := a.Client.GetClient().CoreV1().Pods(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	mounted := map[string]bool{}
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				mounted[pod.Namespace+"/"+volume.PersistentVolumeClaim.ClaimName] = true
+			}
+			// generic ephemeral volumes are backed by a claim named after the pod
+			if volume.Ephemeral != nil {
+				mounted[fmt.Sprintf("%s/%s-%s", pod.Namespace, pod.Name, volume.Name)] = true
+			}
+		}
+	}
+
+	var results []common.Result
+	for _, pvc := range pvcs.Items {
+		key := fmt.Sprintf("%s/%s", pvc.Namespace, pvc.Name)
+		if pvc.Status.Phase != v1.ClaimBound || mounted[key] {
+			continue
+		}
+		results = append(results, common.Result{
+			Kind: "PersistentVolumeClaim",
+			Name: key,
+			Error: []common.Failure{
+				{
+					Text:      fmt.Sprintf("PersistentVolumeClaim %s is bound to PersistentVolume %s but no pod mounts it", key, pvc.Spec.VolumeName),
+					Code:      common.CodePVCNotMounted,
+					Reference: &common.ObjectReference{Kind: "PersistentVolume", Name: pvc.Spec.VolumeName},
+					Sensitive: []common.Sensitive{
+						{
+							Unmasked: pvc.Namespace,
+							Masked:   util.MaskString(pvc.Namespace),
+						},
+						{
+							Unmasked: pvc.Name,
+							Masked:   util.MaskString(pvc.Name),
+						},
+					},
+				},
+			},
+		})
+		AnalyzerErrorsMetric.WithLabelValues("PersistentVolume", pvc.Name, pvc.Namespace).Set(1)
+	}
+	return results, nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPersistentVolumeAnalyzer(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"zone": "a"}}}
+	provisioned := map[string]string{provisionedByAnnotation: "ebs.csi.aws.com"}
+	affinity := func(key string, values ...string) *v1.VolumeNodeAffinity {
+		return &v1.VolumeNodeAffinity{Required: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{
+			MatchExpressions: []v1.NodeSelectorRequirement{{Key: key, Operator: v1.NodeSelectorOpIn, Values: values}},
+		}}}}
+	}
+
+	tests := []struct {
+		name      string
+		pv        v1.PersistentVolume
+		wantCodes []string
+	}{
+		{
+			name: "bound",
+			pv: v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Annotations: provisioned},
+				Spec:       v1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete},
+				Status:     v1.PersistentVolumeStatus{Phase: v1.VolumeBound},
+			},
+		},
+		{
+			name: "released and retained",
+			pv: v1.PersistentVolume{
+				Spec:   v1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain},
+				Status: v1.PersistentVolumeStatus{Phase: v1.VolumeReleased},
+			},
+			wantCodes: []string{common.CodePVReleased},
+		},
+		{
+			name: "released and not deleted",
+			pv: v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Annotations: provisioned},
+				Spec:       v1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete},
+				Status:     v1.PersistentVolumeStatus{Phase: v1.VolumeReleased, Message: "error deleting volume"},
+			},
+			wantCodes: []string{common.CodePVReclaimStuck},
+		},
+		{
+			name: "failed recycle",
+			pv: v1.PersistentVolume{
+				Spec:   v1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRecycle},
+				Status: v1.PersistentVolumeStatus{Phase: v1.VolumeFailed, Reason: "VolumeFailedRecycle"},
+			},
+			wantCodes: []string{common.CodePVFailed, common.CodePVReclaimPolicy},
+		},
+		{
+			name: "static volume deleted with its claim",
+			pv: v1.PersistentVolume{
+				Spec:   v1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete},
+				Status: v1.PersistentVolumeStatus{Phase: v1.VolumeAvailable},
+			},
+			wantCodes: []string{common.CodePVReclaimPolicy},
+		},
+		{
+			name: "node affinity matching a node",
+			pv: v1.PersistentVolume{
+				Spec: v1.PersistentVolumeSpec{
+					PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+					NodeAffinity:                  affinity("zone", "a", "b"),
+				},
+				Status: v1.PersistentVolumeStatus{Phase: v1.VolumeAvailable},
+			},
+		},
+		{
+			name: "node affinity matching no node",
+			pv: v1.PersistentVolume{
+				Spec: v1.PersistentVolumeSpec{
+					PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+					NodeAffinity:                  affinity("kubernetes.io/hostname", "worker-2"),
+				},
+				Status: v1.PersistentVolumeStatus{Phase: v1.VolumeAvailable},
+			},
+			wantCodes: []string{common.CodePVNodeAffinityUnsatisfiable},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.pv.Name = "data"
+			clientset := fake.NewSimpleClientset(&tt.pv, node)
+
+			results, err := PersistentVolumeAnalyzer{}.Analyze(common.Analyzer{
+				Client:  &kubernetes.Client{Client: clientset},
+				Context: context.Background(),
+			})
+			require.NoError(t, err)
+			if len(tt.wantCodes) == 0 {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			require.Equal(t, "data", results[0].Name)
+			var codes []string
+			for _, failure := range results[0].Error {
+				codes = append(codes, failure.Code)
+			}
+			require.Equal(t, tt.wantCodes, codes)
+		})
+	}
+}
+
+func TestPersistentVolumeAnalyzerUnmountedClaims(t *testing.T) {
+	claim := func(name string, phase v1.PersistentVolumeClaimPhase) runtime.Object {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1.PersistentVolumeClaimSpec{VolumeName: "pv-" + name},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		claim("mounted", v1.ClaimBound),
+		claim("web-cache", v1.ClaimBound),
+		claim("unused", v1.ClaimBound),
+		claim("pending", v1.ClaimPending),
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1.PodSpec{Volumes: []v1.Volume{
+				{Name: "data", VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "mounted"}}},
+				{Name: "cache", VolumeSource: v1.VolumeSource{Ephemeral: &v1.EphemeralVolumeSource{}}},
+			}},
+		},
+	)
+
+	results, err := PersistentVolumeAnalyzer{}.Analyze(common.Analyzer{
+		Client:    &kubernetes.Client{Client: clientset},
+		Context:   context.Background(),
+		Namespace: "default",
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "PersistentVolumeClaim", results[0].Kind)
+	require.Equal(t, "default/unused", results[0].Name)
+	require.Equal(t, common.CodePVCNotMounted, results[0].Error[0].Code)
+	require.Equal(t, "pv-unused", results[0].Error[0].Reference.Name)
+}
+
+func TestNodeSelectorMatches(t *testing.T) {
+	node := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"zone": "a", "disks": "4"}}}
+	tests := []struct {
+		name string
+		term v1.NodeSelectorTerm
+		want bool
+	}{
+		{
+			name: "exists",
+			term: v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpExists}}},
+			want: true,
+		},
+		{
+			name: "not in",
+			term: v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpNotIn, Values: []string{"a"}}}},
+		},
+		{
+			name: "greater than",
+			term: v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "disks", Operator: v1.NodeSelectorOpGt, Values: []string{"2"}}}},
+			want: true,
+		},
+		{
+			name: "match fields",
+			term: v1.NodeSelectorTerm{MatchFields: []v1.NodeSelectorRequirement{{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"worker-1"}}}},
+			want: true,
+		},
+		{
+			name: "empty term",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, nodeSelectorMatches(node, &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{tt.term}}))
+		})
+	}
+}
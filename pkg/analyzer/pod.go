@@ -52,6 +52,8 @@ func (PodAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 						failures = append(failures, common.Failure{
 							Text:      containerStatus.Message,
 							Sensitive: []common.Sensitive{},
+							Code:      common.CodePodUnschedulable,
+							Reason:    containerStatus.Reason,
 						})
 					}
 				}
@@ -107,6 +109,9 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 					failures = append(failures, common.Failure{
 						Text:      evt.Message,
 						Sensitive: []common.Sensitive{},
+						Code:      evtErrorCode(evt.Reason),
+						Container: containerStatus.Name,
+						Reason:    evt.Reason,
 					})
 				}
 			} else if containerStatus.State.Waiting.Reason == "CrashLoopBackOff" && containerStatus.LastTerminationState.Terminated != nil {
@@ -114,11 +119,17 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 				failures = append(failures, common.Failure{
 					Text:      fmt.Sprintf("the last termination reason is %s container=%s pod=%s", containerStatus.LastTerminationState.Terminated.Reason, containerStatus.Name, name),
 					Sensitive: []common.Sensitive{},
+					Code:      common.CodePodCrashLoop,
+					Container: containerStatus.Name,
+					Reason:    containerStatus.LastTerminationState.Terminated.Reason,
 				})
 			} else if isErrorReason(containerStatus.State.Waiting.Reason) && containerStatus.State.Waiting.Message != "" {
 				failures = append(failures, common.Failure{
 					Text:      containerStatus.State.Waiting.Message,
 					Sensitive: []common.Sensitive{},
+					Code:      waitingReasonCode(containerStatus.State.Waiting.Reason),
+					Container: containerStatus.Name,
+					Reason:    containerStatus.State.Waiting.Reason,
 				})
 			}
 		} else {
@@ -133,6 +144,9 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 					failures = append(failures, common.Failure{
 						Text:      evt.Message,
 						Sensitive: []common.Sensitive{},
+						Code:      common.CodePodReadinessProbeFailed,
+						Container: containerStatus.Name,
+						Reason:    evt.Reason,
 					})
 				}
 			}
@@ -168,3 +182,25 @@ func isEvtErrorReason(reason string) bool {
 	}
 	return false
 }
+
+// waitingReasonCode maps the reason a container is waiting for to a failure code.
+func waitingReasonCode(reason string) string {
+	switch reason {
+	case "CrashLoopBackOff":
+		return common.CodePodCrashLoop
+	case "ImagePullBackOff", "ErrImagePull", "ErrImageNeverPull", "InvalidImageName", "ImageInspectError":
+		return common.CodePodImagePullFailed
+	case "CreateContainerConfigError":
+		return common.CodePodContainerConfigError
+	default:
+		return common.CodePodContainerStartFailed
+	}
+}
+
+// evtErrorCode maps the reason of an event matched by isEvtErrorReason to a failure code.
+func evtErrorCode(reason string) string {
+	if reason == "FailedMount" {
+		return common.CodePodMountFailed
+	}
+	return common.CodePodSandboxFailed
+}
@@ -55,6 +55,8 @@ func (PvcAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				failures = append(failures, common.Failure{
 					Text:      evt.Message,
 					Sensitive: []common.Sensitive{},
+					Code:      common.CodePVCProvisioningFailed,
+					Reason:    evt.Reason,
 				})
 			}
 		}
@@ -0,0 +1,355 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// quotaNearLimit is the utilization in percent from which a quota is reported
+// as near its hard limit.
+const quotaNearLimit = 90
+
+// ResourceQuotaAnalyzer is an analyzer that checks for quotas near or at their
+// hard limits and for workloads whose pods the quotas reject
+type ResourceQuotaAnalyzer struct{}
+
+// Analyze scans the ResourceQuotas of the analyzed namespaces and the pod
+// templates of the workloads they apply to
+func (ResourceQuotaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "ResourceQuota"
+	apiDoc := kubernetes.K8sApiReference{
+		Kind: kind,
+		ApiVersion: schema.GroupVersion{
+			Group:   "",
+			Version: "v1",
+		},
+		OpenapiSchema: a.OpenapiSchema,
+	}
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().CoreV1().ResourceQuotas(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	templates, err := listWorkloadTemplates(a)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := listLimitRangeDefaults(a)
+	if err != nil {
+		return nil, err
+	}
+
+	var preAnalysis = map[string]common.PreAnalysis{}
+
+	for _, quota := range list.Items {
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: quota.Namespace,
+				Masked:   util.MaskString(quota.Namespace),
+			},
+			{
+				Unmasked: quota.Name,
+				Masked:   util.MaskString(quota.Name),
+			},
+		}
+
+		utilization := quotaUtilization(quota)
+		for _, name := range sortedResourceNames(quota.Status.Hard) {
+			percent, ok := utilization[string(name)]
+			if !ok || percent < quotaNearLimit {
+				continue
+			}
+			hard, used := quota.Status.Hard[name], quota.Status.Used[name]
+			code, text := common.CodeResourceQuotaNearLimit, fmt.Sprintf("ResourceQuota %s uses %.0f%% of %s (%s of %s)", quota.Name, percent, name, used.String(), hard.String())
+			if percent >= 100 {
+				code, text = common.CodeResourceQuotaAtLimit, text+", new objects using it are rejected"
+			}
+			failures = append(failures, common.Failure{
+				Text:          text,
+				KubernetesDoc: apiDoc.GetApiDocV2("spec.hard"),
+				Code:          code,
+				Utilization:   utilization,
+				Sensitive:     sensitive,
+			})
+		}
+
+		// scoped quotas apply to some pods only, their workloads are not checked
+		scoped := len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil
+		for _, workload := range templates {
+			if scoped || workload.meta.Namespace != quota.Namespace {
+				continue
+			}
+			name := fmt.Sprintf("%s/%s", workload.meta.Namespace, workload.meta.Name)
+			reference := &common.ObjectReference{Kind: workload.kind, Namespace: workload.meta.Namespace, Name: workload.meta.Name}
+			workloadSensitive := append(sensitive, common.Sensitive{
+				Unmasked: workload.meta.Name,
+				Masked:   util.MaskString(workload.meta.Name),
+			})
+			podDefaults := defaults[workload.meta.Namespace]
+
+			for _, resourceName := range sortedResourceNames(quota.Status.Hard) {
+				if containers := containersMissing(workload.spec, resourceName, podDefaults); len(containers) > 0 {
+					failures = append(failures, common.Failure{
+						Text:      fmt.Sprintf("%s %s sets no %s for containers %s, ResourceQuota %s requires it and rejects its pods", workload.kind, name, resourceName, strings.Join(containers, ", "), quota.Name),
+						Code:      common.CodeResourceQuotaMissingRequests,
+						Reference: reference,
+						Sensitive: workloadSensitive,
+					})
+				}
+			}
+
+			if workload.pending == 0 {
+				continue
+			}
+			needs := podQuotaUsage(workload.spec, podDefaults)
+			var exceeded []string
+			for _, resourceName := range sortedResourceNames(quota.Status.Hard) {
+				need, ok := needs[resourceName]
+				if !ok {
+					continue
+				}
+				needed := resource.NewMilliQuantity(need.MilliValue()*int64(workload.pending), need.Format)
+				remaining := quota.Status.Hard[resourceName].DeepCopy()
+				remaining.Sub(quota.Status.Used[resourceName])
+				if needed.Cmp(remaining) > 0 {
+					exceeded = append(exceeded, fmt.Sprintf("%s %s with %s left", resourceName, needed.String(), remaining.String()))
+				}
+			}
+			if len(exceeded) > 0 {
+				failures = append(failures, common.Failure{
+					Text:        fmt.Sprintf("The %d missing pods of %s %s do not fit in ResourceQuota %s, they need %s", workload.pending, workload.kind, name, quota.Name, strings.Join(exceeded, ", ")),
+					Code:        common.CodeResourceQuotaWorkloadDoesNotFit,
+					Reference:   reference,
+					Utilization: utilization,
+					Sensitive:   workloadSensitive,
+				})
+			}
+		}
+
+		if len(failures) > 0 {
+			preAnalysis[fmt.Sprintf("%s/%s", quota.Namespace, quota.Name)] = common.PreAnalysis{
+				FailureDetails: failures,
+			}
+			AnalyzerErrorsMetric.WithLabelValues(kind, quota.Name, quota.Namespace).Set(float64(len(failures)))
+		}
+	}
+
+	for key, value := range preAnalysis {
+		a.Results = append(a.Results, common.Result{
+			Kind:  kind,
+			Name:  key,
+			Error: value.FailureDetails,
+		})
+	}
+
+	return a.Results, nil
+}
+
+// quotaUtilization returns the used share in percent of each hard limit of a
+// quota, rounded to one decimal.
+func quotaUtilization(quota v1.ResourceQuota) map[string]float64 {
+	utilization := map[string]float64{}
+	for name, hard := range quota.Status.Hard {
+		used, ok := quota.Status.Used[name]
+		if !ok {
+			continue
+		}
+		var percent float64
+		switch {
+		case hard.IsZero() && used.IsZero():
+			continue
+		case hard.IsZero():
+			percent = 100
+		default:
+			percent = used.AsApproximateFloat64() / hard.AsApproximateFloat64() * 100
+		}
+		utilization[string(name)] = math.Round(percent*10) / 10
+	}
+	return utilization
+}
+
+func sortedResourceNames(list v1.ResourceList) []v1.ResourceName {
+	names := make([]v1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// quotaResource returns the compute resource a quota resource name limits and
+// whether it limits the requests or the limits of containers.
+func quotaResource(name v1.ResourceName) (v1.ResourceName, bool, bool) {
+	switch {
+	case strings.HasPrefix(string(name), "requests."):
+		return v1.ResourceName(strings.TrimPrefix(string(name), "requests.")), false, true
+	case strings.HasPrefix(string(name), "limits."):
+		return v1.ResourceName(strings.TrimPrefix(string(name), "limits.")), true, true
+	case name == v1.ResourceCPU, name == v1.ResourceMemory, name == v1.ResourceEphemeralStorage:
+		return name, false, true
+	}
+	return "", false, false
+}
+
+// limitRangeDefaults are the default requests and limits LimitRanges set on
+// the containers of a namespace.
+type limitRangeDefaults struct {
+	requests v1.ResourceList
+	limits   v1.ResourceList
+}
+
+// listLimitRangeDefaults returns the container defaults of the LimitRanges of
+// the analyzed namespaces, by namespace.
+func listLimitRangeDefaults(a common.Analyzer) (map[string]limitRangeDefaults, error) {
+	list, err := a.Client.GetClient().CoreV1().LimitRanges(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defaults := map[string]limitRangeDefaults{}
+	for _, limitRange := range list.Items {
+		namespaceDefaults := defaults[limitRange.Namespace]
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != v1.LimitTypeContainer {
+				continue
+			}
+			namespaceDefaults = namespaceDefaults.merge(item)
+		}
+		defaults[limitRange.Namespace] = namespaceDefaults
+	}
+	return defaults, nil
+}
+
+func (d limitRangeDefaults) merge(item v1.LimitRangeItem) limitRangeDefaults {
+	merged := limitRangeDefaults{requests: v1.ResourceList{}, limits: v1.ResourceList{}}
+	for name, quantity := range d.requests {
+		merged.requests[name] = quantity
+	}
+	for name, quantity := range d.limits {
+		merged.limits[name] = quantity
+	}
+	for name, quantity := range item.Default {
+		merged.limits[name] = quantity
+		// the default request defaults to the default limit
+		if _, ok := item.DefaultRequest[name]; !ok {
+			merged.requests[name] = quantity
+		}
+	}
+	for name, quantity := range item.DefaultRequest {
+		merged.requests[name] = quantity
+	}
+	return merged
+}
+
+// containerResources returns the requests and limits a container gets once
+// admitted: a request defaults to the limit and then to the default of the
+// LimitRanges, a limit to the default of the LimitRanges.
+func containerResources(container v1.Container, defaults limitRangeDefaults) (v1.ResourceList, v1.ResourceList) {
+	requests, limits := v1.ResourceList{}, v1.ResourceList{}
+	for name, quantity := range defaults.limits {
+		limits[name] = quantity
+	}
+	for name, quantity := range container.Resources.Limits {
+		limits[name] = quantity
+	}
+	for name, quantity := range defaults.requests {
+		requests[name] = quantity
+	}
+	for name, quantity := range container.Resources.Limits {
+		requests[name] = quantity
+	}
+	for name, quantity := range container.Resources.Requests {
+		requests[name] = quantity
+	}
+	return requests, limits
+}
+
+// containersMissing returns the containers of a pod that do not set the
+// requests or limits of a resource a quota limits.
+func containersMissing(spec v1.PodSpec, quotaName v1.ResourceName, defaults limitRangeDefaults) []string {
+	name, limit, ok := quotaResource(quotaName)
+	if !ok || (name != v1.ResourceCPU && name != v1.ResourceMemory) {
+		return nil
+	}
+	var missing []string
+	for _, container := range append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...) {
+		requests, limits := containerResources(container, defaults)
+		list := requests
+		if limit {
+			list = limits
+		}
+		if _, ok := list[name]; !ok {
+			missing = append(missing, container.Name)
+		}
+	}
+	return missing
+}
+
+// podQuotaUsage returns how much of each quota resource a pod uses: the sum
+// over its containers, or the largest init container if that is more.
+func podQuotaUsage(spec v1.PodSpec, defaults limitRangeDefaults) map[v1.ResourceName]resource.Quantity {
+	usage := map[v1.ResourceName]resource.Quantity{v1.ResourcePods: *resource.NewQuantity(1, resource.DecimalSI)}
+	add := func(quotaName v1.ResourceName, quantity resource.Quantity) {
+		sum := usage[quotaName]
+		sum.Add(quantity)
+		usage[quotaName] = sum
+	}
+	for _, container := range spec.Containers {
+		requests, limits := containerResources(container, defaults)
+		for name, quantity := range requests {
+			add(v1.ResourceName("requests."+name), quantity)
+			add(name, quantity)
+		}
+		for name, quantity := range limits {
+			add(v1.ResourceName("limits."+name), quantity)
+		}
+	}
+	for _, container := range spec.InitContainers {
+		requests, limits := containerResources(container, defaults)
+		for prefix, list := range map[string]v1.ResourceList{"requests.": requests, "limits.": limits} {
+			for name, quantity := range list {
+				quotaNames := []v1.ResourceName{v1.ResourceName(prefix + string(name))}
+				if prefix == "requests." {
+					quotaNames = append(quotaNames, name)
+				}
+				for _, quotaName := range quotaNames {
+					if current, ok := usage[quotaName]; !ok || quantity.Cmp(current) > 0 {
+						usage[quotaName] = quantity
+					}
+				}
+			}
+		}
+	}
+	return usage
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func quotaDeployment(name string, replicas int32, current int32, resources v1.ResourceRequirements) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "app", Resources: resources}},
+			}},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: current},
+	}
+}
+
+func TestResourceQuotaAnalyzer(t *testing.T) {
+	quota := &v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "default"},
+		Status: v1.ResourceQuotaStatus{
+			Hard: v1.ResourceList{
+				v1.ResourceRequestsCPU:    resource.MustParse("2"),
+				v1.ResourceRequestsMemory: resource.MustParse("4Gi"),
+				v1.ResourcePods:           resource.MustParse("10"),
+			},
+			Used: v1.ResourceList{
+				v1.ResourceRequestsCPU:    resource.MustParse("1500m"),
+				v1.ResourceRequestsMemory: resource.MustParse("4Gi"),
+				v1.ResourcePods:           resource.MustParse("3"),
+			},
+		},
+	}
+	requests := v1.ResourceRequirements{Requests: v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("250m"),
+		v1.ResourceMemory: resource.MustParse("128Mi"),
+	}}
+
+	parallelism := int32(4)
+
+	tests := []struct {
+		name      string
+		objects   []runtime.Object
+		wantCodes []string
+	}{
+		{
+			name:      "quota used up",
+			objects:   []runtime.Object{quotaDeployment("web", 3, 3, requests)},
+			wantCodes: []string{common.CodeResourceQuotaAtLimit},
+		},
+		{
+			name:      "missing replicas do not fit",
+			objects:   []runtime.Object{quotaDeployment("web", 6, 3, requests)},
+			wantCodes: []string{common.CodeResourceQuotaAtLimit, common.CodeResourceQuotaWorkloadDoesNotFit},
+		},
+		{
+			name: "missing DaemonSet pods do not fit",
+			objects: []runtime.Object{&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+				Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "agent", Resources: requests}},
+				}}},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 6, CurrentNumberScheduled: 3},
+			}},
+			wantCodes: []string{common.CodeResourceQuotaAtLimit, common.CodeResourceQuotaWorkloadDoesNotFit},
+		},
+		{
+			name: "missing CronJob pods do not fit",
+			objects: []runtime.Object{
+				&batchv1.CronJob{
+					ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+					Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+						Containers: []v1.Container{{Name: "report", Resources: requests}},
+					}}}}},
+				},
+				&batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "report-1", Namespace: "default",
+						OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "report"}},
+					},
+					Spec:   batchv1.JobSpec{Parallelism: &parallelism},
+					Status: batchv1.JobStatus{Active: 1},
+				},
+			},
+			wantCodes: []string{common.CodeResourceQuotaAtLimit, common.CodeResourceQuotaWorkloadDoesNotFit},
+		},
+		{
+			name: "finished CronJob runs",
+			objects: []runtime.Object{
+				&batchv1.CronJob{
+					ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+					Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+						Containers: []v1.Container{{Name: "report", Resources: requests}},
+					}}}}},
+				},
+				&batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "report-1", Namespace: "default",
+						OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "report"}},
+					},
+					Spec: batchv1.JobSpec{Parallelism: &parallelism},
+					Status: batchv1.JobStatus{
+						Succeeded:  4,
+						Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}},
+					},
+				},
+			},
+			wantCodes: []string{common.CodeResourceQuotaAtLimit},
+		},
+		{
+			name:      "missing requests",
+			objects:   []runtime.Object{quotaDeployment("web", 1, 1, v1.ResourceRequirements{})},
+			wantCodes: []string{common.CodeResourceQuotaAtLimit, common.CodeResourceQuotaMissingRequests, common.CodeResourceQuotaMissingRequests},
+		},
+		{
+			name: "requests set by a LimitRange",
+			objects: []runtime.Object{
+				quotaDeployment("web", 1, 1, v1.ResourceRequirements{}),
+				&v1.LimitRange{
+					ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "default"},
+					Spec: v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{{
+						Type:    v1.LimitTypeContainer,
+						Default: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+					}}},
+				},
+			},
+			wantCodes: []string{common.CodeResourceQuotaAtLimit},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(append([]runtime.Object{quota}, tt.objects...)...)
+
+			results, err := ResourceQuotaAnalyzer{}.Analyze(common.Analyzer{
+				Client:    &kubernetes.Client{Client: clientset},
+				Context:   context.Background(),
+				Namespace: "default",
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.Equal(t, "default/compute", results[0].Name)
+			var codes []string
+			for _, failure := range results[0].Error {
+				codes = append(codes, failure.Code)
+			}
+			require.Equal(t, tt.wantCodes, codes)
+			require.Equal(t, map[string]float64{"requests.cpu": 75, "requests.memory": 100, "pods": 30}, results[0].Error[0].Utilization)
+		})
+	}
+}
+
+func TestResourceQuotaAnalyzerNearLimit(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "objects", Namespace: "default"},
+		Spec:       v1.ResourceQuotaSpec{Scopes: []v1.ResourceQuotaScope{v1.ResourceQuotaScopeBestEffort}},
+		Status: v1.ResourceQuotaStatus{
+			Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("20"), v1.ResourceServices: resource.MustParse("0")},
+			Used: v1.ResourceList{v1.ResourcePods: resource.MustParse("19"), v1.ResourceServices: resource.MustParse("0")},
+		},
+	}, quotaDeployment("web", 2, 0, v1.ResourceRequirements{}))
+
+	results, err := ResourceQuotaAnalyzer{}.Analyze(common.Analyzer{
+		Client:  &kubernetes.Client{Client: clientset},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Error, 1)
+	require.Equal(t, common.CodeResourceQuotaNearLimit, results[0].Error[0].Code)
+	require.Equal(t, "ResourceQuota objects uses 95% of pods (19 of 20)", results[0].Error[0].Text)
+}
+
+func TestPodQuotaUsage(t *testing.T) {
+	spec := v1.PodSpec{
+		InitContainers: []v1.Container{{Name: "migrate", Resources: v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		}}},
+		Containers: []v1.Container{
+			{Name: "app", Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}}},
+			{Name: "sidecar", Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}}},
+		},
+	}
+	usage := podQuotaUsage(spec, limitRangeDefaults{})
+	for name, want := range map[v1.ResourceName]string{
+		v1.ResourcePods:        "1",
+		v1.ResourceRequestsCPU: "2",
+		v1.ResourceCPU:         "2",
+		v1.ResourceLimitsCPU:   "2",
+	} {
+		quantity := usage[name]
+		require.Equal(t, 0, quantity.Cmp(resource.MustParse(want)), "%s is %s", name, quantity.String())
+	}
+}
@@ -51,6 +51,8 @@ func (ReplicaSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					failures = append(failures, common.Failure{
 						Text:      rsStatus.Message,
 						Sensitive: []common.Sensitive{},
+						Code:      common.CodeReplicaSetCreateFailed,
+						Reason:    rsStatus.Reason,
 					})
 
 				}
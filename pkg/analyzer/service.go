@@ -17,6 +17,7 @@ import (
 	"fmt"
 
 	"github.com/fatih/color"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 
@@ -66,7 +67,9 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		for _, event := range events.Items {
 			if event.Type != "Normal" {
 				failures = append(failures, common.Failure{
-					Text: fmt.Sprintf("Service %s/%s has event %s", ep.Namespace, ep.Name, event.Message),
+					Text:   fmt.Sprintf("Service %s/%s has event %s", ep.Namespace, ep.Name, event.Message),
+					Code:   common.CodeServiceWarningEvent,
+					Reason: event.Reason,
 				})
 			}
 		}
@@ -89,6 +92,7 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("Service has no endpoints, expected label %s=%s", k, v),
 					KubernetesDoc: doc,
+					Code:          common.CodeServiceNoEndpoints,
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: k,
@@ -123,6 +127,7 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("Service has not ready endpoints, pods: %s, expected %d", pods, count),
 					KubernetesDoc: doc,
+					Code:          common.CodeServiceEndpointsNotReady,
 					Sensitive:     []common.Sensitive{},
 				})
 			}
@@ -152,3 +157,43 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	}
 	return a.Results, nil
 }
+
+// serviceBackend describes the pods behind a Service the API server calls,
+// e.g. for a webhook, an aggregated API or a CRD conversion webhook.
+type serviceBackend struct {
+	// Found is false if the Service cannot be read.
+	Found bool
+	// Selected is false if the Service has no selector, its endpoints are
+	// then left to the Service analyzer.
+	Selected bool
+	Pods     []corev1.Pod
+}
+
+// inactivePods returns the pods of the backend that are not running.
+func (b serviceBackend) inactivePods() []corev1.Pod {
+	var inactive []corev1.Pod
+	for _, pod := range b.Pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			inactive = append(inactive, pod)
+		}
+	}
+	return inactive
+}
+
+// getServiceBackend reads a Service and the pods it selects.
+func getServiceBackend(a common.Analyzer, namespace string, name string) (serviceBackend, error) {
+	service, err := a.Client.GetClient().CoreV1().Services(namespace).Get(a.Context, name, metav1.GetOptions{})
+	if err != nil {
+		return serviceBackend{}, nil
+	}
+	if len(service.Spec.Selector) == 0 {
+		return serviceBackend{Found: true}, nil
+	}
+	pods, err := a.Client.GetClient().CoreV1().Pods(namespace).List(a.Context, metav1.ListOptions{
+		LabelSelector: util.MapToString(service.Spec.Selector),
+	})
+	if err != nil {
+		return serviceBackend{}, err
+	}
+	return serviceBackend{Found: true, Selected: true, Pods: pods.Items}, nil
+}
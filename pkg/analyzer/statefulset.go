@@ -63,6 +63,8 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					serviceName,
 				),
 				KubernetesDoc: doc,
+				Code:          common.CodeStatefulSetServiceNotFound,
+				Reference:     &common.ObjectReference{Kind: "Service", Namespace: sts.Namespace, Name: serviceName},
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: sts.Namespace,
@@ -81,7 +83,9 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					_, err := a.Client.GetClient().StorageV1().StorageClasses().Get(a.Context, *volumeClaimTemplate.Spec.StorageClassName, metav1.GetOptions{})
 					if err != nil {
 						failures = append(failures, common.Failure{
-							Text: fmt.Sprintf("StatefulSet uses the storage class %s which does not exist.", *volumeClaimTemplate.Spec.StorageClassName),
+							Text:      fmt.Sprintf("StatefulSet uses the storage class %s which does not exist.", *volumeClaimTemplate.Spec.StorageClassName),
+							Code:      common.CodeStatefulSetStorageClassNotFound,
+							Reference: &common.ObjectReference{Kind: "StorageClass", Name: *volumeClaimTemplate.Spec.StorageClassName},
 							Sensitive: []common.Sensitive{
 								{
 									Unmasked: *volumeClaimTemplate.Spec.StorageClassName,
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// provisioningStuckAfter is how long a claim may wait for its volume before
+// its provisioner is suspected.
+const provisioningStuckAfter = 5 * time.Minute
+
+// selectedNodeAnnotation is set on claims of WaitForFirstConsumer classes once
+// a pod using them is scheduled, which is when they are provisioned.
+const selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+var defaultStorageClassAnnotations = []string{
+	"storageclass.kubernetes.io/is-default-class",
+	"storageclass.beta.kubernetes.io/is-default-class",
+}
+
+// StorageClassAnalyzer is an analyzer that checks for a missing or ambiguous
+// default StorageClass and for provisioners that are not installed, as far as
+// claims are waiting for them
+type StorageClassAnalyzer struct{}
+
+// Analyze scans the StorageClasses of the cluster
+func (StorageClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "StorageClass"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().StorageV1().StorageClasses().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	csiDrivers, err := a.Client.GetClient().StorageV1().CSIDrivers().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	drivers := map[string]bool{}
+	for _, driver := range csiDrivers.Items {
+		drivers[driver.Name] = true
+	}
+
+	var defaults []string
+	for _, sc := range list.Items {
+		for _, annotation := range defaultStorageClassAnnotations {
+			if sc.Annotations[annotation] == "true" {
+				defaults = append(defaults, sc.Name)
+				break
+			}
+		}
+	}
+
+	if len(defaults) == 0 {
+		a.Results = append(a.Results, common.Result{
+			Kind: kind,
+			Name: "(default)",
+			Error: []common.Failure{
+				{
+					Text:      "No StorageClass is marked as default, PersistentVolumeClaims without a storageClassName are not provisioned",
+					Code:      common.CodeStorageClassNoDefault,
+					Sensitive: []common.Sensitive{},
+				},
+			},
+		})
+		AnalyzerErrorsMetric.WithLabelValues(kind, "", "").Set(1)
+	}
+
+	// claims only need to be looked at for provisioners that may be missing,
+	// external provisioners that are not CSI drivers have no CSIDriver either
+	var stuck map[string]int
+	for _, sc := range list.Items {
+		// in-tree provisioners, and kubernetes.io/no-provisioner, have no CSIDriver
+		if !strings.HasPrefix(sc.Provisioner, "kubernetes.io/") && !drivers[sc.Provisioner] {
+			stuck, err = stuckClaims(a, list.Items, defaults, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	for _, sc := range list.Items {
+		var failures []common.Failure
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: sc.Name,
+				Masked:   util.MaskString(sc.Name),
+			},
+		}
+		if len(defaults) > 1 && slices.Contains(defaults, sc.Name) {
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("StorageClass %s is one of %d default StorageClasses (%s), claims without a storageClassName get the newest of them", sc.Name, len(defaults), strings.Join(defaults, ", ")),
+				Code:      common.CodeStorageClassMultipleDefaults,
+				Sensitive: sensitive,
+			})
+		}
+		if !strings.HasPrefix(sc.Provisioner, "kubernetes.io/") && !drivers[sc.Provisioner] && stuck[sc.Name] > 0 {
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("StorageClass %s uses the provisioner %s which has no CSIDriver, and %d PersistentVolumeClaims of the class are not provisioned, check that it is installed", sc.Name, sc.Provisioner, stuck[sc.Name]),
+				Code:      common.CodeStorageClassProvisionerNotFound,
+				Reference: &common.ObjectReference{Kind: "CSIDriver", Name: sc.Provisioner},
+				Sensitive: sensitive,
+			})
+		}
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  kind,
+				Name:  sc.Name,
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, sc.Name, "").Set(float64(len(failures)))
+		}
+	}
+
+	return a.Results, nil
+}
+
+// stuckClaims counts by StorageClass the claims that have been waiting for
+// their volume to be provisioned for a while.
+func stuckClaims(a common.Analyzer, classes []storagev1.StorageClass, defaults []string, now time.Time) (map[string]int, error) {
+	claims, err := a.Client.GetClient().CoreV1().PersistentVolumeClaims(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	waitForConsumer := map[string]bool{}
+	for _, sc := range classes {
+		waitForConsumer[sc.Name] = sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+	}
+
+	stuck := map[string]int{}
+	for _, pvc := range claims.Items {
+		if pvc.Status.Phase != v1.ClaimPending || now.Sub(pvc.CreationTimestamp.Time) < provisioningStuckAfter {
+			continue
+		}
+		class := ""
+		if pvc.Spec.StorageClassName != nil {
+			class = *pvc.Spec.StorageClassName
+		} else if len(defaults) == 1 {
+			class = defaults[0]
+		}
+		// claims of WaitForFirstConsumer classes are pending until a pod uses them
+		if waitForConsumer[class] && pvc.Annotations[selectedNodeAnnotation] == "" {
+			continue
+		}
+		stuck[class]++
+	}
+	return stuck, nil
+}
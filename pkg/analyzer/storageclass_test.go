@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStorageClassAnalyzer(t *testing.T) {
+	storageClass := func(name, provisioner string, isDefault bool) runtime.Object {
+		sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}, Provisioner: provisioner}
+		if isDefault {
+			sc.Annotations = map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}
+		}
+		return sc
+	}
+	driver := &storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: "ebs.csi.aws.com"}}
+	waitForConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	claim := func(name, class string, age time.Duration, selectedNode string) runtime.Object {
+		pvc := &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+			Spec:       v1.PersistentVolumeClaimSpec{StorageClassName: &class},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimPending},
+		}
+		if selectedNode != "" {
+			pvc.Annotations = map[string]string{selectedNodeAnnotation: selectedNode}
+		}
+		return pvc
+	}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    map[string][]string
+	}{
+		{
+			name: "no storage classes",
+		},
+		{
+			name: "healthy",
+			objects: []runtime.Object{
+				driver,
+				storageClass("gp3", "ebs.csi.aws.com", true),
+				storageClass("local", "kubernetes.io/no-provisioner", false),
+			},
+		},
+		{
+			name: "no default",
+			objects: []runtime.Object{
+				driver,
+				storageClass("gp3", "ebs.csi.aws.com", false),
+			},
+			want: map[string][]string{"(default)": {common.CodeStorageClassNoDefault}},
+		},
+		{
+			name: "external provisioner without claims waiting",
+			objects: []runtime.Object{
+				storageClass("local-path", "rancher.io/local-path", true),
+				claim("data", "local-path", time.Minute, ""),
+			},
+		},
+		{
+			name: "claims waiting for their first consumer",
+			objects: []runtime.Object{
+				&storagev1.StorageClass{
+					ObjectMeta:        metav1.ObjectMeta{Name: "local-path", Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}},
+					Provisioner:       "rancher.io/local-path",
+					VolumeBindingMode: &waitForConsumer,
+				},
+				claim("data", "local-path", time.Hour, ""),
+			},
+		},
+		{
+			name: "several defaults and a missing driver",
+			objects: []runtime.Object{
+				driver,
+				storageClass("gp3", "ebs.csi.aws.com", true),
+				storageClass("fast", "pd.csi.storage.gke.io", true),
+				claim("data", "fast", time.Hour, "worker-1"),
+				claim("cache", "gp3", time.Hour, ""),
+			},
+			want: map[string][]string{
+				"gp3":  {common.CodeStorageClassMultipleDefaults},
+				"fast": {common.CodeStorageClassMultipleDefaults, common.CodeStorageClassProvisionerNotFound},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := StorageClassAnalyzer{}.Analyze(common.Analyzer{
+				Client:  &kubernetes.Client{Client: fake.NewSimpleClientset(tt.objects...)},
+				Context: context.Background(),
+			})
+			require.NoError(t, err)
+			got := map[string][]string{}
+			for _, result := range results {
+				for _, failure := range result.Error {
+					got[result.Name] = append(got[result.Name], failure.Code)
+				}
+			}
+			if tt.want == nil {
+				tt.want = map[string][]string{}
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// volumeAttachStuckAfter is how long a volume may take to attach before it is
+// reported as stuck.
+const volumeAttachStuckAfter = 5 * time.Minute
+
+// VolumeAttachmentAnalyzer is an analyzer that checks for volumes that fail
+// or take too long to attach to or detach from their node
+type VolumeAttachmentAnalyzer struct{}
+
+// Analyze scans the VolumeAttachments of the cluster
+func (VolumeAttachmentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+
+	kind := "VolumeAttachment"
+
+	AnalyzerErrorsMetric.DeletePartialMatch(map[string]string{
+		"analyzer_name": kind,
+	})
+
+	list, err := a.Client.GetClient().StorageV1().VolumeAttachments().List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, va := range list.Items {
+		var failures []common.Failure
+		volume := "an inline volume"
+		var reference *common.ObjectReference
+		if pv := va.Spec.Source.PersistentVolumeName; pv != nil {
+			volume = "PersistentVolume " + *pv
+			reference = &common.ObjectReference{Kind: "PersistentVolume", Name: *pv}
+		}
+		sensitive := []common.Sensitive{
+			{
+				Unmasked: va.Name,
+				Masked:   util.MaskString(va.Name),
+			},
+			{
+				Unmasked: va.Spec.NodeName,
+				Masked:   util.MaskString(va.Spec.NodeName),
+			},
+		}
+
+		switch {
+		case va.Status.AttachError != nil:
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("Attaching %s to node %s failed: %s", volume, va.Spec.NodeName, va.Status.AttachError.Message),
+				Code:      common.CodeVolumeAttachFailed,
+				Reference: reference,
+				Sensitive: sensitive,
+			})
+		case !va.Status.Attached && va.DeletionTimestamp == nil && !va.CreationTimestamp.IsZero() && now.Sub(va.CreationTimestamp.Time) > volumeAttachStuckAfter:
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("%s has not been attached to node %s by %s since %s", volume, va.Spec.NodeName, va.Spec.Attacher, va.CreationTimestamp.UTC().Format(time.RFC3339)),
+				Code:      common.CodeVolumeAttachStuck,
+				Reference: reference,
+				Sensitive: sensitive,
+			})
+		}
+		if va.Status.DetachError != nil {
+			failures = append(failures, common.Failure{
+				Text:      fmt.Sprintf("Detaching %s from node %s failed: %s", volume, va.Spec.NodeName, va.Status.DetachError.Message),
+				Code:      common.CodeVolumeDetachFailed,
+				Reference: reference,
+				Sensitive: sensitive,
+			})
+		}
+
+		if len(failures) > 0 {
+			a.Results = append(a.Results, common.Result{
+				Kind:  kind,
+				Name:  va.Name,
+				Error: failures,
+			})
+			AnalyzerErrorsMetric.WithLabelValues(kind, va.Name, "").Set(float64(len(failures)))
+		}
+	}
+
+	return a.Results, nil
+}
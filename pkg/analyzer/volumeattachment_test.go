@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestVolumeAttachmentAnalyzer(t *testing.T) {
+	pv := "pv-data"
+	tests := []struct {
+		name      string
+		created   time.Duration
+		status    storagev1.VolumeAttachmentStatus
+		wantCodes []string
+	}{
+		{
+			name:    "attached",
+			created: time.Hour,
+			status:  storagev1.VolumeAttachmentStatus{Attached: true},
+		},
+		{
+			name:    "attaching",
+			created: time.Minute,
+		},
+		{
+			name:      "stuck attaching",
+			created:   time.Hour,
+			wantCodes: []string{common.CodeVolumeAttachStuck},
+		},
+		{
+			name:      "attach error",
+			created:   time.Minute,
+			status:    storagev1.VolumeAttachmentStatus{AttachError: &storagev1.VolumeError{Message: "volume is attached to another node"}},
+			wantCodes: []string{common.CodeVolumeAttachFailed},
+		},
+		{
+			name:      "detach error",
+			created:   time.Hour,
+			status:    storagev1.VolumeAttachmentStatus{Attached: true, DetachError: &storagev1.VolumeError{Message: "timed out"}},
+			wantCodes: []string{common.CodeVolumeDetachFailed},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(&storagev1.VolumeAttachment{
+				ObjectMeta: metav1.ObjectMeta{Name: "csi-123", CreationTimestamp: metav1.NewTime(time.Now().Add(-tt.created))},
+				Spec: storagev1.VolumeAttachmentSpec{
+					Attacher: "ebs.csi.aws.com",
+					NodeName: "worker-1",
+					Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pv},
+				},
+				Status: tt.status,
+			})
+
+			results, err := VolumeAttachmentAnalyzer{}.Analyze(common.Analyzer{
+				Client:  &kubernetes.Client{Client: clientset},
+				Context: context.Background(),
+			})
+			require.NoError(t, err)
+			if len(tt.wantCodes) == 0 {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			var codes []string
+			for _, failure := range results[0].Error {
+				codes = append(codes, failure.Code)
+				require.Equal(t, pv, failure.Reference.Name)
+			}
+			require.Equal(t, tt.wantCodes, codes)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// workloadTemplate is the pod template of a workload.
+type workloadTemplate struct {
+	kind string
+	meta metav1.ObjectMeta
+	spec v1.PodSpec
+	// pending is the number of pods the workload wants but has not created.
+	pending int32
+}
+
+// listWorkloadTemplates returns the pod templates of the Deployments,
+// StatefulSets, DaemonSets and CronJobs of the analyzed namespaces.
+func listWorkloadTemplates(a common.Analyzer) ([]workloadTemplate, error) {
+	var templates []workloadTemplate
+	deployments, err := a.Client.GetClient().AppsV1().Deployments(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		templates = append(templates, workloadTemplate{"Deployment", deployment.ObjectMeta, deployment.Spec.Template.Spec,
+			pendingReplicas(deployment.Spec.Replicas, deployment.Status.Replicas)})
+	}
+	statefulSets, err := a.Client.GetClient().AppsV1().StatefulSets(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, sts := range statefulSets.Items {
+		templates = append(templates, workloadTemplate{"StatefulSet", sts.ObjectMeta, sts.Spec.Template.Spec,
+			pendingReplicas(sts.Spec.Replicas, sts.Status.Replicas)})
+	}
+	daemonSets, err := a.Client.GetClient().AppsV1().DaemonSets(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range daemonSets.Items {
+		templates = append(templates, workloadTemplate{"DaemonSet", ds.ObjectMeta, ds.Spec.Template.Spec,
+			pendingReplicas(&ds.Status.DesiredNumberScheduled, ds.Status.CurrentNumberScheduled)})
+	}
+	cronJobs, err := a.Client.GetClient().BatchV1().CronJobs(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(cronJobs.Items) == 0 {
+		return templates, nil
+	}
+	jobs, err := a.Client.GetClient().BatchV1().Jobs(a.Namespace).List(a.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// the pods the running Jobs of each CronJob have not created, by
+	// namespace and name of the CronJob
+	pending := map[string]int32{}
+	for _, job := range jobs.Items {
+		for _, owner := range job.OwnerReferences {
+			if owner.Kind == "CronJob" {
+				pending[fmt.Sprintf("%s/%s", job.Namespace, owner.Name)] += jobPendingPods(job)
+			}
+		}
+	}
+	for _, cronJob := range cronJobs.Items {
+		templates = append(templates, workloadTemplate{"CronJob", cronJob.ObjectMeta, cronJob.Spec.JobTemplate.Spec.Template.Spec,
+			pending[fmt.Sprintf("%s/%s", cronJob.Namespace, cronJob.Name)]})
+	}
+	return templates, nil
+}
+
+// pendingReplicas returns the number of desired replicas that have no pod.
+func pendingReplicas(desired *int32, current int32) int32 {
+	replicas := int32(1)
+	if desired != nil {
+		replicas = *desired
+	}
+	if replicas <= current {
+		return 0
+	}
+	return replicas - current
+}
+
+// jobPendingPods returns the number of pods a running Job wants to run in
+// parallel but has not created.
+func jobPendingPods(job batchv1.Job) int32 {
+	if jobFinished(job) || (job.Spec.Suspend != nil && *job.Spec.Suspend) {
+		return 0
+	}
+	parallelism := int32(1)
+	if job.Spec.Parallelism != nil {
+		parallelism = *job.Spec.Parallelism
+	}
+	if job.Spec.Completions != nil && *job.Spec.Completions-job.Status.Succeeded < parallelism {
+		parallelism = *job.Spec.Completions - job.Status.Succeeded
+	}
+	return pendingReplicas(&parallelism, job.Status.Active)
+}
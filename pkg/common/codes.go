@@ -0,0 +1,325 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import "sort"
+
+// Failure codes identify a kind of failure independently of its text. They
+// are stable and safe to match on.
+const (
+	CodeAPIServiceUnavailable     = "APISERVICE_UNAVAILABLE"
+	CodeAPIServiceServiceNotFound = "APISERVICE_SERVICE_NOT_FOUND"
+	CodeAPIServiceNoActivePods    = "APISERVICE_NO_ACTIVE_PODS"
+	CodeAPIServiceInactivePod     = "APISERVICE_INACTIVE_POD"
+
+	CodeCRDNamesNotAccepted          = "CRD_NAMES_NOT_ACCEPTED"
+	CodeCRDNotEstablished            = "CRD_NOT_ESTABLISHED"
+	CodeCRDConversionServiceNotFound = "CRD_CONVERSION_SERVICE_NOT_FOUND"
+	CodeCRDConversionNoActivePods    = "CRD_CONVERSION_NO_ACTIVE_PODS"
+	CodeCRDConversionInactivePod     = "CRD_CONVERSION_INACTIVE_POD"
+
+	CodeCronJobSuspended           = "CRONJOB_SUSPENDED"
+	CodeCronJobInvalidSchedule     = "CRONJOB_INVALID_SCHEDULE"
+	CodeCronJobNegativeDeadline    = "CRONJOB_NEGATIVE_DEADLINE"
+	CodeCronJobConsecutiveFailures = "CRONJOB_CONSECUTIVE_FAILURES"
+	CodeCronJobMissedSchedules     = "CRONJOB_MISSED_SCHEDULES"
+
+	CodeCertificateInvalid         = "CERT_INVALID"
+	CodeCertificateExpired         = "CERT_EXPIRED"
+	CodeCertificateExpiring        = "CERT_EXPIRING"
+	CodeCertificateChainIncomplete = "CERT_CHAIN_INCOMPLETE"
+	CodeCertificateHostMismatch    = "CERT_HOST_MISMATCH"
+
+	CodeConfigMapNotFound     = "CONFIGMAP_NOT_FOUND"
+	CodeConfigMapKeyNotFound  = "CONFIGMAP_KEY_NOT_FOUND"
+	CodeSecretNotFound        = "SECRET_NOT_FOUND"
+	CodeSecretKeyNotFound     = "SECRET_KEY_NOT_FOUND"
+	CodeConfigReferenceUnused = "CONFIG_UNUSED"
+
+	CodeDaemonSetPodsNotReady     = "DS_PODS_NOT_READY"
+	CodeDaemonSetPodsUnavailable  = "DS_PODS_UNAVAILABLE"
+	CodeDaemonSetPodsMisscheduled = "DS_PODS_MISSCHEDULED"
+	CodeDaemonSetRolloutStalled   = "DS_ROLLOUT_STALLED"
+	CodeDaemonSetNoMatchingNodes  = "DS_NO_MATCHING_NODES"
+	CodeDaemonSetNodesTainted     = "DS_NODES_TAINTED"
+
+	CodeDeploymentReplicaMismatch = "DEPLOYMENT_REPLICA_MISMATCH"
+
+	CodeDeprecatedAPIDeprecated = "DEPRECATED_API_DEPRECATED"
+	CodeDeprecatedAPIRemoved    = "DEPRECATED_API_REMOVED"
+
+	CodeEventWarning = "EVENT_WARNING"
+
+	CodeGatewayClassNotFound    = "GATEWAY_CLASS_NOT_FOUND"
+	CodeGatewayNotAccepted      = "GATEWAY_NOT_ACCEPTED"
+	CodeGatewayClassNotAccepted = "GATEWAYCLASS_NOT_ACCEPTED"
+
+	CodeHPAInvalidScaleTargetKind = "HPA_INVALID_SCALE_TARGET_KIND"
+	CodeHPAScaleTargetNotFound    = "HPA_SCALE_TARGET_NOT_FOUND"
+	CodeHPAScaleTargetNoResources = "HPA_SCALE_TARGET_NO_RESOURCES"
+
+	CodeHTTPRouteGatewayNotFound     = "HTTPROUTE_GATEWAY_NOT_FOUND"
+	CodeHTTPRouteNamespaceNotAllowed = "HTTPROUTE_NAMESPACE_NOT_ALLOWED"
+	CodeHTTPRouteSelectorMismatch    = "HTTPROUTE_SELECTOR_MISMATCH"
+	CodeHTTPRouteServiceNotFound     = "HTTPROUTE_SERVICE_NOT_FOUND"
+	CodeHTTPRoutePortMismatch        = "HTTPROUTE_PORT_MISMATCH"
+
+	CodeIngressNoClass           = "INGRESS_NO_CLASS"
+	CodeIngressClassNotFound     = "INGRESS_CLASS_NOT_FOUND"
+	CodeIngressServiceNotFound   = "INGRESS_SERVICE_NOT_FOUND"
+	CodeIngressTLSSecretNotFound = "INGRESS_TLS_SECRET_NOT_FOUND"
+
+	CodeJobBackoffLimitExceeded = "JOB_BACKOFF_LIMIT_EXCEEDED"
+	CodeJobDeadlineExceeded     = "JOB_DEADLINE_EXCEEDED"
+	CodeJobFailed               = "JOB_FAILED"
+	CodeJobPodFailed            = "JOB_POD_FAILED"
+	CodeJobNoActivePods         = "JOB_NO_ACTIVE_PODS"
+
+	CodeLimitRangeInvalidDefault = "LIMITRANGE_INVALID_DEFAULT"
+	CodeLimitRangeConflict       = "LIMITRANGE_CONFLICT"
+
+	CodeLogFetchFailed = "LOG_FETCH_FAILED"
+	CodeLogErrors      = "LOG_ERRORS"
+
+	CodeNamespaceTerminating           = "NS_TERMINATING"
+	CodeNamespaceDeletionFailed        = "NS_DELETION_FAILED"
+	CodeNamespaceFinalizersRemaining   = "NS_FINALIZERS_REMAINING"
+	CodeNamespaceObjectsRemaining      = "NS_OBJECTS_REMAINING"
+	CodeNamespaceAPIServiceUnavailable = "NS_APISERVICE_UNAVAILABLE"
+	CodeObjectDeletionBlocked          = "OBJECT_DELETION_BLOCKED"
+
+	CodeNetworkPolicyAllowsAll = "NETPOL_ALLOWS_ALL"
+	CodeNetworkPolicyNoPods    = "NETPOL_NO_PODS"
+
+	CodeNodeNotReady  = "NODE_NOT_READY"
+	CodeNodeCondition = "NODE_CONDITION"
+
+	CodePDBNoMatchingPods = "PDB_NO_MATCHING_PODS"
+
+	CodePVReleased                  = "PV_RELEASED"
+	CodePVReclaimStuck              = "PV_RECLAIM_STUCK"
+	CodePVFailed                    = "PV_FAILED"
+	CodePVReclaimPolicy             = "PV_RECLAIM_POLICY"
+	CodePVNodeAffinityUnsatisfiable = "PV_NODE_AFFINITY_UNSATISFIABLE"
+	CodePVCNotMounted               = "PVC_NOT_MOUNTED"
+
+	CodeStorageClassNoDefault           = "STORAGECLASS_NO_DEFAULT"
+	CodeStorageClassMultipleDefaults    = "STORAGECLASS_MULTIPLE_DEFAULTS"
+	CodeStorageClassProvisionerNotFound = "STORAGECLASS_PROVISIONER_NOT_FOUND"
+
+	CodeVolumeAttachFailed = "VOLUME_ATTACH_FAILED"
+	CodeVolumeAttachStuck  = "VOLUME_ATTACH_STUCK"
+	CodeVolumeDetachFailed = "VOLUME_DETACH_FAILED"
+
+	CodePodUnschedulable         = "POD_UNSCHEDULABLE"
+	CodePodSandboxFailed         = "POD_SANDBOX_FAILED"
+	CodePodMountFailed           = "POD_MOUNT_FAILED"
+	CodePodCrashLoop             = "POD_CRASHLOOP"
+	CodePodImagePullFailed       = "POD_IMAGE_PULL_FAILED"
+	CodePodContainerConfigError  = "POD_CONTAINER_CONFIG_ERROR"
+	CodePodContainerStartFailed  = "POD_CONTAINER_START_FAILED"
+	CodePodReadinessProbeFailed  = "POD_READINESS_PROBE_FAILED"
+	CodePVCProvisioningFailed    = "PVC_PROVISIONING_FAILED"
+	CodeReplicaSetCreateFailed   = "RS_CREATE_FAILED"
+	CodeServiceWarningEvent      = "SVC_WARNING_EVENT"
+	CodeServiceNoEndpoints       = "SVC_NO_ENDPOINTS"
+	CodeServiceEndpointsNotReady = "SVC_ENDPOINTS_NOT_READY"
+
+	CodeResourceQuotaNearLimit          = "QUOTA_NEAR_LIMIT"
+	CodeResourceQuotaAtLimit            = "QUOTA_AT_LIMIT"
+	CodeResourceQuotaMissingRequests    = "QUOTA_MISSING_REQUESTS"
+	CodeResourceQuotaWorkloadDoesNotFit = "QUOTA_WORKLOAD_DOES_NOT_FIT"
+
+	CodeStatefulSetServiceNotFound      = "STS_SERVICE_NOT_FOUND"
+	CodeStatefulSetStorageClassNotFound = "STS_STORAGE_CLASS_NOT_FOUND"
+
+	CodeWebhookServiceNotFound = "WEBHOOK_SERVICE_NOT_FOUND"
+	CodeWebhookNoActivePods    = "WEBHOOK_NO_ACTIVE_PODS"
+	CodeWebhookInactivePod     = "WEBHOOK_INACTIVE_POD"
+
+	CodeEKSHealthIssue                = "EKS_HEALTH_ISSUE"
+	CodeScaledObjectInvalidTargetKind = "SCALEDOBJECT_INVALID_SCALE_TARGET_KIND"
+	CodeScaledObjectTargetNotFound    = "SCALEDOBJECT_SCALE_TARGET_NOT_FOUND"
+	CodeScaledObjectTargetNoResources = "SCALEDOBJECT_SCALE_TARGET_NO_RESOURCES"
+	CodeScaledObjectWarningEvent      = "SCALEDOBJECT_WARNING_EVENT"
+	CodePrometheusConfigInvalid       = "PROMETHEUS_CONFIG_INVALID"
+	CodePrometheusNoScrapeConfigs     = "PROMETHEUS_NO_SCRAPE_CONFIGS"
+	CodePrometheusRelabelConfig       = "PROMETHEUS_RELABEL_CONFIG"
+	CodeTrivyCriticalVulnerability    = "TRIVY_CRITICAL_VULNERABILITY"
+	CodeTrivyConfigAudit              = "TRIVY_CONFIG_AUDIT"
+)
+
+// FailureCode describes a failure code in the catalog.
+type FailureCode struct {
+	Code string
+	// Analyzer is the filter reporting the code, or a comma separated list of
+	// filters if several report it.
+	Analyzer    string
+	Description string
+	// Remediation is a deterministic hint on how to fix the failure, if it
+	// has a mechanical fix.
+	Remediation string
+}
+
+var failureCodes = []FailureCode{
+	{CodeAPIServiceUnavailable, "APIService", "An aggregated API is not available, discovery of its group fails", "Fix the service serving the API, or delete the APIService if the API, e.g. metrics-server, is no longer installed."},
+	{CodeAPIServiceServiceNotFound, "APIService", "The Service serving an aggregated API does not exist", "Reinstall the extension API server, or delete the APIService if it is no longer installed."},
+	{CodeAPIServiceNoActivePods, "APIService", "No pods back the Service serving an aggregated API", "Check why the pods of the extension API server are not running, e.g. with `kubectl get deploy -n <namespace>`."},
+	{CodeAPIServiceInactivePod, "APIService", "A pod behind the Service serving an aggregated API is not running", ""},
+	{CodeCRDNamesNotAccepted, "CustomResourceDefinition", "The names of the CRD conflict with another resource", "Change spec.names so the plural, singular, short names and kind are unique in the group."},
+	{CodeCRDNotEstablished, "CustomResourceDefinition", "The CRD is not established and its resources are not served", ""},
+	{CodeCRDConversionServiceNotFound, "CustomResourceDefinition", "The Service of the conversion webhook does not exist", "Reinstall the operator serving the conversion webhook, or fix spec.conversion.webhook.clientConfig.service."},
+	{CodeCRDConversionNoActivePods, "CustomResourceDefinition", "No pods back the Service of the conversion webhook", "Check why the pods of the operator serving the conversion webhook are not running."},
+	{CodeCRDConversionInactivePod, "CustomResourceDefinition", "A pod behind the Service of the conversion webhook is not running", ""},
+	{CodeCronJobSuspended, "CronJob", "The CronJob is suspended", "Resume the CronJob with `kubectl patch cronjob <name> -p '{\"spec\":{\"suspend\":false}}'` if it is not suspended on purpose."},
+	{CodeCronJobInvalidSchedule, "CronJob", "The schedule is not a valid cron expression", "Set spec.schedule to a valid cron expression with five fields, e.g. \"*/5 * * * *\"."},
+	{CodeCronJobNegativeDeadline, "CronJob", "The starting deadline is negative", "Set spec.startingDeadlineSeconds to a positive number of seconds or remove it."},
+	{CodeCertificateInvalid, "Certificate", "A TLS Secret holds no valid PEM encoded certificate", "Recreate the Secret with `kubectl create secret tls <name> --cert=<file> --key=<file>`."},
+	{CodeCertificateExpired, "Certificate", "The certificate has expired", "Renew the certificate and update the Secret; with cert-manager, check the Certificate with `cmctl status certificate <name>`."},
+	{CodeCertificateExpiring, "Certificate", "The certificate expires within the certificate_expiry_window", "Renew the certificate before it expires; with cert-manager, check that renewal is not failing."},
+	{CodeCertificateChainIncomplete, "Certificate", "The intermediate certificates are missing from tls.crt", "Append the intermediate certificates to tls.crt after the leaf certificate, clients do not fetch them."},
+	{CodeCertificateHostMismatch, "Certificate", "A host is served with a certificate that is not valid for it", "Issue a certificate whose DNS names include the host, or fix the host of the Ingress rule or Gateway listener."},
+	{CodeConfigMapNotFound, "ConfigReference", "A workload uses a ConfigMap that does not exist", "Create the ConfigMap in the namespace of the workload, fix its name, or mark the reference optional."},
+	{CodeConfigMapKeyNotFound, "ConfigReference", "A workload uses a key a ConfigMap does not have", "Add the key to the ConfigMap or fix the key in the workload; `kubectl get configmap <name> -o yaml` lists its keys."},
+	{CodeSecretNotFound, "ConfigReference", "A workload uses a Secret that does not exist", "Create the Secret in the namespace of the workload, fix its name, or mark the reference optional."},
+	{CodeSecretKeyNotFound, "ConfigReference", "A workload uses a key a Secret does not have", "Add the key to the Secret or fix the key in the workload; `kubectl describe secret <name>` lists its keys."},
+	{CodeConfigReferenceUnused, "ConfigReference", "Low severity: no pod, workload, ServiceAccount or Ingress uses the ConfigMap or Secret", "Delete the object if nothing outside of the cluster uses it, unused credentials should be revoked."},
+	{CodeCronJobConsecutiveFailures, "CronJob", "The most recent runs failed", "Inspect the failed Job named in the failure with `kubectl describe job <name>` and the logs of its pods."},
+	{CodeCronJobMissedSchedules, "CronJob", "Scheduled runs did not start in time", "Check that the CronJob controller is healthy, that a previous run is not blocking a Forbid concurrency policy, and that spec.startingDeadlineSeconds is not too short."},
+	{CodeDaemonSetPodsNotReady, "DaemonSet", "Fewer pods are ready than nodes the DaemonSet should run on", "Check the events and logs of the DaemonSet's pods that are not ready, e.g. with `kubectl get pods -o wide` to find their nodes."},
+	{CodeDaemonSetPodsUnavailable, "DaemonSet", "Ready pods have not been ready for spec.minReadySeconds yet", ""},
+	{CodeDaemonSetPodsMisscheduled, "DaemonSet", "Pods run on nodes the DaemonSet should not run on", "Check whether the node labels or taints changed; the DaemonSet controller removes pods from nodes that no longer match."},
+	{CodeDaemonSetRolloutStalled, "DaemonSet", "The rolling update is blocked by unavailable pods", "Fix the pods that are not available or roll back with `kubectl rollout undo daemonset <name>`; the update only continues once they are available."},
+	{CodeDaemonSetNoMatchingNodes, "DaemonSet", "The DaemonSet runs on no node, its node selector and required node affinity match no node", "Fix spec.template.spec.nodeSelector and nodeAffinity, or label the nodes the DaemonSet should run on."},
+	{CodeDaemonSetNodesTainted, "DaemonSet", "The DaemonSet runs on no node, all the nodes it selects have taints the pods do not tolerate", "Add tolerations for the taints to spec.template.spec.tolerations if the DaemonSet, e.g. a CNI or logging agent, must run on every node."},
+	{CodeDeploymentReplicaMismatch, "Deployment", "Fewer replicas are available than desired", "Check the events of the Deployment's ReplicaSet and pods; pods that cannot be scheduled or keep crashing block the rollout."},
+	{CodeDeprecatedAPIDeprecated, "DeprecatedAPI", "An object uses an API version deprecated in the target Kubernetes version", "Update the apiVersion of the manifest to the replacement in the failure and reapply it before the version is removed."},
+	{CodeDeprecatedAPIRemoved, "DeprecatedAPI", "An object uses an API version removed in the target Kubernetes version", "Update the apiVersion of the manifest to the replacement in the failure; for Helm releases, upgrade the chart or use the helm-mapkubeapis plugin."},
+	{CodeEventWarning, "Event", "The object had Warning events in the events_window", "Read the events of the object with `kubectl events --for <kind>/<name>`; the reason, e.g. FailedMount or BackOff, names the failing step."},
+	{CodeGatewayClassNotFound, "Gateway", "The GatewayClass of the Gateway does not exist", ""},
+	{CodeGatewayNotAccepted, "Gateway", "The Gateway is not accepted by its controller", ""},
+	{CodeGatewayClassNotAccepted, "GatewayClass", "The GatewayClass is not accepted by its controller", ""},
+	{CodeHPAInvalidScaleTargetKind, "HorizontalPodAutoScaler", "The scale target is of a kind that cannot be scaled", "Point spec.scaleTargetRef at a Deployment, ReplicaSet, StatefulSet or ReplicationController."},
+	{CodeHPAScaleTargetNotFound, "HorizontalPodAutoScaler", "The scale target does not exist", "Create the scale target or fix spec.scaleTargetRef.name and kind to match an existing object in the same namespace."},
+	{CodeHPAScaleTargetNoResources, "HorizontalPodAutoScaler", "The containers of the scale target have no resources configured", "Set resources.requests and resources.limits on the containers of the scale target; the autoscaler needs them to compute utilization."},
+	{CodeHTTPRouteGatewayNotFound, "HTTPRoute", "The parent Gateway does not exist", ""},
+	{CodeHTTPRouteNamespaceNotAllowed, "HTTPRoute", "The Gateway only allows routes from its own namespace", ""},
+	{CodeHTTPRouteSelectorMismatch, "HTTPRoute", "The Gateway's route selector does not match the route", ""},
+	{CodeHTTPRouteServiceNotFound, "HTTPRoute", "The backend Service does not exist", ""},
+	{CodeHTTPRoutePortMismatch, "HTTPRoute", "The backend port is not a port of the Service", ""},
+	{CodeIngressNoClass, "Ingress", "The Ingress does not specify an ingress class", "Set spec.ingressClassName to one of the classes listed by `kubectl get ingressclass`, or mark an IngressClass as default."},
+	{CodeIngressClassNotFound, "Ingress", "The ingress class does not exist", "Set spec.ingressClassName to one of the classes listed by `kubectl get ingressclass`, or install the missing ingress controller."},
+	{CodeIngressServiceNotFound, "Ingress", "A backend Service does not exist", "Create the backend Service or fix the service name of the Ingress rule."},
+	{CodeIngressTLSSecretNotFound, "Ingress", "A TLS Secret does not exist", "Create the TLS Secret with `kubectl create secret tls <name> --cert=<file> --key=<file>` or fix spec.tls[].secretName."},
+	{CodeJobBackoffLimitExceeded, "Job", "The pods failed more often than the backoff limit allows", "Fix the cause of the pod failures, see the logs of the failed pods, then delete and recreate the Job; raise spec.backoffLimit only for flaky workloads."},
+	{CodeJobDeadlineExceeded, "Job", "The Job ran longer than spec.activeDeadlineSeconds", "Find out why the Job is slow or raise spec.activeDeadlineSeconds."},
+	{CodeJobFailed, "Job", "The Job failed", ""},
+	{CodeJobPodFailed, "Job", "A container of the Job's pods exited with an error", "Read the logs of the failed pods with `kubectl logs job/<name> --all-containers`; exit code 137 usually means the memory limit is too low."},
+	{CodeJobNoActivePods, "Job", "The Job has no running pods but has not finished", "Check the events of the Job for pods that cannot be created, e.g. because of a ResourceQuota or a missing ServiceAccount."},
+	{CodeLimitRangeInvalidDefault, "LimitRange", "A default of the LimitRange violates its own minimum, maximum or default limit", "Fix spec.limits so that min <= defaultRequest <= default <= max."},
+	{CodeLimitRangeConflict, "LimitRange", "The requests or limits of a container violate the bounds of the LimitRange, its pods are rejected", "Set the requests and limits of the container within the min and max of the LimitRange, or adjust the LimitRange."},
+	{CodeLogFetchFailed, "Log", "The logs of a container could not be read", ""},
+	{CodeLogErrors, "Log", "The logs of a container contain errors", ""},
+	{CodeNamespaceTerminating, "Namespace", "The namespace has been Terminating for several minutes", ""},
+	{CodeNamespaceDeletionFailed, "Namespace", "The namespace controller failed to discover or delete the content of the namespace", ""},
+	{CodeNamespaceFinalizersRemaining, "Namespace", "Objects in the namespace have finalizers that are not removed", "Check that the controllers owning the finalizers are running; remove a finalizer by hand only if its controller is gone for good."},
+	{CodeNamespaceObjectsRemaining, "Namespace", "Objects in the namespace wait for their finalizers to be removed", "Check that the controllers owning the finalizers are running; remove a finalizer by hand only if its controller is gone for good."},
+	{CodeNamespaceAPIServiceUnavailable, "Namespace", "An unavailable APIService prevents the namespace controller from discovering resources to delete", "Fix the service behind the APIService, or delete the APIService if its API is no longer installed."},
+	{CodeObjectDeletionBlocked, "Finalizer", "A deleted object still has finalizers that are not removed", "Check that the controller owning the finalizer is running; remove the finalizer by hand only if its controller is gone for good."},
+	{CodeNetworkPolicyAllowsAll, "NetworkPolicy", "The policy applies to all pods", "Narrow spec.podSelector to the pods the policy is meant for unless allowing all pods is intended."},
+	{CodeNetworkPolicyNoPods, "NetworkPolicy", "The policy does not apply to any pod", "Fix spec.podSelector to match the labels of existing pods or delete the unused policy."},
+	{CodeNodeNotReady, "Node", "The node is not ready", ""},
+	{CodeNodeCondition, "Node", "The node reports a problem condition, e.g. memory or disk pressure", ""},
+	{CodePDBNoMatchingPods, "PodDisruptionBudget", "The budget does not match any pod", "Fix spec.selector to match the labels of the pods the budget should protect."},
+	{CodePodUnschedulable, "Pod", "The pod cannot be scheduled", ""},
+	{CodePodSandboxFailed, "Pod", "The sandbox of the pod could not be created", ""},
+	{CodePodMountFailed, "Pod", "A volume of the pod could not be mounted", ""},
+	{CodePodCrashLoop, "Pod", "A container keeps crashing", ""},
+	{CodePodImagePullFailed, "Pod", "The image of a container cannot be pulled", "Check the image name and tag, and add imagePullSecrets if the registry is private."},
+	{CodePodContainerConfigError, "Pod", "The configuration of a container is invalid, e.g. a missing ConfigMap or Secret", "Create the ConfigMaps and Secrets the container references or fix the references."},
+	{CodePodContainerStartFailed, "Pod", "A container could not be created or started", ""},
+	{CodePodReadinessProbeFailed, "Pod", "A readiness probe fails", ""},
+	{CodePVCProvisioningFailed, "PersistentVolumeClaim", "The volume could not be provisioned", ""},
+	{CodePVReleased, "PersistentVolume", "The claim of a volume with the Retain reclaim policy was deleted, the volume cannot be bound again", "Back up or delete the data and then the PersistentVolume, or remove spec.claimRef to make it available to a new claim."},
+	{CodePVReclaimStuck, "PersistentVolume", "A Released volume is not deleted or recycled by its reclaim policy", "Check the logs of the provisioner and that the storage backend still has the volume."},
+	{CodePVFailed, "PersistentVolume", "The volume failed its automatic reclamation", ""},
+	{CodePVReclaimPolicy, "PersistentVolume", "The reclaim policy may delete or wipe data unexpectedly", "Set spec.persistentVolumeReclaimPolicy to Retain on statically created volumes holding data that must survive the claim."},
+	{CodePVNodeAffinityUnsatisfiable, "PersistentVolume", "No node satisfies the node affinity of the volume", "Check that the node the local volume is on still exists and is labelled as spec.nodeAffinity expects."},
+	{CodePVCNotMounted, "PersistentVolume", "A bound claim is not mounted by any pod", "Delete the PersistentVolumeClaim if its data is no longer needed."},
+	{CodeReplicaSetCreateFailed, "ReplicaSet", "The ReplicaSet cannot create pods", ""},
+	{CodeServiceWarningEvent, "Service", "The endpoints of the Service have warning events", ""},
+	{CodeServiceNoEndpoints, "Service", "The Service selector matches no pods", "Fix spec.selector to match the labels of running pods, compare with `kubectl get pods --show-labels`."},
+	{CodeServiceEndpointsNotReady, "Service", "Pods behind the Service are not ready", "Check the readiness probes and events of the pods behind the Service."},
+	{CodeResourceQuotaNearLimit, "ResourceQuota", "A resource of the quota is used above 90% of its hard limit", "Raise spec.hard of the ResourceQuota or reduce the usage before new pods are rejected."},
+	{CodeResourceQuotaAtLimit, "ResourceQuota", "A resource of the quota is used up, new objects using it are rejected", "Raise spec.hard of the ResourceQuota or scale down workloads in the namespace."},
+	{CodeResourceQuotaMissingRequests, "ResourceQuota", "The quota limits a resource the containers of a workload set no request or limit for, its pods are rejected", "Set the resources of every container, or add a LimitRange with defaults to the namespace."},
+	{CodeResourceQuotaWorkloadDoesNotFit, "ResourceQuota", "The missing replicas of a workload need more than the quota has left", "Raise spec.hard of the ResourceQuota, reduce the requests of the workload or its replicas."},
+	{CodeStatefulSetServiceNotFound, "StatefulSet", "The governing Service does not exist", "Create the headless Service named in spec.serviceName or fix the name."},
+	{CodeStorageClassNoDefault, "StorageClass", "No StorageClass is marked as default", "Mark a class as default with `kubectl annotate storageclass <name> storageclass.kubernetes.io/is-default-class=true`."},
+	{CodeStorageClassMultipleDefaults, "StorageClass", "Several StorageClasses are marked as default", "Remove the storageclass.kubernetes.io/is-default-class annotation from all but one class."},
+	{CodeStorageClassProvisionerNotFound, "StorageClass", "The provisioner of the class has no CSIDriver object and claims of the class are not provisioned", "Install the CSI driver of the provisioner, or fix spec.provisioner; `kubectl get csidrivers` lists the installed drivers."},
+	{CodeStatefulSetStorageClassNotFound, "StatefulSet", "The storage class of a volume claim template does not exist", "Set storageClassName in spec.volumeClaimTemplates to a class listed by `kubectl get storageclass`."},
+	{CodeVolumeAttachFailed, "VolumeAttachment", "A volume could not be attached to its node", "Check the logs of the CSI controller and that the volume is not still attached to another node."},
+	{CodeVolumeAttachStuck, "VolumeAttachment", "A volume has not been attached to its node for several minutes", "Check that the CSI driver's external-attacher is running."},
+	{CodeVolumeDetachFailed, "VolumeAttachment", "A volume could not be detached from its node", "Check the logs of the CSI controller; a node that is gone may need the volume detached in the storage backend."},
+	{CodeWebhookServiceNotFound, "MutatingWebhookConfiguration, ValidatingWebhookConfiguration", "The Service of a webhook does not exist", "Create the Service of the webhook or fix clientConfig.service; until then every matching API request fails."},
+	{CodeWebhookNoActivePods, "MutatingWebhookConfiguration, ValidatingWebhookConfiguration", "The Service of a webhook has no running pods", "Start the pods of the webhook Service or fix its selector; until then every matching API request fails."},
+	{CodeWebhookInactivePod, "MutatingWebhookConfiguration, ValidatingWebhookConfiguration", "A pod of a webhook is not running", "Check why the webhook pod is not running, e.g. with `kubectl describe pod`."},
+	{CodeEKSHealthIssue, "EKS", "The EKS cluster reports a health issue", ""},
+	{CodeScaledObjectInvalidTargetKind, "ScaledObject", "The scale target is of a kind that cannot be scaled", "Point spec.scaleTargetRef at a Deployment, ReplicaSet or StatefulSet."},
+	{CodeScaledObjectTargetNotFound, "ScaledObject", "The scale target does not exist", "Create the scale target or fix spec.scaleTargetRef.name to match an existing object in the same namespace."},
+	{CodeScaledObjectTargetNoResources, "ScaledObject", "The containers of the scale target have no resources configured for cpu or memory triggers", "Set resources.requests and resources.limits on the containers of the scale target."},
+	{CodeScaledObjectWarningEvent, "ScaledObject", "The ScaledObject has a warning event", ""},
+	{CodePrometheusConfigInvalid, "PrometheusConfigValidate", "The Prometheus configuration is invalid", ""},
+	{CodePrometheusNoScrapeConfigs, "PrometheusConfigValidate", "The Prometheus configuration has no scrape configurations", ""},
+	{CodePrometheusRelabelConfig, "PrometheusConfigRelabelReport", "A relabel configuration to review", ""},
+	{CodeTrivyCriticalVulnerability, "VulnerabilityReport", "An image has a critical vulnerability", ""},
+	{CodeTrivyConfigAudit, "ConfigAuditReport", "An object fails a configuration audit check", ""},
+}
+
+// FailureCodes lists the failure codes reported by the analyzers, sorted by
+// analyzer and code.
+func FailureCodes() []FailureCode {
+	codes := make([]FailureCode, len(failureCodes))
+	copy(codes, failureCodes)
+	sort.Slice(codes, func(i, j int) bool {
+		if codes[i].Analyzer != codes[j].Analyzer {
+			return codes[i].Analyzer < codes[j].Analyzer
+		}
+		return codes[i].Code < codes[j].Code
+	})
+	return codes
+}
+
+// Remediation returns the remediation hint of a failure code, if there is one.
+func Remediation(code string) string {
+	for _, c := range failureCodes {
+		if c.Code == code {
+			return c.Remediation
+		}
+	}
+	return ""
+}
+
+// AddRemediations attaches the hints of the catalog to the failures of results.
+func AddRemediations(results []Result) {
+	for i := range results {
+		for j := range results[i].Error {
+			failure := &results[i].Error[j]
+			if failure.Remediation == "" {
+				failure.Remediation = Remediation(failure.Code)
+			}
+		}
+	}
+}
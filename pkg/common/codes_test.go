@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRemediations(t *testing.T) {
+	results := []Result{
+		{
+			Kind: "Ingress",
+			Name: "default/web",
+			Error: []Failure{
+				{Text: "missing secret", Code: CodeIngressTLSSecretNotFound},
+				{Text: "custom hint", Code: CodeIngressNoClass, Remediation: "Ask the platform team"},
+				{Text: "no code"},
+				{Text: "unknown code", Code: "SOMETHING_ELSE"},
+			},
+		},
+	}
+
+	AddRemediations(results)
+
+	failures := results[0].Error
+	require.Equal(t, Remediation(CodeIngressTLSSecretNotFound), failures[0].Remediation)
+	require.NotEmpty(t, failures[0].Remediation)
+	require.Equal(t, "Ask the platform team", failures[1].Remediation)
+	require.Empty(t, failures[2].Remediation)
+	require.Empty(t, failures[3].Remediation)
+}
+
+func TestFailureCodes(t *testing.T) {
+	codes := FailureCodes()
+	require.NotEmpty(t, codes)
+
+	seen := map[string]bool{}
+	for i, code := range codes {
+		require.NotEmpty(t, code.Code)
+		require.NotEmpty(t, code.Analyzer, code.Code)
+		require.NotEmpty(t, code.Description, code.Code)
+		require.False(t, seen[code.Code], "duplicate code %s", code.Code)
+		seen[code.Code] = true
+		if i > 0 {
+			require.LessOrEqual(t, codes[i-1].Analyzer, code.Analyzer)
+		}
+	}
+}
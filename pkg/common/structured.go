@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StructuredResponse is the machine-readable form of an explanation.
+type StructuredResponse struct {
+	Summary    string   `json:"summary"`
+	RootCause  string   `json:"root_cause"`
+	Steps      []string `json:"steps"`
+	Commands   []string `json:"commands,omitempty"`
+	Confidence float64  `json:"confidence"`
+	References []string `json:"references,omitempty"`
+}
+
+// Validate checks that the mandatory fields are present and in range.
+func (r *StructuredResponse) Validate() error {
+	if strings.TrimSpace(r.Summary) == "" {
+		return errors.New("summary is empty")
+	}
+	if strings.TrimSpace(r.RootCause) == "" {
+		return errors.New("root_cause is empty")
+	}
+	if len(r.Steps) == 0 {
+		return errors.New("steps is empty")
+	}
+	if r.Confidence < 0 || r.Confidence > 1 {
+		return fmt.Errorf("confidence %v is not between 0 and 1", r.Confidence)
+	}
+	return nil
+}
+
+// String renders the response in the same "Error: … Solution: …" shape used by
+// the default prompt, so text consumers keep working in structured mode.
+func (r *StructuredResponse) String() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Error: %s\n", r.Summary))
+	b.WriteString(fmt.Sprintf("Root cause: %s\n", r.RootCause))
+	b.WriteString("Solution:\n")
+	for i, step := range r.Steps {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+	}
+	if len(r.Commands) > 0 {
+		b.WriteString("Commands:\n")
+		for _, cmd := range r.Commands {
+			b.WriteString(fmt.Sprintf("  %s\n", cmd))
+		}
+	}
+	if len(r.References) > 0 {
+		b.WriteString("References:\n")
+		for _, ref := range r.References {
+			b.WriteString(fmt.Sprintf("- %s\n", ref))
+		}
+	}
+	return b.String()
+}
+
+// ParseStructuredResponse decodes and validates a model answer. Markdown code
+// fences and leading/trailing prose around the JSON object are tolerated.
+func ParseStructuredResponse(raw string) (*StructuredResponse, error) {
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start == -1 || end < start {
+		return nil, errors.New("no JSON object found in response")
+	}
+
+	var response StructuredResponse
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &response); err != nil {
+		return nil, fmt.Errorf("decoding structured response: %w", err)
+	}
+	if err := response.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid structured response: %w", err)
+	}
+	return &response, nil
+}
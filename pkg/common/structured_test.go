@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStructuredResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    *StructuredResponse
+		expectedErr string
+	}{
+		{
+			name: "plain json",
+			raw:  `{"summary":"pod crashes","root_cause":"bad image","steps":["fix image"],"commands":["kubectl get pod"],"confidence":0.8}`,
+			expected: &StructuredResponse{
+				Summary:    "pod crashes",
+				RootCause:  "bad image",
+				Steps:      []string{"fix image"},
+				Commands:   []string{"kubectl get pod"},
+				Confidence: 0.8,
+			},
+		},
+		{
+			name: "fenced json",
+			raw:  "```json\n{\"summary\":\"s\",\"root_cause\":\"r\",\"steps\":[\"a\",\"b\"],\"confidence\":1}\n```",
+			expected: &StructuredResponse{
+				Summary:    "s",
+				RootCause:  "r",
+				Steps:      []string{"a", "b"},
+				Confidence: 1,
+			},
+		},
+		{
+			name:        "free text",
+			raw:         "Error: something\nSolution: do it",
+			expectedErr: "no JSON object found",
+		},
+		{
+			name:        "missing steps",
+			raw:         `{"summary":"s","root_cause":"r","confidence":0.5}`,
+			expectedErr: "steps is empty",
+		},
+		{
+			name:        "confidence out of range",
+			raw:         `{"summary":"s","root_cause":"r","steps":["a"],"confidence":5}`,
+			expectedErr: "not between 0 and 1",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStructuredResponse(tt.raw)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, got)
+			} else {
+				require.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestStructuredResponseString(t *testing.T) {
+	r := StructuredResponse{
+		Summary:   "pod crashes",
+		RootCause: "bad image",
+		Steps:     []string{"fix image", "redeploy"},
+	}
+	require.Equal(t, "Error: pod crashes\nRoot cause: bad image\nSolution:\n1. fix image\n2. redeploy\n", r.String())
+}
@@ -24,6 +24,7 @@ import (
 	regv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	autov1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	networkv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -55,6 +56,8 @@ type PreAnalysis struct {
 	HorizontalPodAutoscalers autov1.HorizontalPodAutoscaler
 	PodDisruptionBudget      policyv1.PodDisruptionBudget
 	StatefulSet              appsv1.StatefulSet
+	DaemonSet                appsv1.DaemonSet
+	Job                      batchv1.Job
 	NetworkPolicy            networkv1.NetworkPolicy
 	Node                     v1.Node
 	ValidatingWebhook        regv1.ValidatingWebhookConfiguration
@@ -69,17 +72,38 @@ type PreAnalysis struct {
 }
 
 type Result struct {
-	Kind         string    `json:"kind"`
-	Name         string    `json:"name"`
-	Error        []Failure `json:"error"`
-	Details      string    `json:"details"`
-	ParentObject string    `json:"parentObject"`
+	Kind         string              `json:"kind"`
+	Name         string              `json:"name"`
+	Error        []Failure           `json:"error"`
+	Details      string              `json:"details"`
+	Structured   *StructuredResponse `json:"structured,omitempty"`
+	ParentObject string              `json:"parentObject"`
 }
 
 type Failure struct {
 	Text          string
 	KubernetesDoc string
 	Sensitive     []Sensitive
+	// Code identifies the kind of failure, see FailureCodes.
+	Code string `json:",omitempty"`
+	// Remediation is a hint on how to fix the failure that needs no AI backend.
+	Remediation string `json:",omitempty"`
+	// Container, Reason and Reference describe the failure in machine-readable
+	// form where they apply.
+	Container string           `json:",omitempty"`
+	Reason    string           `json:",omitempty"`
+	Reference *ObjectReference `json:",omitempty"`
+	// Utilization is the share in percent of a limit that is used, by
+	// resource, e.g. requests.cpu of a ResourceQuota.
+	Utilization map[string]float64 `json:",omitempty"`
+}
+
+// ObjectReference points at an object a failure refers to, e.g. a missing
+// Secret or the scale target of an autoscaler.
+type ObjectReference struct {
+	Kind      string
+	Namespace string `json:",omitempty"`
+	Name      string
 }
 
 type Sensitive struct {
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
@@ -67,6 +68,8 @@ func (e *EKSAnalyzer) Analyze(analysis common.Analyzer) ([]common.Result, error)
 					Text:          issue.String(),
 					KubernetesDoc: "",
 					Sensitive:     nil,
+					Code:          common.CodeEKSHealthIssue,
+					Reason:        awssdk.StringValue(issue.Code),
 				})
 				cr = append(cr, common.Result{
 					Kind:  "EKS",
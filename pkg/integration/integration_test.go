@@ -15,6 +15,7 @@ package integration
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -75,10 +76,8 @@ func TestActivate(t *testing.T) {
 	err = integration.Deactivate("prometheus", "")
 	require.ErrorContains(t, err, "error writing config file:")
 
-	configFileName := "config.json"
-	_, err = os.CreateTemp("", configFileName)
-	require.NoError(t, err)
-	defer os.Remove(configFileName)
+	configFileName := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configFileName, []byte("{}"), 0o600))
 
 	// Set the configuration file in viper
 	viper.SetConfigType("json")
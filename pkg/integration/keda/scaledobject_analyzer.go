@@ -66,6 +66,7 @@ func (s *ScaledObjectAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 		default:
 			failures = append(failures, common.Failure{
 				Text:      fmt.Sprintf("ScaledObject uses %s as ScaleTargetRef which is not an option.", scaleTargetRef.Kind),
+				Code:      common.CodeScaledObjectInvalidTargetKind,
 				Sensitive: []common.Sensitive{},
 			})
 		}
@@ -76,6 +77,8 @@ func (s *ScaledObjectAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 			failures = append(failures, common.Failure{
 				Text:          fmt.Sprintf("ScaledObject uses %s/%s as ScaleTargetRef which does not exist.", scaleTargetRef.Kind, scaleTargetRef.Name),
 				KubernetesDoc: doc,
+				Code:          common.CodeScaledObjectTargetNotFound,
+				Reference:     &common.ObjectReference{Kind: scaleTargetRef.Kind, Namespace: so.Namespace, Name: scaleTargetRef.Name},
 				Sensitive: []common.Sensitive{
 					{
 						Unmasked: scaleTargetRef.Name,
@@ -102,6 +105,8 @@ func (s *ScaledObjectAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 				failures = append(failures, common.Failure{
 					Text:          fmt.Sprintf("%s %s/%s does not have resource configured.", scaleTargetRef.Kind, so.Namespace, scaleTargetRef.Name),
 					KubernetesDoc: doc,
+					Code:          common.CodeScaledObjectTargetNoResources,
+					Reference:     &common.ObjectReference{Kind: scaleTargetRef.Kind, Namespace: so.Namespace, Name: scaleTargetRef.Name},
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: scaleTargetRef.Name,
@@ -119,6 +124,7 @@ func (s *ScaledObjectAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 			if evt.Type != "Normal" {
 				failures = append(failures, common.Failure{
 					Text: evt.Message,
+					Code: common.CodeScaledObjectWarningEvent,
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: scaleTargetRef.Name,
@@ -65,12 +65,14 @@ func (c *ConfigAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		if err != nil {
 			failures = append(failures, common.Failure{
 				Text: fmt.Sprintf("error validating Prometheus YAML configuration: %s", err),
+				Code: common.CodePrometheusConfigInvalid,
 			})
 		}
 		_, err = yaml.Marshal(config)
 		if err != nil {
 			failures = append(failures, common.Failure{
 				Text: fmt.Sprintf("error validating Prometheus struct configuration: %s", err),
+				Code: common.CodePrometheusConfigInvalid,
 			})
 		}
 
@@ -78,6 +80,7 @@ func (c *ConfigAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		if len(config.ScrapeConfigs) == 0 {
 			failures = append(failures, common.Failure{
 				Text: "no scrape configurations. Prometheus will not scrape any metrics.",
+				Code: common.CodePrometheusNoScrapeConfigs,
 			})
 		}
 
@@ -58,6 +58,7 @@ func (r *RelabelAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			}
 			failures = append(failures, common.Failure{
 				Text: fmt.Sprintf("job_name:\n%s\nrelabel_configs:\n%s\nkubernetes_sd_configs:\n%s\n", sc.JobName, string(brc), string(bsd)),
+				Code: common.CodePrometheusRelabelConfig,
 			})
 			i++
 		}
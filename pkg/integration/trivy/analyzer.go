@@ -55,6 +55,7 @@ func (TrivyAnalyzer) analyzeVulnerabilityReports(a common.Analyzer) ([]common.Re
 				// get the vulnerability description
 				failures = append(failures, common.Failure{
 					Text:      fmt.Sprintf("critical Vulnerability found ID: %s (learn more at: %s)", vuln.VulnerabilityID, vuln.PrimaryLink),
+					Code:      common.CodeTrivyCriticalVulnerability,
 					Sensitive: []common.Sensitive{},
 				})
 			}
@@ -108,6 +109,7 @@ func (t TrivyAnalyzer) analyzeConfigAuditReports(a common.Analyzer) ([]common.Re
 			if check.Severity == "MEDIUM" || check.Severity == "HIGH" || check.Severity == "CRITICAL" {
 				failures = append(failures, common.Failure{
 					Text: fmt.Sprintf("Config issue with severity \"%s\" found: %s", check.Severity, strings.Join(check.Messages, "")),
+					Code: common.CodeTrivyConfigAudit,
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: report.Labels["trivy-operator.resource.name"],
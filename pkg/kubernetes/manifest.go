@@ -0,0 +1,320 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+const (
+	defaultNamespace = "default"
+	fieldManager     = "application/apply-patch"
+)
+
+// ManifestAction is what applying an object of a manifest does to the cluster.
+type ManifestAction string
+
+const (
+	ManifestCreate    ManifestAction = "create"
+	ManifestUpdate    ManifestAction = "update"
+	ManifestUnchanged ManifestAction = "unchanged"
+)
+
+// ManifestObject is an object of a manifest together with the outcome of its
+// validation. Live is the object currently in the cluster, if any, and DryRun
+// the object the cluster would store when the manifest is applied.
+type ManifestObject struct {
+	Object *unstructured.Unstructured
+	Action ManifestAction
+	Errors []string
+	Live   *unstructured.Unstructured
+	DryRun *unstructured.Unstructured
+}
+
+func (o ManifestObject) String() string {
+	if o.Object.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", o.Object.GetKind(), o.Object.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", o.Object.GetKind(), o.Object.GetNamespace(), o.Object.GetName())
+}
+
+// ParseManifest decodes the YAML or JSON documents of a manifest. Text around
+// a fenced code block, as models tend to write, is ignored.
+func ParseManifest(completion string) ([]*unstructured.Unstructured, error) {
+	preprocessCompletion(&completion)
+	decoder := yamlutil.NewYAMLOrJSONDecoder(strings.NewReader(completion), 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		var rawObj runtime.RawExtension
+		if err := decoder.Decode(&rawObj); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error decoding: %w", err)
+		}
+		if len(bytes.TrimSpace(rawObj.Raw)) == 0 || string(rawObj.Raw) == "null" {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(rawObj.Raw); err != nil {
+			return nil, fmt.Errorf("error decoding object %d: %w", len(objects)+1, err)
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("%s number %d has no metadata.name", obj.GetKind(), len(objects)+1)
+		}
+		objects = append(objects, obj)
+	}
+	if len(objects) == 0 {
+		return nil, errors.New("no Kubernetes objects found")
+	}
+	return objects, nil
+}
+
+// ValidateManifest parses a manifest, validates every object against the
+// OpenAPI schema of the cluster and dry-runs it with server-side apply.
+// Objects without a namespace are placed into namespace.
+func (c *Client) ValidateManifest(ctx context.Context, completion string, namespace string) ([]ManifestObject, error) {
+	objects, err := ParseManifest(completion)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := c.Client.Discovery().OpenAPISchema()
+	if err != nil {
+		return nil, err
+	}
+	dd, mapper, err := c.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ManifestObject
+	for _, obj := range objects {
+		result := ManifestObject{Object: obj, Errors: ValidateSchema(schema, obj)}
+		if len(result.Errors) == 0 {
+			err = dryRun(ctx, dd, mapper, &result, namespace)
+			if err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ApplyManifest applies a manifest with server-side apply. Objects without a
+// namespace are placed into namespace, or the default namespace if it is empty.
+func (c *Client) ApplyManifest(completion string, namespace string) error {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	objects, err := ParseManifest(completion)
+	if err != nil {
+		return err
+	}
+	dd, mapper, err := c.dynamicClient()
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		dri, err := resourceInterface(dd, mapper, obj, namespace)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if _, err := dri.Patch(context.Background(), obj.GetName(), types.ApplyPatchType, data, applyOptions(false)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOptions are the options of the server-side apply of a manifest object.
+// The dry-run uses the same options as the apply, so that it reports the
+// conflicts with the fields of other managers the apply would run into.
+func applyOptions(dryRun bool) metav1.PatchOptions {
+	options := metav1.PatchOptions{
+		FieldManager:    fieldManager,
+		FieldValidation: "Strict",
+	}
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	return options
+}
+
+func (c *Client) dynamicClient() (dynamic.Interface, meta.RESTMapper, error) {
+	dd, err := dynamic.NewForConfig(c.GetConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	cl, err := discovery.NewDiscoveryClientForConfig(c.GetConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	gr, err := restmapper.GetAPIGroupResources(cl)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dd, restmapper.NewDiscoveryRESTMapper(gr), nil
+}
+
+// resourceInterface returns the dynamic client for obj, defaulting the
+// namespace of namespaced objects.
+func resourceInterface(dd dynamic.Interface, mapper meta.RESTMapper, obj *unstructured.Unstructured, namespace string) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return dd.Resource(mapping.Resource), nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+	}
+	return dd.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+// dryRun applies the object of result server side without persisting it and
+// records whether it would be created, updated or left unchanged.
+func dryRun(ctx context.Context, dd dynamic.Interface, mapper meta.RESTMapper, result *ManifestObject, namespace string) error {
+	obj := result.Object
+	dri, err := resourceInterface(dd, mapper, obj, namespace)
+	if err != nil {
+		return err
+	}
+	existing, err := dri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	applied, err := dri.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, applyOptions(true))
+	if k8serrors.IsConflict(err) {
+		return fmt.Errorf("fields of the object are managed by another field manager, e.g. kubectl, and would not be changed: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	result.DryRun = applied
+
+	switch {
+	case !found:
+		result.Action = ManifestCreate
+	case reflect.DeepEqual(withoutServerFields(existing), withoutServerFields(applied)):
+		result.Live = existing
+		result.Action = ManifestUnchanged
+	default:
+		result.Live = existing
+		result.Action = ManifestUpdate
+	}
+	return nil
+}
+
+// Diff shows the changes applying the object makes to the cluster as a
+// unified diff of YAML. New objects are diffed against an empty document and
+// shown as generated, without the defaults the server would add.
+func (o ManifestObject) Diff() (string, error) {
+	after := o.DryRun
+	if o.Live == nil || after == nil {
+		after = o.Object
+	}
+	return DiffObjects(o.Live, after, o.String())
+}
+
+// DiffObjects renders the change from one version of an object to another as
+// a unified diff of YAML. A nil from stands for an object that does not exist.
+func DiffObjects(from *unstructured.Unstructured, to *unstructured.Unstructured, name string) (string, error) {
+	after, err := diffYAML(to)
+	if err != nil {
+		return "", err
+	}
+	var before string
+	if from != nil {
+		if before, err = diffYAML(from); err != nil {
+			return "", err
+		}
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: "live/" + name,
+		ToFile:   "generated/" + name,
+		Context:  3,
+	})
+}
+
+func diffYAML(obj *unstructured.Unstructured) (string, error) {
+	c := withoutServerFields(obj)
+	unstructured.RemoveNestedField(c, "metadata", "uid")
+	unstructured.RemoveNestedField(c, "metadata", "creationTimestamp")
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// withoutServerFields strips the fields the server changes on every apply.
+func withoutServerFields(obj *unstructured.Unstructured) map[string]interface{} {
+	c := obj.DeepCopy().Object
+	unstructured.RemoveNestedField(c, "metadata", "managedFields")
+	unstructured.RemoveNestedField(c, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(c, "metadata", "generation")
+	unstructured.RemoveNestedField(c, "status")
+	return c
+}
+
+func preprocessCompletion(completion *string) {
+	// Remove markdown if present
+	if start := strings.Index(*completion, "```"); start >= 0 {
+		// remove everything up to the end of the opening fence line
+		rest := (*completion)[start+3:]
+		if newline := strings.Index(rest, "\n"); newline >= 0 {
+			rest = rest[newline+1:]
+		}
+		if end := strings.Index(rest, "```"); end >= 0 {
+			rest = rest[:end]
+		}
+		*completion = rest
+	}
+}
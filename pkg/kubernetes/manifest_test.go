@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name        string
+		completion  string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:       "fenced with prose",
+			completion: "Here you go:\n```yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: b\n```\nEnjoy!",
+			expected:   []string{"ConfigMap/a", "Service/b"},
+		},
+		{
+			name:       "raw yaml with empty documents",
+			completion: "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\n",
+			expected:   []string{"ConfigMap/a"},
+		},
+		{
+			name:        "missing name",
+			completion:  "apiVersion: v1\nkind: ConfigMap\n",
+			expectedErr: "has no metadata.name",
+		},
+		{
+			name:        "not yaml",
+			completion:  "apiVersion: v1\nkind: [",
+			expectedErr: "error decoding",
+		},
+		{
+			name:        "empty",
+			completion:  "```yaml\n```",
+			expectedErr: "no Kubernetes objects found",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := ParseManifest(tt.completion)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, obj := range objects {
+				names = append(names, obj.GetKind()+"/"+obj.GetName())
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func namedSchema(name string, schema *openapi_v2.Schema) *openapi_v2.NamedSchema {
+	return &openapi_v2.NamedSchema{Name: name, Value: schema}
+}
+
+func typed(t string) *openapi_v2.Schema {
+	return &openapi_v2.Schema{Type: &openapi_v2.TypeItem{Value: []string{t}}}
+}
+
+func TestValidateSchema(t *testing.T) {
+	doc := &openapi_v2.Document{
+		Definitions: &openapi_v2.Definitions{
+			AdditionalProperties: []*openapi_v2.NamedSchema{
+				namedSchema("io.k8s.api.apps.v1.Deployment", &openapi_v2.Schema{
+					Type: &openapi_v2.TypeItem{Value: []string{"object"}},
+					VendorExtension: []*openapi_v2.NamedAny{{
+						Name:  "x-kubernetes-group-version-kind",
+						Value: &openapi_v2.Any{Yaml: "- group: apps\n  kind: Deployment\n  version: v1\n"},
+					}},
+					Properties: &openapi_v2.Properties{AdditionalProperties: []*openapi_v2.NamedSchema{
+						namedSchema("apiVersion", typed("string")),
+						namedSchema("kind", typed("string")),
+						namedSchema("metadata", &openapi_v2.Schema{XRef: "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"}),
+						namedSchema("spec", &openapi_v2.Schema{XRef: "#/definitions/io.k8s.api.apps.v1.DeploymentSpec"}),
+					}},
+				}),
+				namedSchema("io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta", &openapi_v2.Schema{
+					Type: &openapi_v2.TypeItem{Value: []string{"object"}},
+					Properties: &openapi_v2.Properties{AdditionalProperties: []*openapi_v2.NamedSchema{
+						namedSchema("name", typed("string")),
+						namedSchema("labels", &openapi_v2.Schema{
+							Type:                 &openapi_v2.TypeItem{Value: []string{"object"}},
+							AdditionalProperties: &openapi_v2.AdditionalPropertiesItem{Oneof: &openapi_v2.AdditionalPropertiesItem_Schema{Schema: typed("string")}},
+						}),
+					}},
+				}),
+				namedSchema("io.k8s.api.apps.v1.DeploymentSpec", &openapi_v2.Schema{
+					Type:     &openapi_v2.TypeItem{Value: []string{"object"}},
+					Required: []string{"selector"},
+					Properties: &openapi_v2.Properties{AdditionalProperties: []*openapi_v2.NamedSchema{
+						namedSchema("replicas", typed("integer")),
+						namedSchema("selector", typed("object")),
+						namedSchema("paused", typed("boolean")),
+						namedSchema("strategies", &openapi_v2.Schema{
+							Type:  &openapi_v2.TypeItem{Value: []string{"array"}},
+							Items: &openapi_v2.ItemsItem{Schema: []*openapi_v2.Schema{typed("string")}},
+						}),
+					}},
+				}),
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		expected []string
+	}{
+		{
+			name:     "valid",
+			manifest: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  labels:\n    app: web\nspec:\n  replicas: 2\n  selector: {}\n",
+		},
+		{
+			name:     "unknown and missing fields",
+			manifest: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replica: 2\n",
+			expected: []string{
+				"Deployment web: spec.selector: required field is missing",
+				"Deployment web: spec.replica: unknown field",
+			},
+		},
+		{
+			name:     "wrong types",
+			manifest: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  labels:\n    app: [web]\nspec:\n  replicas: two\n  paused: 1\n  selector: {}\n  strategies: rolling\n",
+			expected: []string{
+				"Deployment web: metadata.labels.app: expected a string, got []interface {}",
+				"Deployment web: spec.paused: expected a boolean, got int64",
+				"Deployment web: spec.replicas: expected a number, got string",
+				"Deployment web: spec.strategies: expected a list, got string",
+			},
+		},
+		{
+			name:     "unknown kind",
+			manifest: "apiVersion: apps/v1\nkind: Deploymnet\nmetadata:\n  name: web\n",
+			expected: []string{"Deploymnet web: apps/v1 is not known to the cluster"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := ParseManifest(tt.manifest)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, ValidateSchema(doc, objects[0]))
+		})
+	}
+}
+
+func TestManifestObjectDiff(t *testing.T) {
+	configMap := func(value string, resourceVersion string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "a", "namespace": "default"},
+			"data":       map[string]interface{}{"key": value},
+		}}
+		if resourceVersion != "" {
+			obj.SetResourceVersion(resourceVersion)
+			obj.SetUID("1234")
+		}
+		return obj
+	}
+
+	tests := []struct {
+		name     string
+		object   ManifestObject
+		expected []string
+		excluded []string
+	}{
+		{
+			name:     "create",
+			object:   ManifestObject{Object: configMap("new", ""), DryRun: configMap("new", "1")},
+			expected: []string{"+++ generated/ConfigMap default/a", "+  key: new", "+kind: ConfigMap"},
+			excluded: []string{"uid", "resourceVersion"},
+		},
+		{
+			name: "update",
+			object: ManifestObject{
+				Object: configMap("new", ""),
+				Live:   configMap("old", "1"),
+				DryRun: configMap("new", "2"),
+			},
+			expected: []string{"--- live/ConfigMap default/a", "-  key: old", "+  key: new", " kind: ConfigMap"},
+			excluded: []string{"uid", "resourceVersion"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := tt.object.Diff()
+			require.NoError(t, err)
+			for _, expected := range tt.expected {
+				require.Contains(t, diff, expected)
+			}
+			for _, excluded := range tt.excluded {
+				require.NotContains(t, diff, excluded)
+			}
+		})
+	}
+}
+
+func TestDryRunConflict(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "a", "namespace": "default"},
+		"data":       map[string]interface{}{"key": "old"},
+	}}
+	dd := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), live)
+	dd.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "a",
+			errors.New(`Apply failed with 1 conflict: conflict with "kubectl-client-side-apply": .data.key`))
+	})
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	obj := live.DeepCopy()
+	obj.Object["data"] = map[string]interface{}{"key": "new"}
+	result := &ManifestObject{Object: obj}
+	err := dryRun(context.Background(), dd, mapper, result, "default")
+	require.ErrorContains(t, err, "managed by another field manager")
+	require.ErrorContains(t, err, `conflict with "kubectl-client-side-apply"`)
+}
+
+func TestApplyOptions(t *testing.T) {
+	// the dry-run must not force what the apply would not
+	dryRun, apply := applyOptions(true), applyOptions(false)
+	require.Equal(t, []string{metav1.DryRunAll}, dryRun.DryRun)
+	require.Empty(t, apply.DryRun)
+	dryRun.DryRun = nil
+	require.Equal(t, apply, dryRun)
+	require.Nil(t, apply.Force)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+const patchFieldManager = "k8sgpt-fix"
+
+// GetObject fetches an object by kind, e.g. "Deployment", using the preferred
+// version of the cluster.
+func (c *Client) GetObject(ctx context.Context, kind string, namespace string, name string) (*unstructured.Unstructured, error) {
+	dri, err := c.kindInterface(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return dri.Get(ctx, name, metav1.GetOptions{})
+}
+
+// PatchObject patches obj and returns the object stored by the cluster. With
+// dryRun set, the patch is validated and applied server side without being
+// persisted.
+func (c *Client) PatchObject(ctx context.Context, obj *unstructured.Unstructured, patchType types.PatchType, patch []byte, dryRun bool) (*unstructured.Unstructured, error) {
+	dri, err := c.kindInterface(obj.GetKind(), obj.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	options := metav1.PatchOptions{
+		FieldManager:    patchFieldManager,
+		FieldValidation: "Strict",
+	}
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	return dri.Patch(ctx, obj.GetName(), patchType, patch, options)
+}
+
+func (c *Client) kindInterface(kind string, namespace string) (dynamic.ResourceInterface, error) {
+	dd, mapper, err := c.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	gvk, err := mapper.KindFor(schema.GroupVersionResource{Resource: strings.ToLower(kind)})
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	return resourceInterface(dd, mapper, obj, namespace)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	gvkExtension                   = "x-kubernetes-group-version-kind"
+	preserveUnknownFieldsExtension = "x-kubernetes-preserve-unknown-fields"
+)
+
+// ValidateSchema checks obj against the OpenAPI v2 definitions of the cluster.
+// It reports unknown fields, missing required fields and values of the wrong
+// shape; everything else is left to the API server.
+func ValidateSchema(doc *openapi_v2.Document, obj *unstructured.Unstructured) []string {
+	gvk := obj.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return []string{fmt.Sprintf("%s: apiVersion and kind are required", obj.GetName())}
+	}
+
+	v := &schemaValidator{definitions: map[string]*openapi_v2.Schema{}}
+	var root *openapi_v2.Schema
+	for _, definition := range doc.GetDefinitions().GetAdditionalProperties() {
+		v.definitions[definition.GetName()] = definition.GetValue()
+		if root == nil && hasGroupVersionKind(definition.GetValue(), gvk) {
+			root = definition.GetValue()
+		}
+	}
+	if root == nil {
+		return []string{fmt.Sprintf("%s %s: %s is not known to the cluster", gvk.Kind, obj.GetName(), gvk.GroupVersion())}
+	}
+
+	var errs []string
+	for _, err := range v.validate(root, obj.Object, "") {
+		errs = append(errs, fmt.Sprintf("%s %s: %s", gvk.Kind, obj.GetName(), err))
+	}
+	return errs
+}
+
+// hasGroupVersionKind reports whether the definition describes gvk.
+func hasGroupVersionKind(s *openapi_v2.Schema, gvk schema.GroupVersionKind) bool {
+	for _, extension := range s.GetVendorExtension() {
+		if extension.GetName() != gvkExtension {
+			continue
+		}
+		var gvks []struct {
+			Group   string `yaml:"group"`
+			Version string `yaml:"version"`
+			Kind    string `yaml:"kind"`
+		}
+		if err := yaml.Unmarshal([]byte(extension.GetValue().GetYaml()), &gvks); err != nil {
+			return false
+		}
+		for _, candidate := range gvks {
+			if candidate.Group == gvk.Group && candidate.Version == gvk.Version && candidate.Kind == gvk.Kind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type schemaValidator struct {
+	definitions map[string]*openapi_v2.Schema
+}
+
+func (v *schemaValidator) validate(s *openapi_v2.Schema, value interface{}, path string) []string {
+	if s == nil || value == nil {
+		return nil
+	}
+	if ref := s.GetXRef(); ref != "" {
+		return v.validate(v.definitions[strings.TrimPrefix(ref, "#/definitions/")], value, path)
+	}
+	for _, extension := range s.GetVendorExtension() {
+		if extension.GetName() == preserveUnknownFieldsExtension {
+			return nil
+		}
+	}
+
+	switch schemaType(s) {
+	case "object":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %T", fieldPath(path), value)}
+		}
+		var errs []string
+		for _, required := range s.GetRequired() {
+			if _, ok := m[required]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: required field is missing", joinPath(path, required)))
+			}
+		}
+		properties := map[string]*openapi_v2.Schema{}
+		for _, property := range s.GetProperties().GetAdditionalProperties() {
+			properties[property.GetName()] = property.GetValue()
+		}
+		additional := s.GetAdditionalProperties().GetSchema()
+
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := properties[key]; ok {
+				errs = append(errs, v.validate(property, m[key], joinPath(path, key))...)
+			} else if additional != nil {
+				errs = append(errs, v.validate(additional, m[key], joinPath(path, key))...)
+			} else if len(properties) > 0 {
+				errs = append(errs, fmt.Sprintf("%s: unknown field", joinPath(path, key)))
+			}
+		}
+		return errs
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected a list, got %T", fieldPath(path), value)}
+		}
+		var errs []string
+		if schemas := s.GetItems().GetSchema(); len(schemas) == 1 {
+			for i, item := range items {
+				errs = append(errs, v.validate(schemas[0], item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+		return errs
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected a boolean, got %T", fieldPath(path), value)}
+		}
+	case "integer", "number":
+		switch value.(type) {
+		case int64, float64:
+		default:
+			return []string{fmt.Sprintf("%s: expected a number, got %T", fieldPath(path), value)}
+		}
+	case "string":
+		// Quantities and int-or-string fields are written as numbers as well.
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return []string{fmt.Sprintf("%s: expected a string, got %T", fieldPath(path), value)}
+		}
+	}
+	return nil
+}
+
+func schemaType(s *openapi_v2.Schema) string {
+	if types := s.GetType().GetValue(); len(types) > 0 {
+		return types[0]
+	}
+	if s.GetProperties() != nil || s.GetAdditionalProperties() != nil {
+		return "object"
+	}
+	return ""
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func fieldPath(path string) string {
+	if path == "" {
+		return "object"
+	}
+	return path
+}
@@ -3,9 +3,11 @@ package server
 import (
 	"context"
 	json "encoding/json"
+	"fmt"
 
 	schemav1 "buf.build/gen/go/k8sgpt-ai/k8sgpt/protocolbuffers/go/schema/v1"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 )
 
 func (h *handler) Analyze(ctx context.Context, i *schemav1.AnalyzeRequest) (
@@ -56,6 +58,22 @@ func (h *handler) Analyze(ctx context.Context, i *schemav1.AnalyzeRequest) (
 	if err != nil {
 		return &schemav1.AnalyzeResponse{}, err
 	}
+	addFailureCodes(&obj, config.Results)
 
 	return &obj, nil
 }
+
+// addFailureCodes prefixes the failure texts of the response with their code,
+// e.g. "[POD_CRASHLOOP] ...", as the published schema has no field for it.
+func addFailureCodes(response *schemav1.AnalyzeResponse, results []common.Result) {
+	for i, result := range response.Results {
+		if i >= len(results) {
+			break
+		}
+		for j, detail := range result.Error {
+			if j < len(results[i].Error) && results[i].Error[j].Code != "" {
+				detail.Text = fmt.Sprintf("[%s] %s", results[i].Error[j].Code, detail.Text)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"testing"
+
+	schemav1 "buf.build/gen/go/k8sgpt-ai/k8sgpt/protocolbuffers/go/schema/v1"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddFailureCodes(t *testing.T) {
+	response := &schemav1.AnalyzeResponse{Results: []*schemav1.Result{{
+		Kind: "Pod",
+		Name: "default/web",
+		Error: []*schemav1.ErrorDetail{
+			{Text: "back-off restarting failed container"},
+			{Text: "no code"},
+		},
+	}}}
+	addFailureCodes(response, []common.Result{{
+		Kind: "Pod",
+		Name: "default/web",
+		Error: []common.Failure{
+			{Text: "back-off restarting failed container", Code: common.CodePodCrashLoop},
+			{Text: "no code"},
+		},
+	}})
+	require.Equal(t, "[POD_CRASHLOOP] back-off restarting failed container", response.Results[0].Error[0].Text)
+	require.Equal(t, "no code", response.Results[0].Error[1].Text)
+}
@@ -24,6 +24,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	v1 "k8s.io/api/core/v1"
@@ -77,6 +78,26 @@ func GetParent(client *kubernetes.Client, meta metav1.ObjectMeta) (string, bool)
 				}
 				return "DaemonSet/" + ds.Name, true
 
+			case "Job":
+				job, err := client.GetClient().BatchV1().Jobs(meta.Namespace).Get(context.Background(), owner.Name, metav1.GetOptions{})
+				if err != nil {
+					return "", false
+				}
+				if job.OwnerReferences != nil {
+					return GetParent(client, job.ObjectMeta)
+				}
+				return "Job/" + job.Name, true
+
+			case "CronJob":
+				cj, err := client.GetClient().BatchV1().CronJobs(meta.Namespace).Get(context.Background(), owner.Name, metav1.GetOptions{})
+				if err != nil {
+					return "", false
+				}
+				if cj.OwnerReferences != nil {
+					return GetParent(client, cj.ObjectMeta)
+				}
+				return "CronJob/" + cj.Name, true
+
 			case "Ingress":
 				ds, err := client.GetClient().NetworkingV1().Ingresses(meta.Namespace).Get(context.Background(), owner.Name, metav1.GetOptions{})
 				if err != nil {
@@ -261,3 +282,108 @@ func FetchLatestEvent(ctx context.Context, kubernetesClient *kubernetes.Client,
 	}
 	return latestEvent, nil
 }
+
+// Event is an event read from the core/v1 or the events.k8s.io/v1 API.
+type Event struct {
+	Type    string
+	Reason  string
+	Message string
+	Object  v1.ObjectReference
+	// Count is the number of occurrences of the event, from its series or
+	// its deduplication count.
+	Count int32
+	// FirstSeen is the time of its first occurrence.
+	FirstSeen time.Time
+	// LastSeen is the time of its latest occurrence.
+	LastSeen time.Time
+}
+
+// FetchWarningEvents returns the Warning events of a namespace last seen
+// since the given time, read from events.k8s.io/v1 if eventsV1 is set.
+func FetchWarningEvents(ctx context.Context, kubernetesClient *kubernetes.Client, namespace string, since time.Time, eventsV1 bool) ([]Event, error) {
+	var events []Event
+	if eventsV1 {
+		list, err := kubernetesClient.GetClient().EventsV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: "type=" + v1.EventTypeWarning,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range list.Items {
+			e := Event{
+				Type:      event.Type,
+				Reason:    event.Reason,
+				Message:   event.Note,
+				Object:    event.Regarding,
+				Count:     event.DeprecatedCount,
+				FirstSeen: earliestTime(event.DeprecatedFirstTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
+				LastSeen:  latestTime(event.DeprecatedLastTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
+			}
+			if event.Series != nil {
+				e.Count = event.Series.Count
+				e.LastSeen = latestTime(e.LastSeen, event.Series.LastObservedTime.Time)
+			}
+			events = append(events, e)
+		}
+	} else {
+		list, err := kubernetesClient.GetClient().CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: "type=" + v1.EventTypeWarning,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range list.Items {
+			e := Event{
+				Type:      event.Type,
+				Reason:    event.Reason,
+				Message:   event.Message,
+				Object:    event.InvolvedObject,
+				Count:     event.Count,
+				FirstSeen: earliestTime(event.FirstTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
+				LastSeen:  latestTime(event.LastTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
+			}
+			if event.Series != nil {
+				e.Count = event.Series.Count
+				e.LastSeen = latestTime(e.LastSeen, event.Series.LastObservedTime.Time)
+			}
+			events = append(events, e)
+		}
+	}
+
+	// the field selector is not supported everywhere, e.g. by fake clients
+	var warnings []Event
+	for _, event := range events {
+		if event.Type != v1.EventTypeWarning || event.LastSeen.Before(since) {
+			continue
+		}
+		if event.Count < 1 {
+			event.Count = 1
+		}
+		if event.FirstSeen.IsZero() {
+			event.FirstSeen = event.LastSeen
+		}
+		warnings = append(warnings, event)
+	}
+	return warnings, nil
+}
+
+func latestTime(times ...time.Time) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// earliestTime returns the earliest of the times that are set.
+func earliestTime(times ...time.Time) time.Time {
+	var earliest time.Time
+	for _, t := range times {
+		if !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+	return earliest
+}
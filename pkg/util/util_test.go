@@ -14,13 +14,16 @@ limitations under the License.
 package util
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/stretchr/testify/require"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
@@ -503,3 +506,73 @@ func TestLabelsIncludeAny(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchWarningEvents(t *testing.T) {
+	now := time.Now()
+	pod := v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web"}
+	clientset := fake.NewSimpleClientset(
+		&v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: "default"},
+			InvolvedObject: pod,
+			Type:           v1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Count:          7,
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+		},
+		&v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.2", Namespace: "default"},
+			InvolvedObject: pod,
+			Type:           v1.EventTypeWarning,
+			Reason:         "FailedMount",
+			EventTime:      metav1.NewMicroTime(now.Add(-2 * time.Hour)),
+			Series:         &v1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(now.Add(-time.Minute))},
+		},
+		&v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.3", Namespace: "default"},
+			InvolvedObject: pod,
+			Type:           v1.EventTypeNormal,
+			Reason:         "Pulled",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+		&v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.4", Namespace: "default"},
+			InvolvedObject: pod,
+			Type:           v1.EventTypeWarning,
+			Reason:         "Unhealthy",
+			LastTimestamp:  metav1.NewTime(now.Add(-2 * time.Hour)),
+		},
+		&eventsv1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "web.5", Namespace: "default"},
+			Regarding:  pod,
+			Type:       v1.EventTypeWarning,
+			Reason:     "FailedScheduling",
+			Note:       "0/3 nodes are available",
+			EventTime:  metav1.NewMicroTime(now.Add(-time.Minute)),
+		},
+	)
+	client := &kubernetes.Client{Client: clientset}
+	since := now.Add(-time.Hour)
+
+	events, err := FetchWarningEvents(context.Background(), client, "default", since, false)
+	require.NoError(t, err)
+	reasons := map[string]int32{}
+	for _, event := range events {
+		require.Equal(t, pod, event.Object)
+		reasons[event.Reason] = event.Count
+		if event.Reason == "FailedMount" {
+			// the series started before the window
+			require.WithinDuration(t, now.Add(-2*time.Hour), event.FirstSeen, time.Second)
+		}
+	}
+	// the events.k8s.io/v1 event is stored apart from the core/v1 events by
+	// the fake clientset
+	require.Equal(t, map[string]int32{"BackOff": 7, "FailedMount": 3}, reasons)
+
+	events, err = FetchWarningEvents(context.Background(), client, "default", since, true)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "FailedScheduling", events[0].Reason)
+	require.Equal(t, "0/3 nodes are available", events[0].Message)
+	require.Equal(t, int32(1), events[0].Count)
+}